@@ -25,6 +25,12 @@ type AddPeopleApplication struct {
 	*Application
 	PeopleCSVPath string
 	Access        string
+	// Skip holds normalized email addresses that are not added
+	Skip map[string]bool
+	// Limit bounds the rooms read when no rooms are given, 0 reads all
+	Limit int
+	// Journal records the members added so an interrupted run can resume
+	Journal *jobJournal
 }
 
 // AddPeopleCMD function
@@ -62,47 +68,28 @@ func (app *Application) AddPeopleCMD() *cli.Command {
 				Usage:    "Path to a CSV containing a list of RoomID's to which members will be added to.",
 				Required: false,
 			},
+			&cli.StringFlag{
+				Name:     "team",
+				Aliases:  []string{"tm"},
+				Value:    "",
+				Usage:    "ID or name of a team whose rooms members will be added to",
+				Required: false,
+			},
+			selectFlag(),
+			&cli.BoolFlag{
+				Name:     "validate",
+				Aliases:  []string{"v"},
+				Value:    false,
+				Usage:    "Look up every email address first and skip those that are not found or deactivated",
+				Required: false,
+			},
+			limitFlag(),
+			resumeFlag(),
 		},
 		Action: func(c *cli.Context) error {
-			roomID := c.String("roomID")
-			var roomIDs []string
-
-			roomsCSV := c.String("roomsidscsv")
-			if roomsCSV != "" {
-				roomsCSVPath := roomsCSV
-				if !strings.HasSuffix(roomsCSVPath, ".csv") {
-					return errors.New("Only CSV files are supported")
-				}
-				if strings.HasPrefix(roomsCSVPath, "~") {
-					home, err := os.UserHomeDir()
-					if err != nil {
-						return err
-					}
-					tmpFilename := roomsCSVPath[len("~"):]
-					roomsCSVPath = path.Join(home, tmpFilename)
-				}
-
-				absFilePath, err := filepath.Abs(roomsCSVPath)
-				if err != nil {
-					return err
-				}
-
-				csvfile, err := os.Open(absFilePath)
-				if err != nil {
-					return err
-				}
-
-				defer csvfile.Close()
-				c := ParseRoomIDsCSV(csvfile)
-				for v := range c {
-					parsedRoomID, err := app.parseRoomID(v.Value.RoomID)
-					if err != nil {
-						return err
-					}
-					roomIDs = append(roomIDs, parsedRoomID)
-				}
-			} else if roomID != "" {
-				roomIDs = append(roomIDs, roomID)
+			roomIDs, err := app.targetRoomIDs(c)
+			if err != nil {
+				return err
 			}
 
 			access := c.String("access")
@@ -113,13 +100,13 @@ func (app *Application) AddPeopleCMD() *cli.Command {
 			confirm := c.String("confirm")
 			if len(roomIDs) <= 0 {
 				if confirm != "" {
-					if !strings.HasSuffix("y", strings.TrimSpace(strings.ToLower(confirm))) {
+					if !confirmAnswer(confirm) {
 						return nil
 					}
 					reader := bufio.NewReader(os.Stdin)
 					fmt.Print("Continue to add members to all rooms that you have moderator access to? (y/n) : ")
 					text, _ := reader.ReadString('\n')
-					if !strings.HasSuffix("y", strings.TrimSpace(strings.ToLower(text))) {
+					if !confirmAnswer(text) {
 						return nil
 					}
 				}
@@ -127,8 +114,21 @@ func (app *Application) AddPeopleCMD() *cli.Command {
 
 			csvPath := c.String("memberscsv")
 			if csvPath != "" {
-				roomUtilsApp := &AddPeopleApplication{Application: app, PeopleCSVPath: csvPath, Access: access}
-				err := roomUtilsApp.AddPeopleToRoom(roomIDs)
+				roomUtilsApp := &AddPeopleApplication{Application: app, PeopleCSVPath: csvPath, Access: access, Limit: c.Int("limit")}
+				if c.Bool("validate") {
+					if err := roomUtilsApp.validateMembers(); err != nil {
+						return err
+					}
+				}
+				journal, err := app.openJobJournal(c, "addmembers", c.String("resume"))
+				if err != nil {
+					return err
+				}
+				roomUtilsApp.Journal = journal
+				err = roomUtilsApp.AddPeopleToRoom(roomIDs)
+				if finishErr := journal.finish(err); err == nil {
+					err = finishErr
+				}
 				if err != nil {
 					return err
 				}
@@ -158,6 +158,29 @@ func (app *AddPeopleApplication) checkAccess(me *people.Person, room *rooms.Room
 	return false
 }
 
+// validateMembers resolves the members CSV and marks the addresses that
+// cannot be added to be skipped
+func (app *AddPeopleApplication) validateMembers() error {
+	members, err := readMembersCSV(app.PeopleCSVPath)
+	if err != nil {
+		return err
+	}
+	emails := make([]string, 0, len(members))
+	for _, member := range members {
+		emails = append(emails, string(member.Email))
+	}
+	app.Skip = make(map[string]bool)
+	for key, resolution := range app.resolvePeople(emails) {
+		if resolution.unavailable() {
+			app.Skip[key] = true
+			log.Warnf("Skipping %s: %s", key, resolution.Status)
+		} else if resolution.Status == personLookupError {
+			log.Warnf("Could not look up %s, adding it anyway: %s", key, resolution.Detail)
+		}
+	}
+	return nil
+}
+
 // AddPeopleToRoom function
 func (app *AddPeopleApplication) AddPeopleToRoom(roomIDs []string) error {
 
@@ -170,6 +193,11 @@ func (app *AddPeopleApplication) AddPeopleToRoom(roomIDs []string) error {
 		}
 	}()
 
+	if len(roomIDs) == 0 {
+		// An empty room ID stands for every room the user is a member of
+		roomIDs = []string{""}
+	}
+
 	var rwg sync.WaitGroup
 	rwp := workerpool.New(2)
 	for _, roomID := range roomIDs {
@@ -178,37 +206,36 @@ func (app *AddPeopleApplication) AddPeopleToRoom(roomIDs []string) error {
 			rwp.Submit(func() {
 				defer rwg.Done()
 				if roomID == "" {
-					membershipQueryParams := &memberships.ListOptions{}
-					mbrPage, err := app.Client.Memberships().List(membershipQueryParams)
+					items, err := app.ListMemberships("", app.Limit)
 					if err != nil {
 						errChan <- err
 						return
 					}
-					if len(mbrPage.Items) > 0 {
-						var wg sync.WaitGroup
-						for _, membership := range mbrPage.Items {
-							wg.Add(1)
-							go func(membership memberships.Membership) {
-								defer wg.Done()
-								room, err := app.Client.Rooms().Get(membership.RoomID)
-								if err != nil {
-									errChan <- err
-									return
-								}
-
-								if room.Title != "" && app.checkAccess(app.Me, room, membership) {
-									err := app.processAddPeople(room)
-									if err == nil {
-										log.Println("Added members to: ", room.Title)
-									} else {
+					if len(items) > 0 {
+						mwp := workerpool.New(2)
+						for _, membership := range items {
+							func(membership memberships.Membership) {
+								mwp.Submit(func() {
+									room, err := app.Client.Rooms().Get(membership.RoomID)
+									if err != nil {
 										errChan <- err
 										return
 									}
-								}
-								errChan <- nil
+
+									if room.Title != "" && app.checkAccess(app.Me, room, membership) {
+										err := app.processAddPeople(room)
+										if err == nil {
+											log.Println("Added members to: ", room.Title)
+										} else {
+											errChan <- err
+											return
+										}
+									}
+									errChan <- nil
+								})
 							}(membership)
 						}
-						wg.Wait()
+						mwp.StopWait()
 					}
 				} else {
 					room, err := app.Client.Rooms().Get(roomID)
@@ -295,11 +322,19 @@ func (app *AddPeopleApplication) processAddPeople(room *rooms.Room) error {
 		c := ParseUsersCSV(csvfile)
 		for v := range c {
 			if v.Err == nil {
+				if app.Skip[normalizeEmail(string(v.Value.Email))] {
+					continue
+				}
+				if app.Journal.completed(room.ID, string(v.Value.Email), jobActionAdd) {
+					continue
+				}
 				// Sleep to avoid rate limiting
 				time.Sleep(2 * time.Second)
 				err := app.createMember(room, v.Value.Email, v.Value.IsModerator)
 				if err != nil {
 					log.Println(err)
+				} else {
+					app.Journal.record(room.ID, string(v.Value.Email), jobActionAdd)
 				}
 			} else {
 				return v.Err
@@ -1,11 +1,13 @@
 package cmd
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io"
 	"net"
 	"net/http"
+	"net/url"
 	"os"
 	"path"
 	"path/filepath"
@@ -18,6 +20,7 @@ import (
 	"github.com/WebexCommunity/webex-go-sdk/v2/messages"
 	"github.com/WebexCommunity/webex-go-sdk/v2/people"
 	"github.com/WebexCommunity/webex-go-sdk/v2/rooms"
+	"github.com/WebexCommunity/webex-go-sdk/v2/webexsdk"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -26,6 +29,7 @@ type Application struct {
 	AccessToken    string
 	Email          string
 	DownloadsDir   string
+	StateDir       string
 	Me             *people.Person
 	Client         *webex.WebexClient
 	ContentsClient *contents.Client
@@ -213,55 +217,46 @@ func (app *Application) resolveLocalFile(params *SendMessageParams) (*messages.F
 
 // GetMessagesForRoomFromRoomID method
 func (app *Application) GetMessagesForRoomFromRoomID(roomID string, max int) ([]messages.Message, error) {
-	empty := make([]messages.Message, 0)
 	const noMessage = "No messages found"
 	if max == 0 {
 		max = 10
 	}
 
-	opts := &messages.ListOptions{
-		RoomID: roomID,
-		Max:    max,
-	}
-
-	page, err := app.Client.Messages().List(opts)
+	found := make([]messages.Message, 0, max)
+	err := app.eachMessage(roomID, false, max, func(message messages.Message) error {
+		found = append(found, message)
+		return nil
+	})
 	if err != nil {
-		return empty, err
+		return make([]messages.Message, 0), err
 	}
 
-	if len(page.Items) > 0 {
-		return page.Items, nil
+	if len(found) > 0 {
+		return found, nil
 	}
-	return empty, errors.New(noMessage)
+	return found, errors.New(noMessage)
 }
 
 // GetMessagesForRoom method
 func (app *Application) GetMessagesForRoom(room *rooms.Room, max int) ([]messages.Message, error) {
-	roomID := room.ID
-	empty := make([]messages.Message, 0)
 	const noMessage = "No messages found"
 	if max == 0 {
 		max = 10
 	}
 
-	opts := &messages.ListOptions{
-		RoomID: roomID,
-		Max:    max,
-	}
-
-	if room.Type == "group" {
-		opts.MentionedPeople = "me"
-	}
-
-	page, err := app.Client.Messages().List(opts)
+	found := make([]messages.Message, 0, max)
+	err := app.eachMessage(room.ID, room.Type == "group", max, func(message messages.Message) error {
+		found = append(found, message)
+		return nil
+	})
 	if err != nil {
-		return empty, err
+		return make([]messages.Message, 0), err
 	}
 
-	if len(page.Items) > 0 {
-		return page.Items, nil
+	if len(found) > 0 {
+		return found, nil
 	}
-	return empty, errors.New(noMessage)
+	return found, errors.New(noMessage)
 }
 
 // GetEmail returns the authenticated user's email
@@ -284,17 +279,48 @@ func (app *Application) GetContentsClient() *contents.Client {
 	return app.ContentsClient
 }
 
-// GetRooms retrieves rooms sorted by last activity
-func (app *Application) GetRooms(max int, roomType string) ([]rooms.Room, error) {
-	opts := &rooms.ListOptions{
-		Max:    max,
-		Type:   roomType,
-		SortBy: "lastactivity",
+// apiRequest performs a raw request against the Webex API, for fields and
+// endpoints the SDK types do not cover. The response is decoded into result
+// unless it is nil.
+func (app *Application) apiRequest(method, apiPath string, params url.Values, body interface{}, result interface{}) error {
+	resp, err := app.Client.Core().Request(method, apiPath, params, body)
+	return parseAPIResponse(resp, err, result)
+}
+
+// apiGet is apiRequest for GET requests, retried on 429 after the
+// Retry-After delay and on transient server errors
+func (app *Application) apiGet(apiPath string, params url.Values, result interface{}) error {
+	resp, err := app.Client.Core().RequestWithRetry(context.Background(), http.MethodGet, apiPath, params, nil)
+	return parseAPIResponse(resp, err, result)
+}
+
+func parseAPIResponse(resp *http.Response, err error, result interface{}) error {
+	if err != nil {
+		return err
+	}
+	if result != nil {
+		return webexsdk.ParseResponse(resp, result)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("API error: %d - %s", resp.StatusCode, string(respBody))
 	}
+	return nil
+}
 
-	page, err := app.Client.Rooms().List(opts)
+// setModerator changes whether a room member is a moderator. The SDK drops
+// isModerator=false from updates, so the request is sent directly.
+func (app *Application) setModerator(membershipID string, isModerator bool) error {
+	return app.apiRequest(http.MethodPut, "memberships/"+membershipID, nil, map[string]interface{}{"isModerator": isModerator}, nil)
+}
+
+// GetRooms retrieves up to max rooms sorted by last activity, reading as many
+// pages as needed. A max of 0 retrieves every room.
+func (app *Application) GetRooms(max int, roomType string) ([]rooms.Room, error) {
+	found, err := app.ListRooms(roomType, "", max)
 	if err != nil {
 		return make([]rooms.Room, 0), err
 	}
-	return page.Items, nil
+	return found, nil
 }
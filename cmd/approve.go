@@ -0,0 +1,341 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/WebexCommunity/webex-go-sdk/v2/conversation"
+	"github.com/WebexCommunity/webex-go-sdk/v2/messages"
+	"github.com/WebexCommunity/webex-go-sdk/v2/people"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/go-ozzo/ozzo-validation/v4/is"
+	log "github.com/sirupsen/logrus"
+	"github.com/tejzpr/webex-teams-cli/cmd/webexid"
+	"github.com/urfave/cli/v2"
+)
+
+// Exit codes of the approve command, so pipelines can branch on the outcome.
+// Approval exits with 0.
+const (
+	approvalExitRejected = 1
+	approvalExitTimeout  = 2
+	approvalExitError    = 3
+)
+
+const (
+	approvalDecisionApprove = "approve"
+	approvalDecisionReject  = "reject"
+)
+
+// ApprovalApplication struct
+type ApprovalApplication struct {
+	*Application
+	RoomID    string
+	Title     string
+	Text      string
+	Approvers map[string]bool
+	Timeout   time.Duration
+
+	personEmails sync.Map
+	// people is taken before handlers start, the SDK creates its clients
+	// lazily and without a lock
+	people *people.Client
+}
+
+// approvalDecision is a valid click on the approval card
+type approvalDecision struct {
+	Decision    string
+	PersonEmail string
+}
+
+// ApproveCMD function
+func (app *Application) ApproveCMD() *cli.Command {
+	return &cli.Command{
+		Name:        "approve",
+		Aliases:     []string{"ap"},
+		Usage:       "Wait for a human approval via an Adaptive Card",
+		Description: "Post an Approve / Reject card to a room and wait for one of the approvers to click it. Exits 0 on approve, 1 on reject, 2 on timeout and 3 when the approval could not be requested.",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:     "roomID",
+				Aliases:  []string{"room", "rid"},
+				Value:    "",
+				Usage:    "Webex room ID to post the approval card to",
+				Required: true,
+				EnvVars:  []string{"WEBEX_ROOM_ID"},
+			},
+			&cli.StringFlag{
+				Name:     "title",
+				Aliases:  []string{"t"},
+				Value:    "",
+				Usage:    "Title of the approval request eg. \"Deploy v1.2?\"",
+				Required: true,
+			},
+			&cli.StringFlag{
+				Name:     "text",
+				Aliases:  []string{"txt"},
+				Value:    "",
+				Usage:    "Optional details shown below the title",
+				Required: false,
+			},
+			&cli.StringFlag{
+				Name:     "approvers",
+				Aliases:  []string{"ap"},
+				Value:    "",
+				Usage:    "Comma separated list of email addresses allowed to approve or reject",
+				Required: true,
+			},
+			&cli.DurationFlag{
+				Name:     "timeout",
+				Aliases:  []string{"to"},
+				Value:    30 * time.Minute,
+				Usage:    "How long to wait for a decision. Default is 30m",
+				Required: false,
+			},
+		},
+		Action: func(c *cli.Context) error {
+			roomID, err := app.parseRoomID(c.String("roomID"))
+			if err != nil {
+				return cli.Exit(err.Error(), approvalExitError)
+			}
+			approvers, err := parseApprovers(c.String("approvers"))
+			if err != nil {
+				return cli.Exit(err.Error(), approvalExitError)
+			}
+			timeout := c.Duration("timeout")
+			if timeout <= 0 {
+				return cli.Exit("The timeout should be greater than 0", approvalExitError)
+			}
+
+			approvalApp := &ApprovalApplication{
+				Application: app,
+				RoomID:      roomID,
+				Title:       c.String("title"),
+				Text:        c.String("text"),
+				Approvers:   approvers,
+				Timeout:     timeout,
+			}
+			// Errors get their own exit code, so they are not taken for a rejection
+			decision, err := approvalApp.RequestApproval()
+			if err != nil {
+				return cli.Exit(err.Error(), approvalExitError)
+			}
+
+			switch decision {
+			case approvalDecisionApprove:
+				log.Info("Approved")
+				return nil
+			case approvalDecisionReject:
+				return cli.Exit("Rejected", approvalExitRejected)
+			default:
+				return cli.Exit("Timed out waiting for approval", approvalExitTimeout)
+			}
+		},
+	}
+}
+
+// parseApprovers parses a comma separated list of emails into a lookup set
+func parseApprovers(str string) (map[string]bool, error) {
+	approvers := make(map[string]bool)
+	for _, approver := range strings.Split(str, ",") {
+		approver = strings.ToLower(strings.TrimSpace(approver))
+		if approver == "" {
+			continue
+		}
+		if err := validation.Validate(approver, validation.Required, is.Email); err != nil {
+			return nil, fmt.Errorf("%s is not a valid email", approver)
+		}
+		approvers[approver] = true
+	}
+	if len(approvers) == 0 {
+		return nil, errors.New("At least one approver is required")
+	}
+	return approvers, nil
+}
+
+// decisionFromInputs extracts the decision submitted by an approval card button
+func decisionFromInputs(inputs map[string]interface{}) string {
+	decision, _ := inputs["decision"].(string)
+	switch decision {
+	case approvalDecisionApprove, approvalDecisionReject:
+		return decision
+	}
+	return ""
+}
+
+// approvalCard builds the Adaptive Card body with Approve / Reject buttons
+func (app *ApprovalApplication) approvalCard() map[string]interface{} {
+	body := []interface{}{
+		map[string]interface{}{
+			"type":   "TextBlock",
+			"text":   app.Title,
+			"size":   "Medium",
+			"weight": "Bolder",
+			"wrap":   true,
+		},
+	}
+	if app.Text != "" {
+		body = append(body, map[string]interface{}{
+			"type": "TextBlock",
+			"text": app.Text,
+			"wrap": true,
+		})
+	}
+	body = append(body, map[string]interface{}{
+		"type":     "TextBlock",
+		"text":     fmt.Sprintf("Approvers: %s", strings.Join(app.approverList(), ", ")),
+		"size":     "Small",
+		"isSubtle": true,
+		"wrap":     true,
+	})
+
+	return map[string]interface{}{
+		"$schema": "http://adaptivecards.io/schemas/adaptive-card.json",
+		"type":    "AdaptiveCard",
+		"version": "1.3",
+		"body":    body,
+		"actions": []interface{}{
+			map[string]interface{}{
+				"type":  "Action.Submit",
+				"title": "Approve",
+				"style": "positive",
+				"data":  map[string]interface{}{"decision": approvalDecisionApprove},
+			},
+			map[string]interface{}{
+				"type":  "Action.Submit",
+				"title": "Reject",
+				"style": "destructive",
+				"data":  map[string]interface{}{"decision": approvalDecisionReject},
+			},
+		},
+	}
+}
+
+func (app *ApprovalApplication) approverList() []string {
+	approvers := make([]string, 0, len(app.Approvers))
+	for approver := range app.Approvers {
+		approvers = append(approvers, approver)
+	}
+	sort.Strings(approvers)
+	return approvers
+}
+
+// personEmail resolves and caches the primary email of a person
+func (app *ApprovalApplication) personEmail(personID string) (string, error) {
+	if cached, ok := app.personEmails.Load(personID); ok {
+		return cached.(string), nil
+	}
+	person, err := app.people.Get(personID)
+	if err != nil {
+		return "", err
+	}
+	if len(person.Emails) == 0 {
+		return "", fmt.Errorf("Could not resolve email of %s", personID)
+	}
+	personEmail := strings.ToLower(person.Emails[0])
+	app.personEmails.Store(personID, personEmail)
+	return personEmail, nil
+}
+
+// RequestApproval posts the approval card and blocks until an approver
+// decides or the timeout elapses. An empty decision means timeout. Card
+// actions are listened to before the card is posted, so no click is missed.
+func (app *ApprovalApplication) RequestApproval() (string, error) {
+	room, err := app.Client.Rooms().Get(app.RoomID)
+	if err != nil {
+		return "", err
+	}
+
+	conv, err := app.Client.Conversation()
+	if err != nil {
+		return "", err
+	}
+
+	var sentCard *messages.Message
+	posted := make(chan struct{})
+	done := make(chan struct{})
+	defer close(done)
+
+	app.people = app.Client.People()
+	attachmentActions := app.Client.AttachmentActions()
+	decisions := make(chan approvalDecision, 1)
+	conv.On("cardAction", func(activity *conversation.Activity) {
+		// A click can arrive before the card's ID is known
+		select {
+		case <-posted:
+		case <-done:
+			return
+		}
+		actionID, err := webexid.Encode(webexid.AttachmentAction, activity.ID)
+		if err != nil {
+			log.Debugf("Invalid attachment action %s: %s", activity.ID, err.Error())
+			return
+		}
+		action, err := attachmentActions.Get(actionID)
+		if err != nil {
+			log.Debugf("Error fetching attachment action %s: %s", activity.ID, err.Error())
+			return
+		}
+		if action.MessageID != sentCard.ID {
+			return
+		}
+		decision := decisionFromInputs(action.Inputs)
+		if decision == "" {
+			return
+		}
+		personEmail, err := app.personEmail(action.PersonID)
+		if err != nil {
+			log.Error(err.Error())
+			return
+		}
+		if !app.Approvers[personEmail] {
+			log.Infof("Ignoring %s from %s who is not an approver", decision, personEmail)
+			return
+		}
+		select {
+		case decisions <- approvalDecision{Decision: decision, PersonEmail: personEmail}:
+		default:
+		}
+	})
+	if err := conv.Connect(); err != nil {
+		return "", err
+	}
+	defer conv.Disconnect()
+
+	cardMessage := &messages.Message{RoomID: room.ID}
+	fallback := fmt.Sprintf("Approval requested: %s", app.Title)
+	sentCard, err = app.Client.Messages().CreateWithAdaptiveCard(cardMessage, messages.NewAdaptiveCard(app.approvalCard()), fallback)
+	if err != nil {
+		return "", err
+	}
+	close(posted)
+	log.Infof("Posted approval card: %s", sentCard.ID)
+
+	var reply string
+	var result string
+	select {
+	case decision := <-decisions:
+		result = decision.Decision
+		if decision.Decision == approvalDecisionApprove {
+			reply = fmt.Sprintf("**Approved** by %s", decision.PersonEmail)
+		} else {
+			reply = fmt.Sprintf("**Rejected** by %s", decision.PersonEmail)
+		}
+	case <-time.After(app.Timeout):
+		reply = fmt.Sprintf("**Timed out** after %s without a decision", app.Timeout)
+	}
+
+	_, err = app.Client.Messages().Create(&messages.Message{
+		RoomID:   room.ID,
+		ParentID: sentCard.ID,
+		Markdown: reply,
+	})
+	if err != nil {
+		log.Errorf("Failed to post decision to thread: %s", err.Error())
+	}
+	return result, nil
+}
@@ -0,0 +1,136 @@
+package cmd
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	webex "github.com/WebexCommunity/webex-go-sdk/v2"
+	"github.com/WebexCommunity/webex-go-sdk/v2/webexsdk"
+	"github.com/tejzpr/webex-teams-cli/cmd/webexid"
+	"github.com/urfave/cli/v2"
+)
+
+func TestParseApprovers(t *testing.T) {
+	approvers, err := parseApprovers(" A@example.com, b@example.com ,,")
+	if err != nil {
+		t.Fatalf("parseApprovers() error = %v", err)
+	}
+	if len(approvers) != 2 || !approvers["a@example.com"] || !approvers["b@example.com"] {
+		t.Errorf("Unexpected approvers %v", approvers)
+	}
+
+	if _, err := parseApprovers("not-an-email"); err == nil {
+		t.Error("Expected error for invalid email")
+	}
+
+	if _, err := parseApprovers(" , "); err == nil {
+		t.Error("Expected error when no approvers are given")
+	}
+}
+
+func TestDecisionFromInputs(t *testing.T) {
+	tests := []struct {
+		name     string
+		inputs   map[string]interface{}
+		expected string
+	}{
+		{"approve", map[string]interface{}{"decision": "approve"}, approvalDecisionApprove},
+		{"reject", map[string]interface{}{"decision": "reject"}, approvalDecisionReject},
+		{"unknown decision", map[string]interface{}{"decision": "maybe"}, ""},
+		{"wrong type", map[string]interface{}{"decision": true}, ""},
+		{"missing", map[string]interface{}{}, ""},
+		{"nil inputs", nil, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := decisionFromInputs(tt.inputs); got != tt.expected {
+				t.Errorf("decisionFromInputs() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestApprovalCard(t *testing.T) {
+	app := &ApprovalApplication{
+		Title:     "Deploy v1.2?",
+		Text:      "Release notes",
+		Approvers: map[string]bool{"b@example.com": true, "a@example.com": true},
+		Timeout:   time.Minute,
+	}
+
+	card := app.approvalCard()
+	if card["type"] != "AdaptiveCard" {
+		t.Errorf("Expected AdaptiveCard, got %v", card["type"])
+	}
+
+	body := card["body"].([]interface{})
+	if len(body) != 3 {
+		t.Fatalf("Expected 3 body elements, got %d", len(body))
+	}
+	if body[0].(map[string]interface{})["text"] != "Deploy v1.2?" {
+		t.Errorf("Expected title as first text block, got %v", body[0])
+	}
+	if body[2].(map[string]interface{})["text"] != "Approvers: a@example.com, b@example.com" {
+		t.Errorf("Expected sorted approvers, got %v", body[2])
+	}
+
+	actions := card["actions"].([]interface{})
+	if len(actions) != 2 {
+		t.Fatalf("Expected 2 actions, got %d", len(actions))
+	}
+	for i, expected := range []string{approvalDecisionApprove, approvalDecisionReject} {
+		data := actions[i].(map[string]interface{})["data"].(map[string]interface{})
+		if decisionFromInputs(data) != expected {
+			t.Errorf("Expected action %d to submit %q, got %v", i, expected, data)
+		}
+	}
+}
+
+func TestApproveCMDStructure(t *testing.T) {
+	app := &Application{}
+	cmd := app.ApproveCMD()
+
+	if cmd.Name != "approve" {
+		t.Errorf("Expected command name 'approve', got %q", cmd.Name)
+	}
+
+	for _, flagName := range []string{"roomID", "title", "approvers"} {
+		flag := getFlagByName(cmd.Flags, flagName)
+		if flag == nil {
+			t.Errorf("Expected flag %q not found", flagName)
+		} else if !flag.(interface{ IsRequired() bool }).IsRequired() {
+			t.Errorf("Expected flag %q to be required", flagName)
+		}
+	}
+
+	if getFlagByName(cmd.Flags, "timeout") == nil {
+		t.Error("Expected flag 'timeout' not found")
+	}
+}
+
+func TestApproveCMDExitCodeOnError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+	client, err := webex.NewClient("test-token", &webexsdk.Config{BaseURL: server.URL, MaxRetries: 0})
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	app := &Application{Client: client}
+	roomID, _ := webexid.Encode(webexid.Room, "0b5c6d2e-0000-4000-8000-00000000000a")
+
+	cliApp := &cli.App{
+		Commands:       []*cli.Command{app.ApproveCMD()},
+		ExitErrHandler: func(c *cli.Context, err error) {},
+	}
+	err = cliApp.Run([]string{"webex-teams-cli", "approve", "--room", roomID, "--title", "Deploy?", "--approvers", "a@example.com"})
+	var exitErr cli.ExitCoder
+	if !errors.As(err, &exitErr) || exitErr.ExitCode() != approvalExitError {
+		t.Errorf("Expected exit code %d when Webex fails, got %v", approvalExitError, err)
+	}
+}
@@ -5,9 +5,6 @@ import (
 	"errors"
 	"fmt"
 	"os"
-	"path"
-	"path/filepath"
-	"strings"
 	"sync"
 	"time"
 
@@ -28,6 +25,10 @@ type BroadcastToRoomsApplication struct {
 	UserEmail     string
 	BroadcastText string
 	BroadcastFile string
+	// Limit bounds the rooms read when no rooms are given, 0 reads all
+	Limit int
+	// Journal records the rooms messaged so an interrupted run can resume
+	Journal *jobJournal
 }
 
 // BroadcastToRoomsCMD function
@@ -72,47 +73,21 @@ func (app *Application) BroadcastToRoomsCMD() *cli.Command {
 				Usage:    "Path to a CSV containing a list of RoomID's to which message will be broadcasted to.",
 				Required: false,
 			},
+			&cli.StringFlag{
+				Name:     "team",
+				Aliases:  []string{"tm"},
+				Value:    "",
+				Usage:    "ID or name of a team whose rooms message will be broadcasted to",
+				Required: false,
+			},
+			selectFlag(),
+			limitFlag(),
+			resumeFlag(),
 		},
 		Action: func(c *cli.Context) error {
-			roomID := c.String("roomID")
-			var roomIDs []string
-
-			roomsCSV := c.String("roomsidscsv")
-			if roomsCSV != "" {
-				roomsCSVPath := roomsCSV
-				if !strings.HasSuffix(roomsCSVPath, ".csv") {
-					return errors.New("Only CSV files are supported")
-				}
-				if strings.HasPrefix(roomsCSVPath, "~") {
-					home, err := os.UserHomeDir()
-					if err != nil {
-						return err
-					}
-					tmpFilename := roomsCSVPath[len("~"):]
-					roomsCSVPath = path.Join(home, tmpFilename)
-				}
-
-				absFilePath, err := filepath.Abs(roomsCSVPath)
-				if err != nil {
-					return err
-				}
-
-				csvfile, err := os.Open(absFilePath)
-				if err != nil {
-					return err
-				}
-
-				defer csvfile.Close()
-				c := ParseRoomIDsCSV(csvfile)
-				for v := range c {
-					parsedRoomID, err := app.parseRoomID(v.Value.RoomID)
-					if err != nil {
-						return err
-					}
-					roomIDs = append(roomIDs, parsedRoomID)
-				}
-			} else if roomID != "" {
-				roomIDs = append(roomIDs, roomID)
+			roomIDs, err := app.targetRoomIDs(c)
+			if err != nil {
+				return err
 			}
 
 			access := c.String("access")
@@ -123,14 +98,14 @@ func (app *Application) BroadcastToRoomsCMD() *cli.Command {
 			confirm := c.String("confirm")
 			if len(roomIDs) <= 0 {
 				if confirm != "" {
-					if !strings.HasSuffix("y", strings.TrimSpace(strings.ToLower(confirm))) {
+					if !confirmAnswer(confirm) {
 						return nil
 					}
 				} else {
 					reader := bufio.NewReader(os.Stdin)
 					fmt.Print("Continue to broadcast to all rooms that you have moderator access to? (y/n) : ")
 					text, _ := reader.ReadString('\n')
-					if !strings.HasSuffix("y", strings.TrimSpace(strings.ToLower(text))) {
+					if !confirmAnswer(text) {
 						return nil
 					}
 				}
@@ -139,8 +114,15 @@ func (app *Application) BroadcastToRoomsCMD() *cli.Command {
 			broadcasttext := c.String("text")
 			broadcastfile := c.String("file")
 			if broadcasttext != "" || broadcastfile != "" {
-				roomUtilsApp := &BroadcastToRoomsApplication{Application: app, BroadcastFile: broadcastfile, BroadcastText: broadcasttext, Access: access}
-				err := roomUtilsApp.BroadcastToRoom(roomIDs)
+				journal, err := app.openJobJournal(c, "broadcast", c.String("resume"))
+				if err != nil {
+					return err
+				}
+				roomUtilsApp := &BroadcastToRoomsApplication{Application: app, BroadcastFile: broadcastfile, BroadcastText: broadcasttext, Access: access, Limit: c.Int("limit"), Journal: journal}
+				err = roomUtilsApp.BroadcastToRoom(roomIDs)
+				if finishErr := journal.finish(err); err == nil {
+					err = finishErr
+				}
 				if err != nil {
 					return err
 				}
@@ -191,6 +173,11 @@ func (app *BroadcastToRoomsApplication) BroadcastToRoom(roomIDs []string) error
 		}
 	}()
 
+	if len(roomIDs) == 0 {
+		// An empty room ID stands for every room the user is a member of
+		roomIDs = []string{""}
+	}
+
 	var rwg sync.WaitGroup
 	rwp := workerpool.New(10)
 	for _, roomID := range roomIDs {
@@ -199,37 +186,36 @@ func (app *BroadcastToRoomsApplication) BroadcastToRoom(roomIDs []string) error
 			rwp.Submit(func() {
 				defer rwg.Done()
 				if roomID == "" {
-					membershipQueryParams := &memberships.ListOptions{}
-					mbrPage, err := app.Client.Memberships().List(membershipQueryParams)
+					items, err := app.ListMemberships("", app.Limit)
 					if err != nil {
 						errChan <- err
 						return
 					}
-					if len(mbrPage.Items) > 0 {
-						var wg sync.WaitGroup
-						for _, membership := range mbrPage.Items {
-							wg.Add(1)
-							go func(membership memberships.Membership) {
-								defer wg.Done()
-								room, err := app.Client.Rooms().Get(membership.RoomID)
-								if err != nil {
-									errChan <- err
-									return
-								}
-
-								if room.Title != "" && app.checkAccess(me, room, membership) {
-									err := app.sendBroadCastToRoom(room)
-									if err == nil {
-										log.Println("To Room: ", room.Title)
-									} else {
+					if len(items) > 0 {
+						mwp := workerpool.New(10)
+						for _, membership := range items {
+							func(membership memberships.Membership) {
+								mwp.Submit(func() {
+									room, err := app.Client.Rooms().Get(membership.RoomID)
+									if err != nil {
 										errChan <- err
 										return
 									}
-								}
-								errChan <- nil
+
+									if room.Title != "" && app.checkAccess(me, room, membership) {
+										err := app.sendBroadCastToRoom(room)
+										if err == nil {
+											log.Println("To Room: ", room.Title)
+										} else {
+											errChan <- err
+											return
+										}
+									}
+									errChan <- nil
+								})
 							}(membership)
 						}
-						wg.Wait()
+						mwp.StopWait()
 					}
 				} else {
 					room, err := app.Client.Rooms().Get(roomID)
@@ -269,6 +255,10 @@ func (app *BroadcastToRoomsApplication) BroadcastToRoom(roomIDs []string) error
 }
 
 func (app *BroadcastToRoomsApplication) sendBroadCastToRoom(room *rooms.Room) error {
+	if app.Journal.completed(room.ID, "", jobActionMessage) {
+		log.Infof("Skipping %s, already messaged", room.Title)
+		return nil
+	}
 
 	membershipQueryParams := &memberships.ListOptions{
 		PersonEmail: app.UserEmail,
@@ -298,6 +288,7 @@ func (app *BroadcastToRoomsApplication) sendBroadCastToRoom(room *rooms.Room) er
 		} else {
 			log.Infof("Sent message: %s", sentMessage.ID)
 		}
+		app.Journal.record(room.ID, "", jobActionMessage)
 
 	} else {
 		return errors.New("You are not a member of this room")
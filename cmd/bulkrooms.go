@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/WebexCommunity/webex-go-sdk/v2/memberships"
+	"github.com/WebexCommunity/webex-go-sdk/v2/rooms"
+	"github.com/urfave/cli/v2"
+)
+
+// errLastModerator is returned when leaving a room would leave it without
+// a moderator
+var errLastModerator = errors.New("you are the last moderator of this room")
+
+// roomResult is the outcome of a rooms subcommand for one room
+type roomResult struct {
+	RoomID       string     `json:"roomId"`
+	Title        string     `json:"title"`
+	LastActivity *time.Time `json:"lastActivity,omitempty"`
+	Status       string     `json:"status"`
+	Detail       string     `json:"detail,omitempty"`
+}
+
+var roomResultHeader = []string{"roomId", "title", "lastActivity", "status", "detail"}
+
+// RoomsCMD function
+func (app *Application) RoomsCMD() *cli.Command {
+	return &cli.Command{
+		Name:    "rooms",
+		Aliases: []string{"rs"},
+		Usage:   "Act on many rooms at once",
+		Subcommands: []*cli.Command{
+			app.StaleRoomsCMD(),
+			app.LeaveRoomsCMD(),
+			app.RoomSelectCMD(),
+		},
+		Action: func(c *cli.Context) error {
+			return nil
+		},
+	}
+}
+
+// parseAge parses an age such as 180d, 2w or any Go duration eg. 36h
+func parseAge(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	day := 24 * time.Hour
+	for suffix, unit := range map[string]time.Duration{"d": day, "w": 7 * day} {
+		if strings.HasSuffix(s, suffix) {
+			n, err := strconv.Atoi(strings.TrimSuffix(s, suffix))
+			if err != nil || n < 0 {
+				return 0, fmt.Errorf("%s is not a valid age, use eg. 180d, 2w or 36h", s)
+			}
+			return time.Duration(n) * unit, nil
+		}
+	}
+	age, err := time.ParseDuration(s)
+	if err != nil || age < 0 {
+		return 0, fmt.Errorf("%s is not a valid age, use eg. 180d, 2w or 36h", s)
+	}
+	return age, nil
+}
+
+// isOwner reports whether the authenticated user created room
+func (app *Application) isOwner(room *rooms.Room) bool {
+	return app.Me != nil && room.CreatorID == app.Me.ID
+}
+
+// leaveRoom removes the authenticated user from room. Unless force is set,
+// the last moderator of a room with other members does not leave.
+func (app *Application) leaveRoom(room *rooms.Room, force bool) error {
+	mbrPage, err := app.Client.Memberships().List(&memberships.ListOptions{RoomID: room.ID, PersonEmail: app.Email, Max: 1})
+	if err != nil {
+		return err
+	}
+	if len(mbrPage.Items) == 0 {
+		return errors.New("You are not a member of this room")
+	}
+	membership := mbrPage.Items[0]
+	if membership.IsModerator && !force {
+		items, err := app.ListAllMemberships(room.ID)
+		if err != nil {
+			return err
+		}
+		if isLastModerator(items, membership.ID) {
+			return errLastModerator
+		}
+	}
+	return app.Client.Memberships().Delete(membership.ID)
+}
+
+// isLastModerator reports whether membershipID is the only moderator among
+// items while other members remain
+func isLastModerator(items []memberships.Membership, membershipID string) bool {
+	if len(items) <= 1 {
+		return false
+	}
+	for _, membership := range items {
+		if membership.IsModerator && membership.ID != membershipID {
+			return false
+		}
+	}
+	return true
+}
+
+func roomResultRows(results []roomResult) [][]string {
+	rows := make([][]string, 0, len(results))
+	for _, result := range results {
+		rows = append(rows, []string{result.RoomID, result.Title, formatReportTime(result.LastActivity), result.Status, result.Detail})
+	}
+	return rows
+}
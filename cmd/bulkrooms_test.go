@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/WebexCommunity/webex-go-sdk/v2/memberships"
+	"github.com/WebexCommunity/webex-go-sdk/v2/people"
+	"github.com/WebexCommunity/webex-go-sdk/v2/rooms"
+)
+
+func TestParseAge(t *testing.T) {
+	tests := map[string]time.Duration{
+		"180d": 180 * 24 * time.Hour,
+		"2w":   14 * 24 * time.Hour,
+		"36h":  36 * time.Hour,
+		"0d":   0,
+		" 7d ": 7 * 24 * time.Hour,
+	}
+	for in, expected := range tests {
+		got, err := parseAge(in)
+		if err != nil {
+			t.Errorf("parseAge(%q) returned error: %v", in, err)
+			continue
+		}
+		if got != expected {
+			t.Errorf("parseAge(%q) = %v, expected %v", in, got, expected)
+		}
+	}
+	for _, in := range []string{"", "d", "-3d", "soon", "1.5d"} {
+		if _, err := parseAge(in); err == nil {
+			t.Errorf("Expected parseAge(%q) to fail", in)
+		}
+	}
+}
+
+func TestIsLastModerator(t *testing.T) {
+	items := []memberships.Membership{
+		{ID: "m1", IsModerator: true},
+		{ID: "m2"},
+	}
+	if !isLastModerator(items, "m1") {
+		t.Error("Expected m1 to be the last moderator")
+	}
+	items[1].IsModerator = true
+	if isLastModerator(items, "m1") {
+		t.Error("Expected m1 not to be the last moderator when m2 moderates too")
+	}
+	if isLastModerator(items[:1], "m1") {
+		t.Error("Expected the only member of a room to be free to leave")
+	}
+}
+
+func TestIsOwner(t *testing.T) {
+	app := &Application{Me: &people.Person{ID: "me"}}
+	if !app.isOwner(&rooms.Room{CreatorID: "me"}) {
+		t.Error("Expected a room created by me to be owned")
+	}
+	if app.isOwner(&rooms.Room{CreatorID: "other"}) {
+		t.Error("Expected a room created by someone else not to be owned")
+	}
+	if (&Application{}).isOwner(&rooms.Room{CreatorID: ""}) {
+		t.Error("Expected no ownership without an authenticated user")
+	}
+}
+
+func TestStaleResultRows(t *testing.T) {
+	last := time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)
+	rows := roomResultRows([]roomResult{
+		{RoomID: "r1", Title: "Old", LastActivity: &last, Status: "warned", Detail: "left after 2024-06-15"},
+		{RoomID: "r2", Title: "Gone", Status: "gone"},
+	})
+	if len(rows) != 2 {
+		t.Fatalf("Expected 2 rows, got %d", len(rows))
+	}
+	if rows[0][2] != "2023-01-02T03:04:05Z" || rows[0][3] != "warned" {
+		t.Errorf("Unexpected first row: %v", rows[0])
+	}
+	if rows[1][2] != "" || len(rows[1]) != len(roomResultHeader) {
+		t.Errorf("Unexpected second row: %v", rows[1])
+	}
+}
@@ -0,0 +1,499 @@
+package cmd
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/WebexCommunity/webex-go-sdk/v2/attachmentactions"
+	"github.com/WebexCommunity/webex-go-sdk/v2/conversation"
+	"github.com/WebexCommunity/webex-go-sdk/v2/people"
+	log "github.com/sirupsen/logrus"
+	"github.com/tejzpr/webex-teams-cli/cmd/webexid"
+	"github.com/urfave/cli/v2"
+)
+
+const cardSubmissionsFile = "cards.json"
+
+// Formats of cards collect
+const (
+	cardFormatJSONL = "jsonl"
+	cardFormatCSV   = "csv"
+)
+
+// cardSubmission is a submission of an Adaptive Card
+type cardSubmission struct {
+	ActionID    string                 `json:"actionId"`
+	MessageID   string                 `json:"messageId"`
+	RoomID      string                 `json:"roomId"`
+	PersonID    string                 `json:"personId"`
+	PersonEmail string                 `json:"personEmail,omitempty"`
+	Inputs      map[string]interface{} `json:"inputs"`
+	Created     time.Time              `json:"created"`
+}
+
+var cardSubmissionHeader = []string{"actionId", "messageId", "roomId", "personId", "personEmail", "created", "inputs"}
+
+func (submission cardSubmission) record() []string {
+	inputs, _ := json.Marshal(submission.Inputs)
+	return []string{
+		submission.ActionID,
+		submission.MessageID,
+		submission.RoomID,
+		submission.PersonID,
+		submission.PersonEmail,
+		submission.Created.UTC().Format(time.RFC3339),
+		string(inputs),
+	}
+}
+
+// cardStore is the persisted list of submissions by card message ID. The
+// API cannot list the actions of a message, so collect keeps them here.
+type cardStore struct {
+	Submissions map[string][]cardSubmission `json:"submissions"`
+}
+
+// CardsCMD function
+func (app *Application) CardsCMD() *cli.Command {
+	return &cli.Command{
+		Name:    "cards",
+		Aliases: []string{"cd"},
+		Usage:   "Collect and summarize Adaptive Card submissions",
+		Subcommands: []*cli.Command{
+			app.cardsCollectCMD(),
+			app.cardsResponsesCMD(),
+			app.cardsTallyCMD(),
+		},
+		Action: func(c *cli.Context) error {
+			return nil
+		},
+	}
+}
+
+func (app *Application) cardsCollectCMD() *cli.Command {
+	return &cli.Command{
+		Name:        "collect",
+		Aliases:     []string{"c"},
+		Description: "Listen for card submissions and write each one as it arrives. Submissions are also kept in the state directory for cards responses and cards tally",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:     "message",
+				Aliases:  []string{"m"},
+				Value:    "",
+				Usage:    "Only submissions of the card with this message ID",
+				Required: false,
+			},
+			&cli.StringFlag{
+				Name:     "roomID",
+				Aliases:  []string{"rid"},
+				Value:    "",
+				Usage:    "Only submissions made in this room",
+				Required: false,
+			},
+			&cli.StringFlag{
+				Name:     "format",
+				Aliases:  []string{"fmt"},
+				Value:    cardFormatJSONL,
+				Usage:    "Format of the submissions written, one of jsonl or csv. Default is jsonl",
+				Required: false,
+			},
+			&cli.StringFlag{
+				Name:     "out",
+				Aliases:  []string{"o"},
+				Value:    "",
+				Usage:    "Path of a file the submissions are appended to. Defaults to stdout",
+				Required: false,
+			},
+			&cli.DurationFlag{
+				Name:     "timeout",
+				Aliases:  []string{"t"},
+				Value:    0,
+				Usage:    "Stop collecting after this long eg. 30m. Default is to run until interrupted",
+				Required: false,
+			},
+		},
+		Action: func(c *cli.Context) error {
+			format := c.String("format")
+			if format != cardFormatJSONL && format != cardFormatCSV {
+				return errors.New("Allowed values for format flag are jsonl and csv")
+			}
+			collector := &cardCollector{Application: app, MessageID: c.String("message")}
+			if roomID := c.String("roomID"); roomID != "" {
+				parsedRoomID, err := app.parseRoomID(roomID)
+				if err != nil {
+					return err
+				}
+				collector.RoomID = parsedRoomID
+			}
+
+			var out io.Writer = os.Stdout
+			if outPath := c.String("out"); outPath != "" {
+				outFile, err := os.OpenFile(outPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+				if err != nil {
+					return err
+				}
+				defer outFile.Close()
+				out = outFile
+			}
+			collector.write = cardSubmissionWriter(out, format)
+
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			if timeout := c.Duration("timeout"); timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, timeout)
+				defer cancel()
+			}
+			return collector.Run(ctx)
+		},
+	}
+}
+
+func (app *Application) cardsResponsesCMD() *cli.Command {
+	return &cli.Command{
+		Name:        "responses",
+		Aliases:     []string{"r"},
+		Description: "Show the collected submissions of a card message",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:     "message",
+				Aliases:  []string{"m"},
+				Value:    "",
+				Usage:    "ID of the card message",
+				Required: true,
+			},
+			&cli.BoolFlag{
+				Name:     "latest",
+				Aliases:  []string{"l"},
+				Value:    false,
+				Usage:    "Only the latest submission of each person",
+				Required: false,
+			},
+			&cli.StringFlag{
+				Name:     "output",
+				Aliases:  []string{"o"},
+				Value:    outputTable,
+				Usage:    "Output format, one of table, csv or json. Default is table",
+				Required: false,
+			},
+		},
+		Action: func(c *cli.Context) error {
+			format := c.String("output")
+			if err := validateOutputFormat(format); err != nil {
+				return err
+			}
+			submissions, err := app.cardSubmissions(c.String("message"))
+			if err != nil {
+				return err
+			}
+			if c.Bool("latest") {
+				submissions = latestSubmissions(submissions)
+			}
+			rows := make([][]string, 0, len(submissions))
+			for _, submission := range submissions {
+				rows = append(rows, submission.record())
+			}
+			return writeOutput(os.Stdout, format, cardSubmissionHeader, rows, submissions)
+		},
+	}
+}
+
+func (app *Application) cardsTallyCMD() *cli.Command {
+	return &cli.Command{
+		Name:        "tally",
+		Aliases:     []string{"t"},
+		Description: "Count the values submitted for each input of a card, using the latest submission of each person",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:     "message",
+				Aliases:  []string{"m"},
+				Value:    "",
+				Usage:    "ID of the card message",
+				Required: true,
+			},
+			&cli.StringFlag{
+				Name:     "input",
+				Aliases:  []string{"i"},
+				Value:    "",
+				Usage:    "Only count this input. Defaults to all inputs",
+				Required: false,
+			},
+			&cli.StringSliceFlag{
+				Name:     "multi",
+				Usage:    "Name of a multi-select input whose comma separated values are counted apart. Can be repeated",
+				Required: false,
+			},
+			&cli.StringFlag{
+				Name:     "output",
+				Aliases:  []string{"o"},
+				Value:    outputTable,
+				Usage:    "Output format, one of table, csv or json. Default is table",
+				Required: false,
+			},
+		},
+		Action: func(c *cli.Context) error {
+			format := c.String("output")
+			if err := validateOutputFormat(format); err != nil {
+				return err
+			}
+			submissions, err := app.cardSubmissions(c.String("message"))
+			if err != nil {
+				return err
+			}
+			multi := make(map[string]bool)
+			for _, name := range c.StringSlice("multi") {
+				multi[strings.TrimSpace(name)] = true
+			}
+			counts := tallySubmissions(latestSubmissions(submissions), c.String("input"), multi)
+			return writeOutput(os.Stdout, format, cardTallyHeader, cardTallyRows(counts), counts)
+		},
+	}
+}
+
+// cardCollector records the card submissions seen over the conversation
+// WebSocket
+type cardCollector struct {
+	*Application
+	MessageID    string
+	RoomID       string
+	write        func(cardSubmission) error
+	personEmails sync.Map
+	// people is set by Run before handlers start, the SDK creates its
+	// clients lazily and without a lock
+	people *people.Client
+
+	// mu serializes the store update and the write of submissions, the SDK
+	// runs every cardAction handler in its own goroutine
+	mu sync.Mutex
+}
+
+// Run collects submissions until ctx is done
+func (collector *cardCollector) Run(ctx context.Context) error {
+	conv, err := collector.Client.Conversation()
+	if err != nil {
+		return err
+	}
+	attachmentActions := collector.Client.AttachmentActions()
+	collector.people = collector.Client.People()
+	conv.On("cardAction", func(activity *conversation.Activity) {
+		actionID, err := webexid.Encode(webexid.AttachmentAction, activity.ID)
+		if err != nil {
+			log.Debugf("Invalid attachment action %s: %s", activity.ID, err.Error())
+			return
+		}
+		action, err := attachmentActions.Get(actionID)
+		if err != nil {
+			log.Errorf("Error fetching attachment action %s: %s", activity.ID, err.Error())
+			return
+		}
+		if err := collector.collect(action); err != nil {
+			log.Error(err.Error())
+		}
+	})
+	if err := conv.Connect(); err != nil {
+		return err
+	}
+	defer conv.Disconnect()
+	log.Info("Collecting card submissions")
+	<-ctx.Done()
+	return nil
+}
+
+// collect stores and writes a submission that passes the filters
+func (collector *cardCollector) collect(action *attachmentactions.AttachmentAction) error {
+	if collector.MessageID != "" && action.MessageID != collector.MessageID {
+		return nil
+	}
+	if collector.RoomID != "" && action.RoomID != collector.RoomID {
+		return nil
+	}
+	submission := cardSubmission{
+		ActionID:    action.ID,
+		MessageID:   action.MessageID,
+		RoomID:      action.RoomID,
+		PersonID:    action.PersonID,
+		PersonEmail: collector.personEmail(action.PersonID),
+		Inputs:      action.Inputs,
+		Created:     time.Now(),
+	}
+	if action.Created != nil {
+		submission.Created = *action.Created
+	}
+	collector.mu.Lock()
+	defer collector.mu.Unlock()
+	added, err := collector.storeCardSubmission(submission)
+	if err != nil || !added {
+		return err
+	}
+	return collector.write(submission)
+}
+
+// personEmail resolves and caches the primary email of a person. Lookup
+// failures leave it empty.
+func (collector *cardCollector) personEmail(personID string) string {
+	if cached, ok := collector.personEmails.Load(personID); ok {
+		return cached.(string)
+	}
+	person, err := collector.people.Get(personID)
+	if err != nil || len(person.Emails) == 0 {
+		return ""
+	}
+	collector.personEmails.Store(personID, person.Emails[0])
+	return person.Emails[0]
+}
+
+// storeCardSubmission adds a submission to the store and reports whether it
+// was new
+func (app *Application) storeCardSubmission(submission cardSubmission) (bool, error) {
+	statePath, err := app.statePath(cardSubmissionsFile)
+	if err != nil {
+		return false, err
+	}
+	store := &cardStore{Submissions: make(map[string][]cardSubmission)}
+	if err := loadStateFile(statePath, store); err != nil {
+		return false, err
+	}
+	if store.Submissions == nil {
+		store.Submissions = make(map[string][]cardSubmission)
+	}
+	for _, existing := range store.Submissions[submission.MessageID] {
+		if existing.ActionID == submission.ActionID {
+			return false, nil
+		}
+	}
+	store.Submissions[submission.MessageID] = append(store.Submissions[submission.MessageID], submission)
+	return true, saveStateFile(statePath, store)
+}
+
+// cardSubmissions returns the collected submissions of a card message
+func (app *Application) cardSubmissions(messageID string) ([]cardSubmission, error) {
+	statePath, err := app.statePath(cardSubmissionsFile)
+	if err != nil {
+		return nil, err
+	}
+	store := &cardStore{}
+	if err := loadStateFile(statePath, store); err != nil {
+		return nil, err
+	}
+	submissions := store.Submissions[messageID]
+	if len(submissions) == 0 {
+		return nil, fmt.Errorf("No submissions collected for message %s, run cards collect while people respond", messageID)
+	}
+	return submissions, nil
+}
+
+// latestSubmissions keeps the latest submission of each person, in the
+// order they were first made
+func latestSubmissions(submissions []cardSubmission) []cardSubmission {
+	latest := make(map[string]int)
+	result := make([]cardSubmission, 0, len(submissions))
+	for _, submission := range submissions {
+		if i, ok := latest[submission.PersonID]; ok {
+			if !submission.Created.Before(result[i].Created) {
+				result[i] = submission
+			}
+			continue
+		}
+		latest[submission.PersonID] = len(result)
+		result = append(result, submission)
+	}
+	return result
+}
+
+// cardTally is the number of people who submitted a value for an input
+type cardTally struct {
+	Input   string  `json:"input"`
+	Value   string  `json:"value"`
+	Count   int     `json:"count"`
+	Percent float64 `json:"percent"`
+}
+
+var cardTallyHeader = []string{"input", "value", "count", "percent"}
+
+// tallySubmissions counts the values of each input, or of the named input.
+// Comma separated values of the multi inputs are counted apart, values of
+// other inputs such as free text are counted as they are.
+func tallySubmissions(submissions []cardSubmission, input string, multi map[string]bool) []cardTally {
+	counts := make(map[string]map[string]int)
+	for _, submission := range submissions {
+		for name, value := range submission.Inputs {
+			if input != "" && name != input {
+				continue
+			}
+			if counts[name] == nil {
+				counts[name] = make(map[string]int)
+			}
+			values := []string{fmt.Sprint(value)}
+			if multi[name] {
+				values = strings.Split(values[0], ",")
+			}
+			for _, choice := range values {
+				if choice = strings.TrimSpace(choice); choice != "" {
+					counts[name][choice]++
+				}
+			}
+		}
+	}
+
+	tallies := make([]cardTally, 0)
+	for name, values := range counts {
+		for value, count := range values {
+			percent := 0.0
+			if len(submissions) > 0 {
+				percent = float64(count) * 100 / float64(len(submissions))
+			}
+			tallies = append(tallies, cardTally{Input: name, Value: value, Count: count, Percent: percent})
+		}
+	}
+	sort.Slice(tallies, func(i, j int) bool {
+		if tallies[i].Input != tallies[j].Input {
+			return tallies[i].Input < tallies[j].Input
+		}
+		if tallies[i].Count != tallies[j].Count {
+			return tallies[i].Count > tallies[j].Count
+		}
+		return tallies[i].Value < tallies[j].Value
+	})
+	return tallies
+}
+
+func cardTallyRows(tallies []cardTally) [][]string {
+	rows := make([][]string, 0, len(tallies))
+	for _, tally := range tallies {
+		rows = append(rows, []string{tally.Input, tally.Value, strconv.Itoa(tally.Count), strconv.FormatFloat(tally.Percent, 'f', 1, 64)})
+	}
+	return rows
+}
+
+// cardSubmissionWriter returns a function writing submissions to w as JSONL
+// or CSV, with the CSV header before the first row
+func cardSubmissionWriter(w io.Writer, format string) func(cardSubmission) error {
+	if format == cardFormatCSV {
+		csvWriter := csv.NewWriter(w)
+		headerWritten := false
+		return func(submission cardSubmission) error {
+			if !headerWritten {
+				csvWriter.Write(cardSubmissionHeader)
+				headerWritten = true
+			}
+			csvWriter.Write(submission.record())
+			csvWriter.Flush()
+			return csvWriter.Error()
+		}
+	}
+	encoder := json.NewEncoder(w)
+	return func(submission cardSubmission) error {
+		return encoder.Encode(submission)
+	}
+}
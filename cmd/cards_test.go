@@ -0,0 +1,141 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/WebexCommunity/webex-go-sdk/v2/attachmentactions"
+)
+
+func TestStoreCardSubmission(t *testing.T) {
+	app := &Application{StateDir: t.TempDir()}
+	submission := cardSubmission{ActionID: "a1", MessageID: "m1", PersonID: "p1", Inputs: map[string]interface{}{"choice": "yes"}}
+
+	added, err := app.storeCardSubmission(submission)
+	if err != nil || !added {
+		t.Fatalf("storeCardSubmission() = %v, %v", added, err)
+	}
+	added, err = app.storeCardSubmission(submission)
+	if err != nil || added {
+		t.Errorf("Expected a repeated action to be ignored, got %v, %v", added, err)
+	}
+
+	submissions, err := app.cardSubmissions("m1")
+	if err != nil {
+		t.Fatalf("cardSubmissions() error = %v", err)
+	}
+	if len(submissions) != 1 || submissions[0].Inputs["choice"] != "yes" {
+		t.Errorf("Unexpected submissions %+v", submissions)
+	}
+	if _, err := app.cardSubmissions("m2"); err == nil {
+		t.Error("Expected an error for a message without submissions")
+	}
+}
+
+func TestLatestSubmissions(t *testing.T) {
+	now := time.Now()
+	submissions := []cardSubmission{
+		{ActionID: "a1", PersonID: "p1", Created: now},
+		{ActionID: "a2", PersonID: "p2", Created: now},
+		{ActionID: "a3", PersonID: "p1", Created: now.Add(time.Minute)},
+	}
+	latest := latestSubmissions(submissions)
+	if len(latest) != 2 || latest[0].ActionID != "a3" || latest[1].ActionID != "a2" {
+		t.Errorf("Unexpected latest submissions %+v", latest)
+	}
+}
+
+func TestTallySubmissions(t *testing.T) {
+	submissions := []cardSubmission{
+		{PersonID: "p1", Inputs: map[string]interface{}{"lunch": "pizza", "extras": "salad,soda", "comment": "Yes, definitely"}},
+		{PersonID: "p2", Inputs: map[string]interface{}{"lunch": "pizza", "extras": "soda"}},
+		{PersonID: "p3", Inputs: map[string]interface{}{"lunch": "tacos"}},
+		{PersonID: "p4", Inputs: map[string]interface{}{"lunch": "pizza"}},
+	}
+
+	multi := map[string]bool{"extras": true}
+	tallies := tallySubmissions(submissions, "lunch", multi)
+	if len(tallies) != 2 {
+		t.Fatalf("Expected 2 tallies, got %+v", tallies)
+	}
+	if tallies[0] != (cardTally{Input: "lunch", Value: "pizza", Count: 3, Percent: 75}) {
+		t.Errorf("Unexpected first tally %+v", tallies[0])
+	}
+
+	tallies = tallySubmissions(submissions, "", multi)
+	if len(tallies) != 5 || tallies[1].Input != "extras" || tallies[1].Value != "soda" || tallies[1].Count != 2 {
+		t.Errorf("Unexpected tallies %+v", tallies)
+	}
+	if tallies[0] != (cardTally{Input: "comment", Value: "Yes, definitely", Count: 1, Percent: 25}) {
+		t.Errorf("Expected free text to be counted as it is, got %+v", tallies[0])
+	}
+
+	tallies = tallySubmissions(submissions, "extras", nil)
+	if len(tallies) != 2 || tallies[0].Value != "salad,soda" {
+		t.Errorf("Expected values of inputs not marked multi to be counted whole, got %+v", tallies)
+	}
+	if rows := cardTallyRows(tallySubmissions(submissions, "extras", multi)); rows[0][3] != "50.0" {
+		t.Errorf("Expected percent 50.0, got %s", rows[0][3])
+	}
+}
+
+func TestCardSubmissionWriter(t *testing.T) {
+	created := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	submission := cardSubmission{ActionID: "a1", MessageID: "m1", RoomID: "r1", PersonID: "p1", PersonEmail: "a@example.com", Inputs: map[string]interface{}{"choice": "yes"}, Created: created}
+
+	var csvOut bytes.Buffer
+	write := cardSubmissionWriter(&csvOut, cardFormatCSV)
+	write(submission)
+	write(submission)
+	lines := strings.Split(strings.TrimSpace(csvOut.String()), "\n")
+	if len(lines) != 3 || lines[0] != strings.Join(cardSubmissionHeader, ",") {
+		t.Fatalf("Unexpected CSV %q", csvOut.String())
+	}
+	if lines[1] != `a1,m1,r1,p1,a@example.com,2024-05-01T12:00:00Z,"{""choice"":""yes""}"` {
+		t.Errorf("Unexpected CSV row %q", lines[1])
+	}
+
+	var jsonlOut bytes.Buffer
+	cardSubmissionWriter(&jsonlOut, cardFormatJSONL)(submission)
+	if !strings.Contains(jsonlOut.String(), `"personEmail":"a@example.com"`) || !strings.HasSuffix(jsonlOut.String(), "\n") {
+		t.Errorf("Unexpected JSONL %q", jsonlOut.String())
+	}
+}
+
+func TestCardCollectorConcurrentSubmissions(t *testing.T) {
+	app := &Application{StateDir: t.TempDir()}
+	var out bytes.Buffer
+	collector := &cardCollector{Application: app, MessageID: "m1", write: cardSubmissionWriter(&out, cardFormatCSV)}
+
+	const total = 20
+	var wg sync.WaitGroup
+	for i := 0; i < total; i++ {
+		personID := fmt.Sprintf("p%d", i)
+		collector.personEmails.Store(personID, personID+"@example.com")
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			action := &attachmentactions.AttachmentAction{ID: fmt.Sprintf("a%d", i), MessageID: "m1", PersonID: personID, Inputs: map[string]interface{}{"choice": "yes"}}
+			if err := collector.collect(action); err != nil {
+				t.Errorf("collect() error = %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	submissions, err := app.cardSubmissions("m1")
+	if err != nil {
+		t.Fatalf("cardSubmissions() error = %v", err)
+	}
+	if len(submissions) != total {
+		t.Errorf("Expected %d stored submissions, got %d", total, len(submissions))
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != total+1 || lines[0] != strings.Join(cardSubmissionHeader, ",") {
+		t.Errorf("Expected one header and %d rows, got %d lines", total, len(lines))
+	}
+}
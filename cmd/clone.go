@@ -0,0 +1,204 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/WebexCommunity/webex-go-sdk/v2/memberships"
+	"github.com/WebexCommunity/webex-go-sdk/v2/messages"
+	log "github.com/sirupsen/logrus"
+	"github.com/urfave/cli/v2"
+)
+
+// cloneTemplateData is available to the kickoff message template
+type cloneTemplateData struct {
+	Title   string
+	RoomID  string
+	Source  string
+	Members int
+	Now     time.Time
+}
+
+// clonedMember is a row of room clone
+type clonedMember struct {
+	Email     string `json:"email"`
+	Moderator bool   `json:"moderator"`
+	Status    string `json:"status"`
+	Detail    string `json:"detail,omitempty"`
+}
+
+var clonedMemberHeader = []string{"email", "moderator", "status", "detail"}
+
+// CloneRoomCMD function
+func (app *Application) CloneRoomCMD() *cli.Command {
+	return &cli.Command{
+		Name:        "clone",
+		Aliases:     []string{"cl"},
+		Description: "Create a new group room with the members and moderators of an existing room",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:     "from",
+				Aliases:  []string{"f"},
+				Value:    "",
+				Usage:    "ID of the room to copy members from",
+				Required: true,
+			},
+			&cli.StringFlag{
+				Name:     "title",
+				Aliases:  []string{"t"},
+				Value:    "",
+				Usage:    "Title of the new room",
+				Required: true,
+			},
+			&cli.StringFlag{
+				Name:     "description",
+				Aliases:  []string{"d"},
+				Value:    "",
+				Usage:    "Description of the new room",
+				Required: false,
+			},
+			&cli.BoolFlag{
+				Name:     "same-team",
+				Aliases:  []string{"st"},
+				Value:    false,
+				Usage:    "Create the new room in the team of the source room",
+				Required: false,
+			},
+			&cli.StringFlag{
+				Name:     "kickoff",
+				Aliases:  []string{"k"},
+				Value:    "",
+				Usage:    "Message to post once members are added. Rendered as a Go template with .Title, .RoomID, .Source, .Members and .Now",
+				Required: false,
+			},
+			&cli.StringFlag{
+				Name:     "output",
+				Aliases:  []string{"o"},
+				Value:    outputTable,
+				Usage:    "Output format of the member results, one of table, csv or json. Default is table",
+				Required: false,
+			},
+		},
+		Action: func(c *cli.Context) error {
+			format := c.String("output")
+			if err := validateOutputFormat(format); err != nil {
+				return err
+			}
+			sourceID, err := app.parseRoomID(c.String("from"))
+			if err != nil {
+				return err
+			}
+			var kickoff *template.Template
+			if text := c.String("kickoff"); text != "" {
+				if kickoff, err = template.New("kickoff").Funcs(scheduleTemplateFuncs).Parse(text); err != nil {
+					return errors.New("invalid kickoff template: " + err.Error())
+				}
+			}
+
+			room, results, err := app.CloneRoom(sourceID, c.String("title"), c.String("description"), c.Bool("same-team"), kickoff)
+			if err != nil {
+				return err
+			}
+			log.Infof("Created room %s with ID %s", room.Title, room.ID)
+			return writeOutput(os.Stdout, format, clonedMemberHeader, clonedMemberRows(results), results)
+		},
+	}
+}
+
+// CloneRoom creates a room titled title with every member of the source
+// room. Members that cannot be added are reported in the results.
+func (app *Application) CloneRoom(sourceID string, title string, description string, sameTeam bool, kickoff *template.Template) (*roomDetails, []clonedMember, error) {
+	source, err := app.getRoomDetails(sourceID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if source.Type == "direct" {
+		return nil, nil, errors.New("Cannot clone a 1:1 room")
+	}
+	items, err := app.ListAllMemberships(source.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	teamID := ""
+	if sameTeam {
+		teamID = source.TeamID
+	}
+	room, err := app.createRoom(title, description, teamID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	results := make([]clonedMember, 0, len(items))
+	for _, change := range cloneChanges(items, app.Email) {
+		result := clonedMember{Email: change.Email, Moderator: change.Moderator, Status: "added"}
+		if change.Action == spaceUpdateMember {
+			result.Status = "updated"
+		}
+		if err := app.applyMemberChange(room.ID, room.Title, change, false); err != nil {
+			result.Status = "failed"
+			result.Detail = err.Error()
+		}
+		results = append(results, result)
+	}
+
+	if kickoff != nil {
+		var buf bytes.Buffer
+		err := kickoff.Execute(&buf, cloneTemplateData{
+			Title:   room.Title,
+			RoomID:  room.ID,
+			Source:  source.Title,
+			Members: countCloned(results),
+			Now:     time.Now(),
+		})
+		if err == nil {
+			_, err = app.Client.Messages().Create(&messages.Message{RoomID: room.ID, Markdown: buf.String()})
+		}
+		if err != nil {
+			log.Errorf("Error posting the kickoff message: %v", err)
+		}
+	}
+	return room, results, nil
+}
+
+// cloneChanges returns the changes that copy items into a new room. The
+// creator is already a member, so only their moderator flag is copied.
+func cloneChanges(items []memberships.Membership, self string) []spaceChange {
+	changes := make([]spaceChange, 0, len(items))
+	for _, membership := range items {
+		if membership.PersonEmail == "" {
+			continue
+		}
+		if strings.EqualFold(membership.PersonEmail, self) {
+			if membership.IsModerator {
+				changes = append(changes, spaceChange{Action: spaceUpdateMember, Email: membership.PersonEmail, Moderator: true})
+			}
+			continue
+		}
+		changes = append(changes, spaceChange{Action: spaceAddMember, Email: membership.PersonEmail, Moderator: membership.IsModerator})
+	}
+	return changes
+}
+
+func countCloned(results []clonedMember) int {
+	n := 0
+	for _, result := range results {
+		if result.Status == "added" {
+			n++
+		}
+	}
+	return n
+}
+
+func clonedMemberRows(results []clonedMember) [][]string {
+	rows := make([][]string, 0, len(results))
+	for _, result := range results {
+		rows = append(rows, []string{result.Email, strconv.FormatBool(result.Moderator), result.Status, result.Detail})
+	}
+	return rows
+}
@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/WebexCommunity/webex-go-sdk/v2/memberships"
+)
+
+func TestCloneChanges(t *testing.T) {
+	items := []memberships.Membership{
+		{PersonEmail: "Me@example.com", IsModerator: true},
+		{PersonEmail: "lead@example.com", IsModerator: true},
+		{PersonEmail: "dev@example.com"},
+		{PersonEmail: ""},
+	}
+	changes := cloneChanges(items, "me@example.com")
+	if len(changes) != 3 {
+		t.Fatalf("Expected 3 changes, got %d: %+v", len(changes), changes)
+	}
+	if changes[0].Action != spaceUpdateMember || !changes[0].Moderator {
+		t.Errorf("Expected the creator to be made moderator, got %+v", changes[0])
+	}
+	if changes[1].Action != spaceAddMember || changes[1].Email != "lead@example.com" || !changes[1].Moderator {
+		t.Errorf("Expected lead to be added as moderator, got %+v", changes[1])
+	}
+	if changes[2].Action != spaceAddMember || changes[2].Moderator {
+		t.Errorf("Expected dev to be added as member, got %+v", changes[2])
+	}
+
+	items[0].IsModerator = false
+	if changes := cloneChanges(items, "me@example.com"); len(changes) != 2 {
+		t.Errorf("Expected no change for a creator who is not a moderator, got %+v", changes)
+	}
+}
+
+func TestCountCloned(t *testing.T) {
+	results := []clonedMember{
+		{Email: "a@example.com", Status: "added"},
+		{Email: "b@example.com", Status: "failed", Detail: "error adding b@example.com"},
+		{Email: "me@example.com", Status: "updated"},
+	}
+	if n := countCloned(results); n != 1 {
+		t.Errorf("Expected 1 cloned member, got %d", n)
+	}
+	rows := clonedMemberRows(results)
+	if len(rows) != 3 || rows[1][2] != "failed" || len(rows[1]) != len(clonedMemberHeader) {
+		t.Errorf("Unexpected rows: %v", rows)
+	}
+}
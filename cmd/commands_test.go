@@ -51,7 +51,7 @@ func TestRoomCMDStructure(t *testing.T) {
 	}
 
 	// Check subcommands
-	expectedSubcommands := []string{"message", "addmembers", "exportmembers", "removemembers", "broadcast"}
+	expectedSubcommands := []string{"message", "addmembers", "exportmembers", "removemembers", "syncmembers", "moderators", "broadcast", "status", "create", "update", "lock", "unlock", "info", "delete", "clone"}
 	if len(cmd.Subcommands) != len(expectedSubcommands) {
 		t.Errorf("Expected %d subcommands, got %d", len(expectedSubcommands), len(cmd.Subcommands))
 	}
@@ -63,6 +63,37 @@ func TestRoomCMDStructure(t *testing.T) {
 	}
 }
 
+// --- Test StatusMessageCMD structure ---
+
+func TestStatusMessageCMDStructure(t *testing.T) {
+	app := &Application{}
+	cmd := app.StatusMessageCMD()
+
+	if cmd.Name != "status" {
+		t.Errorf("Expected command name 'status', got %q", cmd.Name)
+	}
+
+	requiredFlags := []string{"key", "text"}
+	for _, flagName := range requiredFlags {
+		flag := getFlagByName(cmd.Flags, flagName)
+		if flag == nil {
+			t.Errorf("Expected required flag %q not found", flagName)
+		} else if !flag.(*cli.StringFlag).Required {
+			t.Errorf("Expected flag %q to be required", flagName)
+		}
+	}
+
+	optionalFlags := []string{"final", "thread-log"}
+	for _, flagName := range optionalFlags {
+		flag := getFlagByName(cmd.Flags, flagName)
+		if flag == nil {
+			t.Errorf("Expected optional flag %q not found", flagName)
+		} else if flag.(*cli.BoolFlag).Value {
+			t.Errorf("Expected flag %q to default to false", flagName)
+		}
+	}
+}
+
 // --- Test ChatCMD structure ---
 
 func TestChatCMDStructure(t *testing.T) {
@@ -111,7 +142,7 @@ func TestWebexUtilsStructure(t *testing.T) {
 	}
 
 	// Check subcommands
-	expectedSubcommands := []string{"findroom", "listrooms"}
+	expectedSubcommands := []string{"findroom", "listrooms", "id"}
 	if len(cmd.Subcommands) != len(expectedSubcommands) {
 		t.Errorf("Expected %d subcommands, got %d", len(expectedSubcommands), len(cmd.Subcommands))
 	}
@@ -0,0 +1,244 @@
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/WebexCommunity/webex-go-sdk/v2/messages"
+	log "github.com/sirupsen/logrus"
+)
+
+const dedupStateFile = "dedup.json"
+
+// MessageDeduplicator suppresses repeats of the same message to the same
+// target within Window. State is persisted to StatePath so repeats are
+// detected across CLI invocations, and a lock file next to it serializes
+// processes sharing the state directory.
+type MessageDeduplicator struct {
+	StatePath string
+	Window    time.Duration
+	mu        sync.Mutex
+}
+
+// dedupEntry tracks a message seen within the current window
+type dedupEntry struct {
+	RoomID      string    `json:"roomId,omitempty"`
+	PersonID    string    `json:"personId,omitempty"`
+	PersonEmail string    `json:"personEmail,omitempty"`
+	Text        string    `json:"text"`
+	WindowStart time.Time `json:"windowStart"`
+	Repeats     int       `json:"repeats"`
+
+	// key is the key of an expired entry in the state
+	key string
+}
+
+// NewMessageDeduplicator returns a deduplicator backed by the state directory
+func (app *Application) NewMessageDeduplicator(window time.Duration) (*MessageDeduplicator, error) {
+	statePath, err := app.statePath(dedupStateFile)
+	if err != nil {
+		return nil, err
+	}
+	return &MessageDeduplicator{StatePath: statePath, Window: window}, nil
+}
+
+// normalizeMessage lowercases the text and collapses whitespace so trivially
+// different renderings of the same alert hash identically
+func normalizeMessage(text string) string {
+	return strings.Join(strings.Fields(strings.ToLower(text)), " ")
+}
+
+func dedupKey(params *SendMessageParams) string {
+	hasher := sha256.New()
+	for _, part := range []string{params.RoomID, params.PersonID, params.PersonEmail, params.Filename, normalizeMessage(params.Text)} {
+		hasher.Write([]byte(part))
+		hasher.Write([]byte{0})
+	}
+	return hex.EncodeToString(hasher.Sum(nil))
+}
+
+// lock serializes access to the state within the process and across
+// processes. It returns a function releasing the lock.
+func (d *MessageDeduplicator) lock() (func(), error) {
+	d.mu.Lock()
+	unlockFile, err := lockStateFile(d.StatePath)
+	if err != nil {
+		d.mu.Unlock()
+		return nil, err
+	}
+	return func() {
+		unlockFile()
+		d.mu.Unlock()
+	}, nil
+}
+
+func (d *MessageDeduplicator) load() (map[string]*dedupEntry, error) {
+	entries := make(map[string]*dedupEntry)
+	if err := loadStateFile(d.StatePath, &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// Check records the message and reports whether it should be sent. A message
+// is suppressed when the same content was sent to the same target within the
+// current window.
+func (d *MessageDeduplicator) Check(params *SendMessageParams, now time.Time) (bool, error) {
+	unlock, err := d.lock()
+	if err != nil {
+		return false, err
+	}
+	defer unlock()
+
+	entries, err := d.load()
+	if err != nil {
+		return false, err
+	}
+
+	key := dedupKey(params)
+	if entry, ok := entries[key]; ok {
+		if now.Before(entry.WindowStart.Add(d.Window)) {
+			entry.Repeats++
+			return false, saveStateFile(d.StatePath, entries)
+		}
+		if entry.Repeats > 0 {
+			// The closed window is kept under its own key until its summary
+			// is sent
+			entries[key+"@"+entry.WindowStart.Format(time.RFC3339Nano)] = entry
+		}
+	}
+
+	entries[key] = &dedupEntry{
+		RoomID:      params.RoomID,
+		PersonID:    params.PersonID,
+		PersonEmail: params.PersonEmail,
+		Text:        params.Text,
+		WindowStart: now,
+	}
+	return true, saveStateFile(d.StatePath, entries)
+}
+
+// Forget drops the entry for a message, used when sending it failed so a
+// retry is not mistaken for a repeat
+func (d *MessageDeduplicator) Forget(params *SendMessageParams) error {
+	unlock, err := d.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	entries, err := d.load()
+	if err != nil {
+		return err
+	}
+	delete(entries, dedupKey(params))
+	return saveStateFile(d.StatePath, entries)
+}
+
+// Expired removes every entry whose window has closed without repeats and
+// returns those that had suppressed repeats, so a summary can be posted for
+// them. They stay in the state until Summarized, so a summary that failed to
+// send is returned again.
+func (d *MessageDeduplicator) Expired(now time.Time) ([]dedupEntry, error) {
+	unlock, err := d.lock()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	entries, err := d.load()
+	if err != nil {
+		return nil, err
+	}
+
+	var expired []dedupEntry
+	for key, entry := range entries {
+		if now.Before(entry.WindowStart.Add(d.Window)) {
+			continue
+		}
+		if entry.Repeats > 0 {
+			summary := *entry
+			summary.key = key
+			expired = append(expired, summary)
+			continue
+		}
+		delete(entries, key)
+	}
+	return expired, saveStateFile(d.StatePath, entries)
+}
+
+// Summarized removes an entry returned by Expired once its summary is sent
+func (d *MessageDeduplicator) Summarized(entry dedupEntry) error {
+	unlock, err := d.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	entries, err := d.load()
+	if err != nil {
+		return err
+	}
+	delete(entries, entry.key)
+	return saveStateFile(d.StatePath, entries)
+}
+
+// summaryText renders the message posted once a window with repeats closes
+func (d *MessageDeduplicator) summaryText(entry dedupEntry) string {
+	return fmt.Sprintf("Previous message repeated %d times in the last %s:\n\n%s", entry.Repeats, d.Window, entry.Text)
+}
+
+// FlushDedupSummaries posts a "repeated N times" summary for each closed
+// window. Summaries that fail to send with a retryable error are kept for
+// the next flush.
+func (app *Application) FlushDedupSummaries(dedup *MessageDeduplicator) error {
+	expired, err := dedup.Expired(time.Now())
+	if err != nil {
+		return err
+	}
+	for _, entry := range expired {
+		params := &SendMessageParams{
+			RoomID:      entry.RoomID,
+			PersonID:    entry.PersonID,
+			PersonEmail: entry.PersonEmail,
+			Text:        dedup.summaryText(entry),
+		}
+		if _, err := app.SendMessage2Room(params); err != nil {
+			log.Errorf("Failed to send repeat summary: %s", err.Error())
+			if isRetryableSendError(err) {
+				continue
+			}
+		}
+		if err := dedup.Summarized(entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SendDedupedMessage2Room sends a message through the deduplicator. It returns
+// a nil message and nil error when the message was suppressed as a repeat.
+func (app *Application) SendDedupedMessage2Room(dedup *MessageDeduplicator, params *SendMessageParams) (*messages.Message, error) {
+	if err := app.FlushDedupSummaries(dedup); err != nil {
+		return nil, err
+	}
+	send, err := dedup.Check(params, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if !send {
+		return nil, nil
+	}
+	sentMessage, err := app.SendMessage2Room(params)
+	if err != nil {
+		if forgetErr := dedup.Forget(params); forgetErr != nil {
+			log.Errorf("Failed to reset dedup state: %s", forgetErr.Error())
+		}
+		return nil, err
+	}
+	return sentMessage, nil
+}
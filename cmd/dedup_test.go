@@ -0,0 +1,304 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	webex "github.com/WebexCommunity/webex-go-sdk/v2"
+	"github.com/WebexCommunity/webex-go-sdk/v2/webexsdk"
+	"github.com/tejzpr/webex-teams-cli/cmd/webexid"
+)
+
+func newTestDeduplicator(t *testing.T, window time.Duration) *MessageDeduplicator {
+	return &MessageDeduplicator{
+		StatePath: filepath.Join(t.TempDir(), dedupStateFile),
+		Window:    window,
+	}
+}
+
+func TestNormalizeMessage(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"Hello World", "hello world"},
+		{"  Hello   World \n", "hello world"},
+		{"BUILD\tFAILED", "build failed"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if got := normalizeMessage(tt.input); got != tt.expected {
+				t.Errorf("normalizeMessage(%q) = %q, want %q", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestDedupKey(t *testing.T) {
+	a := dedupKey(&SendMessageParams{RoomID: "room1", Text: "Build failed"})
+	b := dedupKey(&SendMessageParams{RoomID: "room1", Text: "  build   FAILED "})
+	if a != b {
+		t.Error("Expected normalized messages to share a key")
+	}
+
+	c := dedupKey(&SendMessageParams{RoomID: "room2", Text: "Build failed"})
+	if a == c {
+		t.Error("Expected different targets to have different keys")
+	}
+
+	d := dedupKey(&SendMessageParams{RoomID: "room1", Text: "Build failed", Filename: "log.txt"})
+	if a == d {
+		t.Error("Expected different attachments to have different keys")
+	}
+}
+
+func TestDeduplicatorCheck(t *testing.T) {
+	dedup := newTestDeduplicator(t, 10*time.Minute)
+	params := &SendMessageParams{RoomID: "room1", Text: "Build failed"}
+	now := time.Now()
+
+	send, err := dedup.Check(params, now)
+	if err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+	if !send {
+		t.Error("Expected first message to be sent")
+	}
+
+	for i := 1; i <= 3; i++ {
+		send, err = dedup.Check(params, now.Add(time.Duration(i)*time.Minute))
+		if err != nil {
+			t.Fatalf("Check() error = %v", err)
+		}
+		if send {
+			t.Errorf("Expected repeat %d to be suppressed", i)
+		}
+	}
+
+	// State is persisted, so a fresh deduplicator sees the same window
+	reloaded := &MessageDeduplicator{StatePath: dedup.StatePath, Window: dedup.Window}
+	send, err = reloaded.Check(params, now.Add(5*time.Minute))
+	if err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+	if send {
+		t.Error("Expected repeat to be suppressed across invocations")
+	}
+
+	// Once the window closes the message is sent again
+	send, err = reloaded.Check(params, now.Add(11*time.Minute))
+	if err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+	if !send {
+		t.Error("Expected message to be sent after the window closed")
+	}
+}
+
+func TestDeduplicatorExpired(t *testing.T) {
+	dedup := newTestDeduplicator(t, 10*time.Minute)
+	repeated := &SendMessageParams{RoomID: "room1", Text: "Build failed"}
+	single := &SendMessageParams{RoomID: "room2", Text: "Deploy done"}
+	now := time.Now()
+
+	dedup.Check(repeated, now)
+	dedup.Check(repeated, now.Add(time.Minute))
+	dedup.Check(repeated, now.Add(2*time.Minute))
+	dedup.Check(single, now)
+
+	expired, err := dedup.Expired(now.Add(5 * time.Minute))
+	if err != nil {
+		t.Fatalf("Expired() error = %v", err)
+	}
+	if len(expired) != 0 {
+		t.Errorf("Expected no expired entries while the window is open, got %d", len(expired))
+	}
+
+	expired, err = dedup.Expired(now.Add(11 * time.Minute))
+	if err != nil {
+		t.Fatalf("Expired() error = %v", err)
+	}
+	if len(expired) != 1 {
+		t.Fatalf("Expected 1 entry with repeats, got %d", len(expired))
+	}
+	if expired[0].RoomID != "room1" || expired[0].Repeats != 2 {
+		t.Errorf("Unexpected expired entry %+v", expired[0])
+	}
+	if summary := dedup.summaryText(expired[0]); !strings.Contains(summary, "repeated 2 times") {
+		t.Errorf("Expected summary to mention the repeat count, got %q", summary)
+	}
+
+	// Entries with repeats stay until their summary is sent, the others are
+	// removed
+	expired, err = dedup.Expired(now.Add(12 * time.Minute))
+	if err != nil {
+		t.Fatalf("Expired() error = %v", err)
+	}
+	if len(expired) != 1 {
+		t.Fatalf("Expected the unsummarized entry to be returned again, got %d", len(expired))
+	}
+	if err := dedup.Summarized(expired[0]); err != nil {
+		t.Fatalf("Summarized() error = %v", err)
+	}
+	entries, err := dedup.load()
+	if err != nil {
+		t.Fatalf("load() error = %v", err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("Expected expired entries to be removed, got %+v", entries)
+	}
+}
+
+func TestDeduplicatorCheckKeepsClosedWindow(t *testing.T) {
+	dedup := newTestDeduplicator(t, 10*time.Minute)
+	params := &SendMessageParams{RoomID: "room1", Text: "Build failed"}
+	now := time.Now()
+
+	dedup.Check(params, now)
+	dedup.Check(params, now.Add(time.Minute))
+	// The same message after the window opens a new one, and the closed
+	// window's summary is still pending
+	if send, _ := dedup.Check(params, now.Add(11*time.Minute)); !send {
+		t.Error("Expected the message to be sent in a new window")
+	}
+	expired, err := dedup.Expired(now.Add(12 * time.Minute))
+	if err != nil {
+		t.Fatalf("Expired() error = %v", err)
+	}
+	if len(expired) != 1 || expired[0].Repeats != 1 || !expired[0].WindowStart.Equal(now) {
+		t.Errorf("Expected the closed window to be kept for its summary, got %+v", expired)
+	}
+}
+
+func TestFlushDedupSummaries(t *testing.T) {
+	var attempts int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method != http.MethodPost {
+			json.NewEncoder(w).Encode(map[string]interface{}{"id": "room1"})
+			return
+		}
+		attempts++
+		if attempts == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"id": "summary1"})
+	}))
+	defer server.Close()
+	client, err := webex.NewClient("test-token", &webexsdk.Config{BaseURL: server.URL, MaxRetries: 0})
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	app := &Application{Client: client}
+
+	// A window of 1ns is closed by the time the summaries are flushed
+	dedup := newTestDeduplicator(t, time.Nanosecond)
+	roomID, _ := webexid.Encode(webexid.Room, "0b5c6d2e-0000-4000-8000-00000000000a")
+	params := &SendMessageParams{RoomID: roomID, Text: "Build failed"}
+	now := time.Now()
+	dedup.Check(params, now)
+	dedup.Check(params, now)
+
+	if err := app.FlushDedupSummaries(dedup); err != nil {
+		t.Fatalf("FlushDedupSummaries() error = %v", err)
+	}
+	entries, _ := dedup.load()
+	if len(entries) != 1 {
+		t.Fatalf("Expected the summary that failed to send to be kept, got %+v", entries)
+	}
+
+	if err := app.FlushDedupSummaries(dedup); err != nil {
+		t.Fatalf("FlushDedupSummaries() error = %v", err)
+	}
+	entries, _ = dedup.load()
+	if len(entries) != 0 || attempts != 2 {
+		t.Errorf("Expected the summary to be sent and removed, got %d attempts, %+v", attempts, entries)
+	}
+}
+
+func TestDeduplicatorForget(t *testing.T) {
+	dedup := newTestDeduplicator(t, 10*time.Minute)
+	params := &SendMessageParams{RoomID: "room1", Text: "Build failed"}
+	now := time.Now()
+
+	dedup.Check(params, now)
+	if err := dedup.Forget(params); err != nil {
+		t.Fatalf("Forget() error = %v", err)
+	}
+
+	send, err := dedup.Check(params, now.Add(time.Minute))
+	if err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+	if !send {
+		t.Error("Expected message to be sent after its entry was forgotten")
+	}
+}
+
+func TestDeduplicatorSharedState(t *testing.T) {
+	// Deduplicators sharing a state path stand in for separate processes,
+	// which only the lock file serializes
+	statePath := filepath.Join(t.TempDir(), dedupStateFile)
+	params := &SendMessageParams{RoomID: "room1", Text: "Build failed"}
+	now := time.Now()
+
+	var wg sync.WaitGroup
+	var sent atomic.Int32
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			dedup := &MessageDeduplicator{StatePath: statePath, Window: 10 * time.Minute}
+			send, err := dedup.Check(params, now)
+			if err != nil {
+				t.Errorf("Check() error = %v", err)
+			}
+			if send {
+				sent.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+	if sent.Load() != 1 {
+		t.Errorf("Expected the message to be sent once, got %d", sent.Load())
+	}
+
+	dedup := &MessageDeduplicator{StatePath: statePath, Window: 10 * time.Minute}
+	expired, err := dedup.Expired(now.Add(11 * time.Minute))
+	if err != nil {
+		t.Fatalf("Expired() error = %v", err)
+	}
+	if len(expired) != 1 || expired[0].Repeats != 7 {
+		t.Errorf("Expected 7 repeats, got %+v", expired)
+	}
+}
+
+func TestLockStateFileStale(t *testing.T) {
+	statePath := filepath.Join(t.TempDir(), dedupStateFile)
+	if err := os.WriteFile(statePath+".lock", nil, 0600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	old := time.Now().Add(-2 * stateLockStale)
+	if err := os.Chtimes(statePath+".lock", old, old); err != nil {
+		t.Fatalf("Chtimes() error = %v", err)
+	}
+	unlock, err := lockStateFile(statePath)
+	if err != nil {
+		t.Fatalf("lockStateFile() error = %v", err)
+	}
+	unlock()
+	if _, err := os.Stat(statePath + ".lock"); !os.IsNotExist(err) {
+		t.Errorf("Expected the lock file to be removed, got %v", err)
+	}
+}
@@ -2,19 +2,34 @@ package cmd
 
 import (
 	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
 	"os"
+	"strconv"
+	"time"
 
 	"github.com/WebexCommunity/webex-go-sdk/v2/memberships"
 	log "github.com/sirupsen/logrus"
 	"github.com/urfave/cli/v2"
 )
 
+// Export formats
+const (
+	exportFormatCSV  = "csv"
+	exportFormatJSON = "json"
+	exportFormatXLSX = "xlsx"
+)
+
+// utf8BOM makes spreadsheet applications read the CSV as UTF-8
+const utf8BOM = "\ufeff"
+
 // ExportPeopleCMD function
 func (app *Application) ExportPeopleCMD() *cli.Command {
 	return &cli.Command{
 		Name:        "exportmembers",
 		Aliases:     []string{"em"},
-		Description: "Export members of a room to a CSV file",
+		Description: "Export members of a room(s) to a file. CSV exports can be imported by addmembers",
 		Flags: []cli.Flag{
 			&cli.StringFlag{
 				Name:     "memberscsv",
@@ -23,24 +38,54 @@ func (app *Application) ExportPeopleCMD() *cli.Command {
 				Usage:    "Path to CSV to export to",
 				Required: true,
 			},
+			&cli.StringFlag{
+				Name:     "format",
+				Aliases:  []string{"fmt"},
+				Value:    exportFormatCSV,
+				Usage:    "Output format, one of csv, json or xlsx (CSV that opens cleanly in spreadsheet applications). Default is csv",
+				Required: false,
+			},
+			&cli.StringFlag{
+				Name:     "access",
+				Aliases:  []string{"a"},
+				Value:    "a",
+				Usage:    "Members will be exported from rooms for which you have specified permissions of either 'a' (include all), 'o' (owner), 'm' (moderator) or 'om' (owner and moderator). Default is all.",
+				Required: false,
+			},
+			&cli.StringFlag{
+				Name:     "roomsidscsv",
+				Aliases:  []string{"rcsv"},
+				Value:    "",
+				Usage:    "Path to a CSV containing a list of RoomID's whose members will be exported.",
+				Required: false,
+			},
+			&cli.StringFlag{
+				Name:     "team",
+				Aliases:  []string{"tm"},
+				Value:    "",
+				Usage:    "ID or name of a team whose rooms' members will be exported",
+				Required: false,
+			},
+			selectFlag(),
+			limitFlag(),
 		},
 		Action: func(c *cli.Context) error {
-			roomID := c.String("roomID")
-
-			parsedRoomID, err := app.parseRoomID(roomID)
+			roomIDs, err := app.targetRoomIDs(c)
 			if err != nil {
 				return err
 			}
-			csvPath := c.String("memberscsv")
-			if csvPath != "" {
-				roomUtilsApp := &ExportPeopleApplication{Application: app, MemberCSVPath: csvPath}
-				err := roomUtilsApp.Export(parsedRoomID)
-				if err != nil {
-					return err
-				}
+
+			access := c.String("access")
+			if access != "a" && access != "o" && access != "m" && access != "om" {
+				return errors.New("Allowed valued for access flag are a, o, m and om")
+			}
+			format := c.String("format")
+			if format != exportFormatCSV && format != exportFormatJSON && format != exportFormatXLSX {
+				return errors.New("Allowed values for format flag are csv, json and xlsx")
 			}
 
-			return nil
+			roomUtilsApp := &ExportPeopleApplication{Application: app, MemberCSVPath: c.String("memberscsv"), Format: format, Access: access, Limit: c.Int("limit")}
+			return roomUtilsApp.Export(roomIDs)
 		},
 	}
 }
@@ -49,46 +94,116 @@ func (app *Application) ExportPeopleCMD() *cli.Command {
 type ExportPeopleApplication struct {
 	*Application
 	MemberCSVPath string
+	Format        string
+	Access        string
+	// Limit bounds the rooms read when no rooms are given, 0 reads all
+	Limit int
 }
 
-// Export function
-func (app *ExportPeopleApplication) Export(roomID string) error {
+// exportedMember is a row of exportmembers. The email and moderator columns
+// keep the names addmembers reads.
+type exportedMember struct {
+	RoomID      string     `json:"roomId"`
+	RoomTitle   string     `json:"roomTitle"`
+	Email       string     `json:"email"`
+	Moderator   bool       `json:"moderator"`
+	DisplayName string     `json:"displayName"`
+	PersonID    string     `json:"personId"`
+	PersonOrgID string     `json:"personOrgId"`
+	Created     *time.Time `json:"created,omitempty"`
+	IsMonitor   bool       `json:"isMonitor"`
+	External    bool       `json:"external"`
+}
 
-	room, err := app.Client.Rooms().Get(roomID)
-	if err != nil {
-		return err
-	}
+var exportedMemberHeader = []string{"roomId", "roomTitle", "email", "moderator", "displayName", "personId", "personOrgId", "created", "isMonitor", "external"}
 
-	log.Info(room.Title)
-	membershipQueryParams := &memberships.ListOptions{
-		RoomID: room.ID,
+func (member exportedMember) record() []string {
+	created := ""
+	if member.Created != nil {
+		created = member.Created.UTC().Format(time.RFC3339)
 	}
+	return []string{
+		member.RoomID,
+		member.RoomTitle,
+		member.Email,
+		strconv.FormatBool(member.Moderator),
+		member.DisplayName,
+		member.PersonID,
+		member.PersonOrgID,
+		created,
+		strconv.FormatBool(member.IsMonitor),
+		strconv.FormatBool(member.External),
+	}
+}
 
-	mbrPage, err := app.Client.Memberships().List(membershipQueryParams)
+// Export writes the members of the eligible rooms among roomIDs, or of all
+// eligible rooms when roomIDs is empty, to MemberCSVPath
+func (app *ExportPeopleApplication) Export(roomIDs []string) error {
+	access := app.Access
+	if access == "" {
+		access = "a"
+	}
+	targets, err := app.eligibleRooms(roomIDs, access, app.Limit)
 	if err != nil {
 		return err
 	}
-	log.Info(room.ID)
-	if len(mbrPage.Items) > 0 {
-		csvFile, err := os.Create(app.MemberCSVPath)
+
+	exported := make([]exportedMember, 0)
+	for _, target := range targets {
+		items, err := app.ListAllMemberships(target.Room.ID)
 		if err != nil {
-			log.Fatal(err)
+			return err
 		}
-		defer csvFile.Close()
-
-		csvWriter := csv.NewWriter(csvFile)
-		defer csvWriter.Flush()
-		csvWriter.Write([]string{"email", "moderator"})
-		// Save membership to CSV file
-		for _, membership := range mbrPage.Items {
-			moderator := "false"
-			if membership.IsModerator {
-				moderator = "true"
-			}
-			emailData := []string{membership.PersonEmail, moderator}
-			csvWriter.Write(emailData)
+		log.Infof("Exporting %d members of %s", len(items), target.Room.Title)
+		for _, membership := range items {
+			exported = append(exported, app.exportedMember(target.Room.Title, membership))
 		}
 	}
 
-	return nil
+	outFile, err := os.Create(app.MemberCSVPath)
+	if err != nil {
+		return err
+	}
+	if err := writeExportedMembers(outFile, app.Format, exported); err != nil {
+		outFile.Close()
+		return err
+	}
+	return outFile.Close()
+}
+
+func (app *ExportPeopleApplication) exportedMember(roomTitle string, membership memberships.Membership) exportedMember {
+	return exportedMember{
+		RoomID:      membership.RoomID,
+		RoomTitle:   roomTitle,
+		Email:       membership.PersonEmail,
+		Moderator:   membership.IsModerator,
+		DisplayName: membership.PersonDisplayName,
+		PersonID:    membership.PersonID,
+		PersonOrgID: membership.PersonOrgID,
+		Created:     membership.Created,
+		IsMonitor:   membership.IsMonitor,
+		External:    app.isExternal(membership.PersonOrgID),
+	}
+}
+
+func writeExportedMembers(w io.Writer, format string, exported []exportedMember) error {
+	if format == exportFormatJSON {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(exported)
+	}
+
+	if format == exportFormatXLSX {
+		if _, err := io.WriteString(w, utf8BOM); err != nil {
+			return err
+		}
+	}
+	csvWriter := csv.NewWriter(w)
+	csvWriter.UseCRLF = format == exportFormatXLSX
+	csvWriter.Write(exportedMemberHeader)
+	for _, member := range exported {
+		csvWriter.Write(member.record())
+	}
+	csvWriter.Flush()
+	return csvWriter.Error()
 }
@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testExportedMembers() []exportedMember {
+	created := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	return []exportedMember{
+		{RoomID: "room-1", RoomTitle: "Project X", Email: "a@example.com", Moderator: true, DisplayName: "A, Person", Created: &created},
+		{RoomID: "room-1", RoomTitle: "Project X", Email: "b@example.com", External: true},
+	}
+}
+
+func TestExportedMembersImportable(t *testing.T) {
+	for _, format := range []string{exportFormatCSV, exportFormatXLSX} {
+		var buf bytes.Buffer
+		if err := writeExportedMembers(&buf, format, testExportedMembers()); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		var members []userCSV
+		for v := range ParseUsersCSV(&buf) {
+			if v.Err != nil {
+				t.Fatalf("%s export is not importable: %v", format, v.Err)
+			}
+			members = append(members, v.Value)
+		}
+		if len(members) != 2 {
+			t.Fatalf("Expected 2 members from %s export, got %d", format, len(members))
+		}
+		if members[0].Email != "a@example.com" || !members[0].IsModerator {
+			t.Errorf("Unexpected first member from %s export: %+v", format, members[0])
+		}
+		if members[1].Email != "b@example.com" || members[1].IsModerator {
+			t.Errorf("Unexpected second member from %s export: %+v", format, members[1])
+		}
+	}
+}
+
+func TestExportedMembersXLSX(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeExportedMembers(&buf, exportFormatXLSX, testExportedMembers()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	out := buf.String()
+	if !strings.HasPrefix(out, utf8BOM) {
+		t.Error("Expected the xlsx export to start with a UTF-8 BOM")
+	}
+	if !strings.Contains(out, "\r\n") {
+		t.Error("Expected the xlsx export to use CRLF line endings")
+	}
+	if !strings.Contains(out, "2024-05-01T10:00:00Z") {
+		t.Error("Expected the created date in RFC3339")
+	}
+}
+
+func TestExportedMembersJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeExportedMembers(&buf, exportFormatJSON, testExportedMembers()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var decoded []exportedMember
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("Invalid JSON export: %v", err)
+	}
+	if len(decoded) != 2 || decoded[1].External != true || decoded[0].DisplayName != "A, Person" {
+		t.Errorf("Unexpected JSON export %+v", decoded)
+	}
+}
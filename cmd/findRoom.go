@@ -28,9 +28,10 @@ func (app *Application) FindRoomCMD() *cli.Command {
 				Usage:    "Filter rooms by room type - group / direct. Defaults to all.",
 				Required: false,
 			},
+			limitFlag(),
 		},
 		Action: func(c *cli.Context) error {
-			rooms, err := app.GetRooms(1000, c.String("roomType"))
+			rooms, err := app.GetRooms(c.Int("limit"), c.String("roomType"))
 			if err != nil {
 				return err
 			}
@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/tejzpr/webex-teams-cli/cmd/webexid"
+	"github.com/urfave/cli/v2"
+)
+
+// IDCMD function
+func (app *Application) IDCMD() *cli.Command {
+	return &cli.Command{
+		Name:  "id",
+		Usage: "Decode and encode Webex IDs",
+
+		Subcommands: []*cli.Command{
+			app.decodeIDCMD(),
+			app.encodeIDCMD(),
+		},
+		Action: func(c *cli.Context) error {
+			return nil
+		},
+	}
+}
+
+func (app *Application) decodeIDCMD() *cli.Command {
+	return &cli.Command{
+		Name:        "decode",
+		Aliases:     []string{"d"},
+		Usage:       "decode <id>",
+		Description: "Print the cluster, resource type and UUID of a Webex ID, a space URL or a webexteams:// link",
+		Action: func(c *cli.Context) error {
+			if c.Args().Len() != 1 {
+				return errors.New("an ID is required")
+			}
+			id, err := decodeIDRef(c.Args().First())
+			if err != nil {
+				return err
+			}
+			m, err := json.Marshal(id)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("%s", string(m))
+			return nil
+		},
+	}
+}
+
+func (app *Application) encodeIDCMD() *cli.Command {
+	return &cli.Command{
+		Name:        "encode",
+		Aliases:     []string{"e"},
+		Usage:       "encode --type room <uuid>",
+		Description: "Print the Webex ID of a resource known by its UUID",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:     "type",
+				Aliases:  []string{"t"},
+				Value:    string(webexid.Room),
+				Usage:    "Resource type, one of room, people, message, membership, team, team_membership, organization, webhook or attachment_action. Default is room",
+				Required: false,
+			},
+		},
+		Action: func(c *cli.Context) error {
+			if c.Args().Len() != 1 {
+				return errors.New("a UUID is required")
+			}
+			resourceType, err := webexid.ParseResourceType(c.String("type"))
+			if err != nil {
+				return err
+			}
+			id, err := webexid.Encode(resourceType, c.Args().First())
+			if err != nil {
+				return err
+			}
+			fmt.Println(id)
+			return nil
+		},
+	}
+}
+
+// decodeIDRef decodes a Webex ID. Space links are read as room IDs.
+func decodeIDRef(ref string) (webexid.ID, error) {
+	id, err := webexid.Decode(ref)
+	if err == nil {
+		return id, nil
+	}
+	roomID, roomErr := webexid.Parse(ref, webexid.Room)
+	if roomErr != nil {
+		return webexid.ID{}, err
+	}
+	return webexid.Decode(roomID)
+}
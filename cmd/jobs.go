@@ -0,0 +1,421 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/urfave/cli/v2"
+)
+
+const jobsDir = "jobs"
+
+// Kinds of journal records. A journal has a start record for every run of
+// the job, a done record for every completed item and a finish record when
+// a run ends.
+const (
+	journalStart  = "start"
+	journalDone   = "done"
+	journalFinish = "finish"
+)
+
+// Actions recorded in journals
+const (
+	jobActionAdd     = "add"
+	jobActionRemove  = "remove"
+	jobActionMessage = "message"
+)
+
+// Statuses of jobs
+const (
+	jobFinished    = "finished"
+	jobFailed      = "failed"
+	jobInterrupted = "interrupted"
+)
+
+// jobFlagsOmitted are flags not worth recording in a journal
+var jobFlagsOmitted = map[string]bool{"resume": true, "confirm": true}
+
+var jobHeader = []string{"id", "command", "started", "updated", "runs", "done", "status"}
+
+var jobItemHeader = []string{"roomId", "person", "action", "at"}
+
+// journalRecord is a line of a job journal
+type journalRecord struct {
+	Kind    string            `json:"kind"`
+	JobID   string            `json:"jobId,omitempty"`
+	Command string            `json:"command,omitempty"`
+	Flags   map[string]string `json:"flags,omitempty"`
+	RoomID  string            `json:"roomId,omitempty"`
+	Person  string            `json:"person,omitempty"`
+	Action  string            `json:"action,omitempty"`
+	Error   string            `json:"error,omitempty"`
+	At      time.Time         `json:"at"`
+}
+
+// jobSummary describes a job from its journal
+type jobSummary struct {
+	ID      string            `json:"id"`
+	Command string            `json:"command"`
+	Flags   map[string]string `json:"flags,omitempty"`
+	Path    string            `json:"path"`
+	Started time.Time         `json:"started"`
+	Updated time.Time         `json:"updated"`
+	Runs    int               `json:"runs"`
+	Done    int               `json:"done"`
+	Status  string            `json:"status"`
+	Error   string            `json:"error,omitempty"`
+	Items   []journalRecord   `json:"items,omitempty"`
+}
+
+// jobJournal records the completed items of a bulk command so an
+// interrupted run can be resumed. A nil journal records nothing.
+type jobJournal struct {
+	ID   string
+	Path string
+
+	mu   sync.Mutex
+	file *os.File
+	done map[string]bool
+}
+
+// JobsCMD function
+func (app *Application) JobsCMD() *cli.Command {
+	return &cli.Command{
+		Name:    "jobs",
+		Aliases: []string{"jb"},
+		Usage:   "Inspect the journals of bulk commands",
+		Subcommands: []*cli.Command{
+			app.jobsListCMD(),
+			app.jobsShowCMD(),
+		},
+		Action: func(c *cli.Context) error {
+			return nil
+		},
+	}
+}
+
+func (app *Application) jobsListCMD() *cli.Command {
+	return &cli.Command{
+		Name:        "list",
+		Aliases:     []string{"ls"},
+		Description: "List the jobs journaled in the state directory, most recent first",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:     "output",
+				Aliases:  []string{"o"},
+				Value:    outputTable,
+				Usage:    "Output format, one of table, csv or json. Default is table",
+				Required: false,
+			},
+		},
+		Action: func(c *cli.Context) error {
+			format := c.String("output")
+			if err := validateOutputFormat(format); err != nil {
+				return err
+			}
+			jobs, err := app.listJobs()
+			if err != nil {
+				return err
+			}
+			rows := make([][]string, 0, len(jobs))
+			for _, job := range jobs {
+				rows = append(rows, job.record())
+			}
+			return writeOutput(os.Stdout, format, jobHeader, rows, jobs)
+		},
+	}
+}
+
+func (app *Application) jobsShowCMD() *cli.Command {
+	return &cli.Command{
+		Name:        "show",
+		Usage:       "show <jobID|journal>",
+		Description: "Show a job and the items it completed",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:     "output",
+				Aliases:  []string{"o"},
+				Value:    outputTable,
+				Usage:    "Output format, one of table, csv or json. Default is table",
+				Required: false,
+			},
+		},
+		Action: func(c *cli.Context) error {
+			format := c.String("output")
+			if err := validateOutputFormat(format); err != nil {
+				return err
+			}
+			if c.Args().Len() != 1 {
+				return errors.New("a job ID or journal path is required")
+			}
+			journalPath, err := app.journalPath(c.Args().First())
+			if err != nil {
+				return err
+			}
+			job, err := loadJobSummary(journalPath)
+			if err != nil {
+				return err
+			}
+			if format == outputJSON {
+				return writeOutput(os.Stdout, format, nil, nil, job)
+			}
+			if format == outputTable {
+				fmt.Fprintf(os.Stdout, "Job %s: %s, %s\n", job.ID, job.Command, job.Status)
+				for _, name := range sortedKeys(job.Flags) {
+					fmt.Fprintf(os.Stdout, "  --%s %s\n", name, job.Flags[name])
+				}
+				if job.Error != "" {
+					fmt.Fprintf(os.Stdout, "  error: %s\n", job.Error)
+				}
+			}
+			rows := make([][]string, 0, len(job.Items))
+			for _, item := range job.Items {
+				rows = append(rows, []string{item.RoomID, item.Person, item.Action, item.At.Format(time.RFC3339)})
+			}
+			return writeOutput(os.Stdout, format, jobItemHeader, rows, job.Items)
+		},
+	}
+}
+
+// resumeFlag is the --resume flag of journaled bulk commands
+func resumeFlag() *cli.StringFlag {
+	return &cli.StringFlag{
+		Name:     "resume",
+		Aliases:  []string{"rs"},
+		Value:    "",
+		Usage:    "Job ID or journal path of an interrupted run. Work it completed is skipped",
+		Required: false,
+	}
+}
+
+// openJobJournal starts the journal of a bulk command, or continues the
+// journal to resume when it is set
+func (app *Application) openJobJournal(c *cli.Context, command string, resume string) (*jobJournal, error) {
+	journal := &jobJournal{done: make(map[string]bool)}
+	if resume != "" {
+		journalPath, err := app.journalPath(resume)
+		if err != nil {
+			return nil, err
+		}
+		job, err := loadJobSummary(journalPath)
+		if err != nil {
+			return nil, err
+		}
+		if job.Command != command {
+			return nil, fmt.Errorf("Job %s is a %s job, not %s", job.ID, job.Command, command)
+		}
+		for _, item := range job.Items {
+			journal.done[journalKey(item.RoomID, item.Person, item.Action)] = true
+		}
+		journal.ID, journal.Path = job.ID, journalPath
+		log.Infof("Resuming job %s, skipping %d completed items", job.ID, job.Done)
+	} else {
+		id, err := newStateID()
+		if err != nil {
+			return nil, err
+		}
+		dir, err := app.statePath(jobsDir)
+		if err != nil {
+			return nil, err
+		}
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			return nil, err
+		}
+		journal.ID, journal.Path = id, filepath.Join(dir, id+".jsonl")
+		log.Infof("Journaling job %s, resume an interrupted run with --resume %s", id, id)
+	}
+
+	file, err := os.OpenFile(journal.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, err
+	}
+	journal.file = file
+	flags := make(map[string]string)
+	if c.Command != nil {
+		for _, flag := range c.Command.Flags {
+			name := flag.Names()[0]
+			if c.IsSet(name) && !jobFlagsOmitted[name] {
+				flags[name] = c.String(name)
+			}
+		}
+	}
+	if err := journal.write(journalRecord{Kind: journalStart, JobID: journal.ID, Command: command, Flags: flags}); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return journal, nil
+}
+
+// completed reports whether a previous run completed the item
+func (journal *jobJournal) completed(roomID, person, action string) bool {
+	if journal == nil {
+		return false
+	}
+	journal.mu.Lock()
+	defer journal.mu.Unlock()
+	return journal.done[journalKey(roomID, person, action)]
+}
+
+// record adds a completed item to the journal
+func (journal *jobJournal) record(roomID, person, action string) {
+	if journal == nil {
+		return
+	}
+	journal.mu.Lock()
+	journal.done[journalKey(roomID, person, action)] = true
+	journal.mu.Unlock()
+	err := journal.write(journalRecord{Kind: journalDone, RoomID: roomID, Person: normalizeEmail(person), Action: action})
+	if err != nil {
+		log.Errorf("Failed to journal %s %s in %s: %s", action, person, roomID, err.Error())
+	}
+}
+
+// finish records the end of the run and closes the journal
+func (journal *jobJournal) finish(runErr error) error {
+	if journal == nil {
+		return nil
+	}
+	record := journalRecord{Kind: journalFinish}
+	if runErr != nil {
+		record.Error = runErr.Error()
+	}
+	err := journal.write(record)
+	if closeErr := journal.file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+func (journal *jobJournal) write(record journalRecord) error {
+	record.At = time.Now()
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	journal.mu.Lock()
+	defer journal.mu.Unlock()
+	_, err = journal.file.Write(append(line, '\n'))
+	return err
+}
+
+func journalKey(roomID, person, action string) string {
+	return roomID + "\x00" + normalizeEmail(person) + "\x00" + action
+}
+
+// journalPath resolves a job ID to its journal in the state directory. An
+// existing file path is used as is.
+func (app *Application) journalPath(ref string) (string, error) {
+	if info, err := os.Stat(ref); err == nil && !info.IsDir() {
+		return ref, nil
+	}
+	dir, err := app.statePath(jobsDir)
+	if err != nil {
+		return "", err
+	}
+	journalPath := filepath.Join(dir, filepath.Base(ref)+".jsonl")
+	if _, err := os.Stat(journalPath); err != nil {
+		return "", fmt.Errorf("No job %s", ref)
+	}
+	return journalPath, nil
+}
+
+// listJobs summarizes every journal in the state directory
+func (app *Application) listJobs() ([]*jobSummary, error) {
+	dir, err := app.statePath(jobsDir)
+	if err != nil {
+		return nil, err
+	}
+	paths, err := filepath.Glob(filepath.Join(dir, "*.jsonl"))
+	if err != nil {
+		return nil, err
+	}
+	jobs := make([]*jobSummary, 0, len(paths))
+	for _, journalPath := range paths {
+		job, err := loadJobSummary(journalPath)
+		if err != nil {
+			log.Warnf("Skipping %s: %s", journalPath, err.Error())
+			continue
+		}
+		job.Items = nil
+		jobs = append(jobs, job)
+	}
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].Started.After(jobs[j].Started)
+	})
+	return jobs, nil
+}
+
+func loadJobSummary(journalPath string) (*jobSummary, error) {
+	file, err := os.Open(journalPath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	job, err := readJournal(file)
+	if err != nil {
+		return nil, err
+	}
+	job.Path = journalPath
+	return job, nil
+}
+
+// readJournal summarizes the records of a journal. A line cut short by a
+// crash is ignored.
+func readJournal(r io.Reader) (*jobSummary, error) {
+	job := &jobSummary{Items: make([]journalRecord, 0)}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var record journalRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			continue
+		}
+		switch record.Kind {
+		case journalStart:
+			if job.Runs == 0 {
+				job.ID, job.Command, job.Flags, job.Started = record.JobID, record.Command, record.Flags, record.At
+			}
+			job.Runs++
+			job.Status, job.Error = jobInterrupted, ""
+		case journalDone:
+			job.Items = append(job.Items, record)
+		case journalFinish:
+			job.Status, job.Error = jobFinished, record.Error
+			if record.Error != "" {
+				job.Status = jobFailed
+			}
+		}
+		job.Updated = record.At
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if job.Runs == 0 {
+		return nil, errors.New("not a job journal")
+	}
+	job.Done = len(job.Items)
+	return job, nil
+}
+
+func (job *jobSummary) record() []string {
+	return []string{job.ID, job.Command, job.Started.Format(time.RFC3339), job.Updated.Format(time.RFC3339), strconv.Itoa(job.Runs), strconv.Itoa(job.Done), job.Status}
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
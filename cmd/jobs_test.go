@@ -0,0 +1,173 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/urfave/cli/v2"
+)
+
+// runJournaled runs a command with the given arguments that opens a journal
+// and hands it to run
+func runJournaled(t *testing.T, app *Application, command string, args []string, run func(journal *jobJournal) error) {
+	cliApp := &cli.App{
+		Commands: []*cli.Command{
+			{
+				Name: command,
+				Flags: []cli.Flag{
+					&cli.StringFlag{Name: "memberscsv", Aliases: []string{"csv"}},
+					&cli.StringFlag{Name: "confirm", Aliases: []string{"c"}},
+					limitFlag(),
+					resumeFlag(),
+				},
+				Action: func(c *cli.Context) error {
+					journal, err := app.openJobJournal(c, command, c.String("resume"))
+					if err != nil {
+						return err
+					}
+					return journal.finish(run(journal))
+				},
+			},
+		},
+	}
+	if err := cliApp.Run(append([]string{"webex-teams-cli", command}, args...)); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+}
+
+func TestJobJournalResume(t *testing.T) {
+	app := &Application{StateDir: t.TempDir()}
+
+	var jobID string
+	runJournaled(t, app, "addmembers", []string{"--csv", "people.csv", "--limit", "5", "-c", "y"}, func(journal *jobJournal) error {
+		jobID = journal.ID
+		journal.record("r1", "A@Example.com", jobActionAdd)
+		journal.record("r1", "b@example.com", jobActionAdd)
+		return errors.New("rate limited")
+	})
+
+	var skipped []string
+	runJournaled(t, app, "addmembers", []string{"--resume", jobID}, func(journal *jobJournal) error {
+		if journal.ID != jobID {
+			t.Errorf("Expected the resumed run to continue job %s, got %s", jobID, journal.ID)
+		}
+		for _, person := range []string{"a@example.com", "b@example.com", "c@example.com"} {
+			if journal.completed("r1", person, jobActionAdd) {
+				skipped = append(skipped, person)
+				continue
+			}
+			journal.record("r1", person, jobActionAdd)
+		}
+		if journal.completed("r2", "a@example.com", jobActionAdd) || journal.completed("r1", "a@example.com", jobActionRemove) {
+			t.Error("Expected completion to be tracked per room and action")
+		}
+		return nil
+	})
+	if strings.Join(skipped, ",") != "a@example.com,b@example.com" {
+		t.Errorf("Expected the completed members to be skipped, got %v", skipped)
+	}
+
+	jobs, err := app.listJobs()
+	if err != nil {
+		t.Fatalf("listJobs() error = %v", err)
+	}
+	if len(jobs) != 1 {
+		t.Fatalf("Expected one job, got %d", len(jobs))
+	}
+	job := jobs[0]
+	if job.ID != jobID || job.Command != "addmembers" || job.Runs != 2 || job.Done != 3 || job.Status != jobFinished {
+		t.Errorf("Unexpected job %+v", job)
+	}
+	if job.Flags["memberscsv"] != "people.csv" || job.Flags["limit"] != "5" || len(job.Flags) != 2 {
+		t.Errorf("Expected the flags of the first run without confirm, got %v", job.Flags)
+	}
+}
+
+func TestJobJournalResumeOtherCommand(t *testing.T) {
+	app := &Application{StateDir: t.TempDir()}
+	var jobID string
+	runJournaled(t, app, "broadcast", nil, func(journal *jobJournal) error {
+		jobID = journal.ID
+		return nil
+	})
+
+	cliApp := &cli.App{
+		Commands: []*cli.Command{
+			{
+				Name:  "addmembers",
+				Flags: []cli.Flag{resumeFlag()},
+				Action: func(c *cli.Context) error {
+					_, err := app.openJobJournal(c, "addmembers", c.String("resume"))
+					return err
+				},
+			},
+		},
+	}
+	if err := cliApp.Run([]string{"webex-teams-cli", "addmembers", "--resume", jobID}); err == nil {
+		t.Error("Expected an error resuming a broadcast job with addmembers")
+	}
+	if err := cliApp.Run([]string{"webex-teams-cli", "addmembers", "--resume", "missing"}); err == nil {
+		t.Error("Expected an error resuming an unknown job")
+	}
+}
+
+func TestReadJournal(t *testing.T) {
+	journal := strings.Join([]string{
+		`{"kind":"start","jobId":"abc","command":"removemembers","at":"2024-06-01T10:00:00Z"}`,
+		`{"kind":"done","roomId":"r1","person":"a@example.com","action":"remove","at":"2024-06-01T10:01:00Z"}`,
+		`{"kind":"finish","error":"boom","at":"2024-06-01T10:02:00Z"}`,
+		`{"kind":"start","jobId":"abc","command":"removemembers","at":"2024-06-02T10:00:00Z"}`,
+		`{"kind":"done","roomId":"r1","person":"b@example.com","action":"remove","at":"2024-06-02T10:01:00Z"}`,
+		`{"kind":"done","roomId":"r1","per`,
+	}, "\n")
+	job, err := readJournal(strings.NewReader(journal))
+	if err != nil {
+		t.Fatalf("readJournal() error = %v", err)
+	}
+	if job.ID != "abc" || job.Runs != 2 || job.Done != 2 || job.Status != jobInterrupted || job.Error != "" {
+		t.Errorf("Unexpected job %+v", job)
+	}
+	if job.Started.Day() != 1 || job.Updated.Day() != 2 {
+		t.Errorf("Unexpected job times %s and %s", job.Started, job.Updated)
+	}
+
+	job, err = readJournal(strings.NewReader(strings.Join(strings.Split(journal, "\n")[:3], "\n")))
+	if err != nil {
+		t.Fatalf("readJournal() error = %v", err)
+	}
+	if job.Status != jobFailed || job.Error != "boom" {
+		t.Errorf("Expected a failed job, got %+v", job)
+	}
+
+	if _, err := readJournal(strings.NewReader("not a journal\n")); err == nil {
+		t.Error("Expected an error for a file without a start record")
+	}
+}
+
+func TestJournalPath(t *testing.T) {
+	app := &Application{StateDir: t.TempDir()}
+	var jobID string
+	runJournaled(t, app, "broadcast", nil, func(journal *jobJournal) error {
+		jobID = journal.ID
+		return nil
+	})
+
+	journalPath, err := app.journalPath(jobID)
+	if err != nil {
+		t.Fatalf("journalPath() error = %v", err)
+	}
+	if _, err := os.Stat(journalPath); err != nil {
+		t.Errorf("Expected the journal at %s: %v", journalPath, err)
+	}
+	if got, err := app.journalPath(journalPath); err != nil || got != journalPath {
+		t.Errorf("Expected a journal path to be used as is, got %s, %v", got, err)
+	}
+
+	var nilJournal *jobJournal
+	nilJournal.record("r1", "a@example.com", jobActionAdd)
+	if nilJournal.completed("r1", "a@example.com", jobActionAdd) || nilJournal.finish(nil) != nil {
+		t.Error("Expected a nil journal to record nothing")
+	}
+}
@@ -0,0 +1,183 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"time"
+
+	"github.com/WebexCommunity/webex-go-sdk/v2/rooms"
+	log "github.com/sirupsen/logrus"
+	"github.com/urfave/cli/v2"
+)
+
+// roomSelection narrows the group rooms the user belongs to. Set fields are
+// combined, a room must match all of them.
+type roomSelection struct {
+	RoomIDs  []string
+	Title    *regexp.Regexp
+	Inactive time.Duration
+	Now      time.Time
+}
+
+// empty reports whether the selection would match every room
+func (selection roomSelection) empty() bool {
+	return len(selection.RoomIDs) == 0 && selection.Title == nil && selection.Inactive == 0
+}
+
+// filter returns the rooms among allRooms that match the selection
+func (selection roomSelection) filter(allRooms []rooms.Room) []*rooms.Room {
+	var wanted map[string]bool
+	if len(selection.RoomIDs) > 0 {
+		wanted = make(map[string]bool, len(selection.RoomIDs))
+		for _, roomID := range selection.RoomIDs {
+			wanted[roomID] = true
+		}
+	}
+	cutoff := selection.Now.Add(-selection.Inactive)
+	matched := make([]*rooms.Room, 0)
+	for i := range allRooms {
+		room := &allRooms[i]
+		if wanted != nil && !wanted[room.ID] {
+			continue
+		}
+		if selection.Title != nil && !selection.Title.MatchString(room.Title) {
+			continue
+		}
+		if selection.Inactive > 0 && !isStale(room, cutoff) {
+			continue
+		}
+		matched = append(matched, room)
+	}
+	return matched
+}
+
+// LeaveRoomsCMD function
+func (app *Application) LeaveRoomsCMD() *cli.Command {
+	return &cli.Command{
+		Name:        "leave",
+		Aliases:     []string{"lv"},
+		Description: "Remove yourself from the group rooms selected by a rooms CSV, a team, a selector expression, a title regex and / or inactivity. Selectors are combined",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:     "roomsidscsv",
+				Aliases:  []string{"rcsv"},
+				Value:    "",
+				Usage:    "Path to a CSV containing a list of RoomID's to leave.",
+				Required: false,
+			},
+			&cli.StringFlag{
+				Name:     "team",
+				Aliases:  []string{"tm"},
+				Value:    "",
+				Usage:    "ID or name of a team whose rooms to leave",
+				Required: false,
+			},
+			selectFlag(),
+			&cli.StringFlag{
+				Name:     "title",
+				Aliases:  []string{"t"},
+				Value:    "",
+				Usage:    "Regular expression the room title must match, eg. '(?i)^test'",
+				Required: false,
+			},
+			&cli.StringFlag{
+				Name:     "inactive",
+				Aliases:  []string{"i"},
+				Value:    "",
+				Usage:    "Only rooms without activity for this long, eg. 180d, 8w or 36h",
+				Required: false,
+			},
+			&cli.BoolFlag{
+				Name:     "force",
+				Aliases:  []string{"f"},
+				Value:    false,
+				Usage:    "Also leave rooms where you are the last moderator",
+				Required: false,
+			},
+			&cli.StringFlag{
+				Name:     "confirm",
+				Aliases:  []string{"c"},
+				Value:    "",
+				Usage:    "Continue without confirmation? Allowed values are 'y' or 'n' ",
+				Required: false,
+			},
+			&cli.StringFlag{
+				Name:     "output",
+				Aliases:  []string{"o"},
+				Value:    outputTable,
+				Usage:    "Output format, one of table, csv or json. Default is table",
+				Required: false,
+			},
+		},
+		Action: func(c *cli.Context) error {
+			format := c.String("output")
+			if err := validateOutputFormat(format); err != nil {
+				return err
+			}
+			roomIDs, err := app.targetRoomIDs(c)
+			if err != nil {
+				return err
+			}
+			selection := roomSelection{RoomIDs: roomIDs, Now: time.Now()}
+			if title := c.String("title"); title != "" {
+				if selection.Title, err = regexp.Compile(title); err != nil {
+					return fmt.Errorf("invalid title regex: %v", err)
+				}
+			}
+			if inactive := c.String("inactive"); inactive != "" {
+				if selection.Inactive, err = parseAge(inactive); err != nil {
+					return err
+				}
+			}
+			if selection.empty() {
+				return errors.New("Select rooms to leave with roomsidscsv, team, select, title or inactive")
+			}
+
+			results, err := app.LeaveRooms(selection, c.Bool("force"), c.String("confirm"))
+			if err != nil {
+				return err
+			}
+			return writeOutput(os.Stdout, format, roomResultHeader, roomResultRows(results), results)
+		},
+	}
+}
+
+// LeaveRooms removes the user from the group rooms matching selection after
+// confirmation
+func (app *Application) LeaveRooms(selection roomSelection, force bool, confirm string) ([]roomResult, error) {
+	allRooms, err := app.ListAllRooms("group", "")
+	if err != nil {
+		return nil, err
+	}
+	selected := selection.filter(allRooms)
+	results := make([]roomResult, 0, len(selected))
+	if len(selected) == 0 {
+		log.Info("No rooms match the selection")
+		return results, nil
+	}
+
+	fmt.Fprintln(os.Stderr, "Rooms to leave:")
+	for _, room := range selected {
+		fmt.Fprintf(os.Stderr, "  %s\n", room.Title)
+	}
+	if !confirmPrompt(confirm, fmt.Sprintf("Leave %d room(s)?", len(selected))) {
+		return results, nil
+	}
+
+	for _, room := range selected {
+		result := roomResult{RoomID: room.ID, Title: room.Title, LastActivity: room.LastActivity, Status: "left"}
+		if err := app.leaveRoom(room, force); err != nil {
+			result.Status = "failed"
+			if errors.Is(err, errLastModerator) {
+				result.Status = "skipped"
+			}
+			result.Detail = err.Error()
+		} else {
+			log.Infof("Left room %s", room.Title)
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/WebexCommunity/webex-go-sdk/v2/rooms"
+)
+
+func TestRoomSelectionFilter(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	old := now.AddDate(0, 0, -200)
+	recent := now.AddDate(0, 0, -2)
+	allRooms := []rooms.Room{
+		{ID: "r1", Title: "Test: old", LastActivity: &old},
+		{ID: "r2", Title: "test recent", LastActivity: &recent},
+		{ID: "r3", Title: "Project", LastActivity: &old},
+	}
+	ids := func(selected []*rooms.Room) []string {
+		out := make([]string, 0, len(selected))
+		for _, room := range selected {
+			out = append(out, room.ID)
+		}
+		return out
+	}
+
+	tests := []struct {
+		name      string
+		selection roomSelection
+		expected  []string
+	}{
+		{"room ids", roomSelection{RoomIDs: []string{"r3", "missing"}}, []string{"r3"}},
+		{"title", roomSelection{Title: regexp.MustCompile("(?i)^test")}, []string{"r1", "r2"}},
+		{"inactive", roomSelection{Inactive: 180 * 24 * time.Hour, Now: now}, []string{"r1", "r3"}},
+		{"combined", roomSelection{Title: regexp.MustCompile("(?i)^test"), Inactive: 180 * 24 * time.Hour, Now: now}, []string{"r1"}},
+	}
+	for _, tt := range tests {
+		got := ids(tt.selection.filter(allRooms))
+		if len(got) != len(tt.expected) {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.expected, got)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.expected[i] {
+				t.Errorf("%s: expected %v, got %v", tt.name, tt.expected, got)
+				break
+			}
+		}
+	}
+}
+
+func TestRoomSelectionEmpty(t *testing.T) {
+	if !(roomSelection{Now: time.Now()}).empty() {
+		t.Error("Expected a selection without selectors to be empty")
+	}
+	if (roomSelection{Title: regexp.MustCompile("x")}).empty() {
+		t.Error("Expected a title selection not to be empty")
+	}
+}
@@ -5,10 +5,12 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 
 	"github.com/WebexCommunity/webex-go-sdk/v2/memberships"
+	"github.com/WebexCommunity/webex-go-sdk/v2/messages"
 	"github.com/go-chi/chi"
 	"github.com/go-chi/chi/middleware"
 	"github.com/urfave/cli/v2"
@@ -18,6 +20,7 @@ import (
 type MessageRelayServerApplication struct {
 	*Application
 	MessagerelayKey string
+	Dedup           *MessageDeduplicator
 }
 
 // MessageRelayServer function
@@ -41,6 +44,13 @@ func (app *Application) MessageRelayServer() *cli.Command {
 				Usage:    "A key of length greater than 256, that would be used to establish authenticity of calls",
 				Required: true,
 			},
+			&cli.DurationFlag{
+				Name:     "dedupWindow",
+				Aliases:  []string{"dw"},
+				Value:    0,
+				Usage:    "Suppress repeats of the same message to the same room within this window (eg. 10m). A summary is posted once the window closes. Disabled by default.",
+				Required: false,
+			},
 		},
 		Action: func(c *cli.Context) error {
 
@@ -52,6 +62,15 @@ func (app *Application) MessageRelayServer() *cli.Command {
 			}
 
 			relayApp := &MessageRelayServerApplication{Application: app, MessagerelayKey: messagerelaykey}
+			dedupWindow := c.Duration("dedupWindow")
+			if dedupWindow > 0 {
+				dedup, err := app.NewMessageDeduplicator(dedupWindow)
+				if err != nil {
+					return err
+				}
+				relayApp.Dedup = dedup
+				go relayApp.flushDedupSummaries()
+			}
 			r := chi.NewRouter()
 			r.Use(middleware.RequestID)
 			r.Use(middleware.Logger)
@@ -70,6 +89,22 @@ func (app *MessageRelayServerApplication) index(w http.ResponseWriter, r *http.R
 	w.Write([]byte(fmt.Sprintf("Hi, I can add you to webex rooms maintained by %s", app.Me.DisplayName)))
 }
 
+// flushDedupSummaries periodically posts summaries for closed dedup windows,
+// so repeats are reported even if no further message arrives
+func (app *MessageRelayServerApplication) flushDedupSummaries() {
+	interval := app.Dedup.Window
+	if interval > time.Minute {
+		interval = time.Minute
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		if err := app.FlushDedupSummaries(app.Dedup); err != nil {
+			log.Error(err.Error())
+		}
+	}
+}
+
 func (app *MessageRelayServerApplication) authCheck(r *http.Request) error {
 	messageKey := r.Header.Get("X-Message-Key")
 	if messageKey == "" {
@@ -147,7 +182,12 @@ func (app *MessageRelayServerApplication) sendMessagePOST(w http.ResponseWriter,
 
 	messageParams := &SendMessageParams{RoomID: room.ID, Text: message}
 
-	sentMsg, err := app.SendMessage2Room(messageParams)
+	var sentMsg *messages.Message
+	if app.Dedup != nil {
+		sentMsg, err = app.SendDedupedMessage2Room(app.Dedup, messageParams)
+	} else {
+		sentMsg, err = app.SendMessage2Room(messageParams)
+	}
 	if err != nil {
 		log.Debugf("Error sending message to room %s", webexroom)
 		log.Debug(err.Error())
@@ -157,6 +197,10 @@ func (app *MessageRelayServerApplication) sendMessagePOST(w http.ResponseWriter,
 	}
 
 	w.WriteHeader(http.StatusAccepted)
+	if sentMsg == nil {
+		w.Write([]byte("Suppressed repeated message to room"))
+		return
+	}
 	w.Write([]byte(fmt.Sprintf("Sent message %s to room", sentMsg.ID)))
 	return
 }
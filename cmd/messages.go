@@ -1,8 +1,10 @@
 package cmd
 
 import (
+	"fmt"
 	"time"
 
+	"github.com/WebexCommunity/webex-go-sdk/v2/messages"
 	log "github.com/sirupsen/logrus"
 	"github.com/urfave/cli/v2"
 )
@@ -34,6 +36,20 @@ func (app *Application) SendMessageToRoomCMD() *cli.Command {
 				Usage:    "Remote file get request timeout in seconds",
 				Required: false,
 			},
+			&cli.DurationFlag{
+				Name:     "dedupWindow",
+				Aliases:  []string{"dw"},
+				Value:    0,
+				Usage:    "Suppress repeats of the same message to the same target within this window (eg. 10m). A summary is posted once the window closes. Disabled by default.",
+				Required: false,
+			},
+			&cli.BoolFlag{
+				Name:     "queue-on-failure",
+				Aliases:  []string{"qof"},
+				Value:    false,
+				Usage:    "Queue the message in the outbox when sending fails with a network error, 5xx or 429, and exit with code 3. Deliver it later with outbox flush",
+				Required: false,
+			},
 		},
 		Action: func(c *cli.Context) error {
 			roomID := c.String("roomID")
@@ -54,10 +70,35 @@ func (app *Application) SendMessageToRoomCMD() *cli.Command {
 				RemoteFileRequestTimeout: time.Duration(remoteFileRequestTimeout),
 			}
 
-			// One-shot send
-			sentMessage, err := app.SendMessage2Room(params)
+			var sentMessage *messages.Message
+			var err error
+			dedupWindow := c.Duration("dedupWindow")
+			if dedupWindow > 0 {
+				dedup, dedupErr := app.NewMessageDeduplicator(dedupWindow)
+				if dedupErr != nil {
+					return dedupErr
+				}
+				sentMessage, err = app.SendDedupedMessage2Room(dedup, params)
+			} else {
+				// One-shot send
+				sentMessage, err = app.SendMessage2Room(params)
+			}
 			if err != nil {
 				log.Error(err.Error())
+				if !c.Bool("queue-on-failure") {
+					return nil
+				}
+				if !isRetryableSendError(err) {
+					return cli.Exit("Message was not delivered", 1)
+				}
+				entry, queueErr := app.QueueMessage(params, err)
+				if queueErr != nil {
+					return queueErr
+				}
+				return cli.Exit(fmt.Sprintf("Message queued in outbox as %s", entry.ID), outboxExitQueued)
+			}
+			if sentMessage == nil {
+				log.Infof("Suppressed repeated message within %s", dedupWindow)
 				return nil
 			}
 			log.Infof("Sent message: %s", sentMessage.ID)
@@ -0,0 +1,233 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/WebexCommunity/webex-go-sdk/v2/memberships"
+	log "github.com/sirupsen/logrus"
+	"github.com/urfave/cli/v2"
+)
+
+// roomModerators is the output of room moderators list
+type roomModerators struct {
+	RoomID     string   `json:"roomId"`
+	Title      string   `json:"title"`
+	Moderators []string `json:"moderators"`
+}
+
+// ModeratorsCMD function
+func (app *Application) ModeratorsCMD() *cli.Command {
+	return &cli.Command{
+		Name:        "moderators",
+		Aliases:     []string{"mods"},
+		Description: "List, promote or demote room moderators",
+		Subcommands: []*cli.Command{
+			app.listModeratorsCMD(),
+			app.setModeratorsCMD("set", "Make existing members of room(s) moderators", true),
+			app.setModeratorsCMD("unset", "Demote moderators of room(s) to regular members. The last moderator of a room is never demoted", false),
+		},
+		Action: func(c *cli.Context) error {
+			return nil
+		},
+	}
+}
+
+func moderatorRoomFlags(action string) []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:     "roomsidscsv",
+			Aliases:  []string{"rcsv"},
+			Value:    "",
+			Usage:    fmt.Sprintf("Path to a CSV containing a list of RoomID's whose moderators will be %s.", action),
+			Required: false,
+		},
+		&cli.StringFlag{
+			Name:     "team",
+			Aliases:  []string{"tm"},
+			Value:    "",
+			Usage:    fmt.Sprintf("ID or name of a team whose rooms' moderators will be %s", action),
+			Required: false,
+		},
+	}
+}
+
+func (app *Application) listModeratorsCMD() *cli.Command {
+	return &cli.Command{
+		Name:        "list",
+		Aliases:     []string{"ls"},
+		Description: "List the moderators of room(s)",
+		Flags:       moderatorRoomFlags("listed"),
+		Action: func(c *cli.Context) error {
+			roomIDs, err := app.targetRoomIDs(c)
+			if err != nil {
+				return err
+			}
+			targets, err := app.eligibleRooms(roomIDs, "a", 0)
+			if err != nil {
+				return err
+			}
+			result := make([]roomModerators, 0, len(targets))
+			for _, target := range targets {
+				current, err := app.ListAllMemberships(target.Room.ID)
+				if err != nil {
+					return err
+				}
+				result = append(result, roomModerators{
+					RoomID:     target.Room.ID,
+					Title:      target.Room.Title,
+					Moderators: moderatorEmails(current),
+				})
+			}
+			m, err := json.Marshal(result)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("%s", string(m))
+			return nil
+		},
+	}
+}
+
+func (app *Application) setModeratorsCMD(name string, description string, moderator bool) *cli.Command {
+	action := "promoted"
+	if !moderator {
+		action = "demoted"
+	}
+	return &cli.Command{
+		Name:        name,
+		Description: description,
+		Flags: append([]cli.Flag{
+			&cli.StringFlag{
+				Name:     "memberscsv",
+				Aliases:  []string{"csv"},
+				Value:    "",
+				Usage:    "Path to CSV with list of email addresses formatted as : email",
+				Required: true,
+			},
+			&cli.StringFlag{
+				Name:     "confirm",
+				Aliases:  []string{"c"},
+				Value:    "",
+				Usage:    "Continue without confirmation? Allowed values are 'y' or 'n' ",
+				Required: false,
+			},
+			&cli.StringFlag{
+				Name:     "access",
+				Aliases:  []string{"a"},
+				Value:    "om",
+				Usage:    fmt.Sprintf("Members will be %s in rooms for which you have specified permissions of either 'a' (include all), 'o' (owner), 'm' (moderator) or 'om' (owner and moderator). Default is owner and moderator.", action),
+				Required: false,
+			},
+		}, moderatorRoomFlags(action)...),
+		Action: func(c *cli.Context) error {
+			roomIDs, err := app.targetRoomIDs(c)
+			if err != nil {
+				return err
+			}
+
+			access := c.String("access")
+			if access != "a" && access != "o" && access != "m" && access != "om" {
+				return errors.New("Allowed valued for access flag are a, o, m and om")
+			}
+
+			if len(roomIDs) == 0 && !confirmPrompt(c.String("confirm"), "Continue to update moderators of all rooms that you have access to?") {
+				return nil
+			}
+
+			members, err := readMembersCSV(c.String("memberscsv"))
+			if err != nil {
+				return err
+			}
+			var emails []string
+			for _, member := range members {
+				emails = append(emails, string(member.Email))
+			}
+			updated, err := app.SetModerators(roomIDs, access, emails, moderator)
+			log.Infof("Updated %d membership(s)", updated)
+			return err
+		},
+	}
+}
+
+// SetModerators promotes or demotes the given members in each eligible room
+func (app *Application) SetModerators(roomIDs []string, access string, emails []string, moderator bool) (int, error) {
+	targets, err := app.eligibleRooms(roomIDs, access, 0)
+	if err != nil {
+		return 0, err
+	}
+	updated := 0
+	for _, target := range targets {
+		current, err := app.ListAllMemberships(target.Room.ID)
+		if err != nil {
+			return updated, err
+		}
+		changes, skipped := moderatorChanges(membershipsByEmail(current), emails, moderator)
+		for _, skip := range skipped {
+			log.Warnf("%s in %s", skip, target.Room.Title)
+		}
+		for _, change := range changes {
+			if err := app.applyMemberChange(target.Room.ID, target.Room.Title, change, false); err != nil {
+				log.Error(err.Error())
+				continue
+			}
+			updated++
+		}
+	}
+	return updated, nil
+}
+
+// moderatorChanges lists the membership updates that set the moderator flag
+// of emails, and why any were skipped. Non members are skipped, and the last
+// moderator of a room is never demoted.
+func moderatorChanges(current map[string]memberships.Membership, emails []string, moderator bool) ([]spaceChange, []string) {
+	moderators := 0
+	for _, membership := range current {
+		if membership.IsModerator {
+			moderators++
+		}
+	}
+
+	var changes []spaceChange
+	var skipped []string
+	seen := make(map[string]bool)
+	for _, emailAddress := range emails {
+		emailAddress = strings.ToLower(strings.TrimSpace(emailAddress))
+		if seen[emailAddress] {
+			continue
+		}
+		seen[emailAddress] = true
+		membership, ok := current[emailAddress]
+		if !ok {
+			skipped = append(skipped, fmt.Sprintf("Skipping %s, not a member", emailAddress))
+			continue
+		}
+		if membership.IsModerator == moderator {
+			continue
+		}
+		if !moderator {
+			if moderators <= 1 {
+				skipped = append(skipped, fmt.Sprintf("Not demoting %s, the last moderator", emailAddress))
+				continue
+			}
+			moderators--
+		}
+		changes = append(changes, spaceChange{Action: spaceUpdateMember, Email: emailAddress, Moderator: moderator, MembershipID: membership.ID})
+	}
+	return changes, skipped
+}
+
+// moderatorEmails returns the sorted emails of the moderators among items
+func moderatorEmails(items []memberships.Membership) []string {
+	emails := make([]string, 0)
+	for _, membership := range items {
+		if membership.IsModerator {
+			emails = append(emails, membership.PersonEmail)
+		}
+	}
+	sort.Strings(emails)
+	return emails
+}
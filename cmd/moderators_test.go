@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/WebexCommunity/webex-go-sdk/v2/memberships"
+)
+
+func TestModeratorChangesPromote(t *testing.T) {
+	current := membershipsByEmail([]memberships.Membership{
+		{ID: "m-a", PersonEmail: "a@example.com", IsModerator: true},
+		{ID: "m-b", PersonEmail: "b@example.com"},
+	})
+	changes, skipped := moderatorChanges(current, []string{"A@example.com", "b@example.com", "c@example.com"}, true)
+	if len(changes) != 1 || changes[0].Email != "b@example.com" || !changes[0].Moderator || changes[0].MembershipID != "m-b" {
+		t.Errorf("Expected b@example.com to be promoted, got %+v", changes)
+	}
+	if len(skipped) != 1 {
+		t.Errorf("Expected the non member to be skipped, got %v", skipped)
+	}
+}
+
+func TestModeratorChangesKeepsLastModerator(t *testing.T) {
+	current := membershipsByEmail([]memberships.Membership{
+		{ID: "m-a", PersonEmail: "a@example.com", IsModerator: true},
+		{ID: "m-b", PersonEmail: "b@example.com", IsModerator: true},
+		{ID: "m-c", PersonEmail: "c@example.com"},
+	})
+	changes, skipped := moderatorChanges(current, []string{"a@example.com", "b@example.com"}, false)
+	if len(changes) != 1 || changes[0].Email != "a@example.com" || changes[0].Moderator {
+		t.Errorf("Expected only a@example.com to be demoted, got %+v", changes)
+	}
+	if len(skipped) != 1 {
+		t.Errorf("Expected the last moderator to be kept, got %v", skipped)
+	}
+}
+
+func TestModeratorEmails(t *testing.T) {
+	emails := moderatorEmails([]memberships.Membership{
+		{PersonEmail: "b@example.com", IsModerator: true},
+		{PersonEmail: "c@example.com"},
+		{PersonEmail: "a@example.com", IsModerator: true},
+	})
+	if len(emails) != 2 || emails[0] != "a@example.com" || emails[1] != "b@example.com" {
+		t.Errorf("Unexpected moderators %v", emails)
+	}
+}
+
+func TestModeratorsCMDStructure(t *testing.T) {
+	app := &Application{}
+	cmd := app.ModeratorsCMD()
+	expected := []string{"list", "set", "unset"}
+	if len(cmd.Subcommands) != len(expected) {
+		t.Fatalf("Expected %d subcommands, got %d", len(expected), len(cmd.Subcommands))
+	}
+	for i, name := range expected {
+		if cmd.Subcommands[i].Name != name {
+			t.Errorf("Expected subcommand %q, got %q", name, cmd.Subcommands[i].Name)
+		}
+	}
+	for _, flagName := range []string{"memberscsv", "access", "roomsidscsv", "team", "confirm"} {
+		if getFlagByName(cmd.Subcommands[2].Flags, flagName) == nil {
+			t.Errorf("Expected unset flag %q not found", flagName)
+		}
+	}
+}
@@ -0,0 +1,387 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/urfave/cli/v2"
+)
+
+const (
+	outboxDir       = "outbox"
+	outboxIndexFile = "outbox.json"
+	outboxFilesDir  = "files"
+)
+
+// outboxExitQueued is the exit code of room msg when the message could not
+// be delivered and was queued in the outbox instead
+const outboxExitQueued = 3
+
+var apiErrorStatus = regexp.MustCompile(`API error: (\d{3})`)
+
+// OutboxEntry is a message waiting to be delivered
+type OutboxEntry struct {
+	ID          string    `json:"id"`
+	RoomID      string    `json:"roomId,omitempty"`
+	PersonID    string    `json:"personId,omitempty"`
+	PersonEmail string    `json:"personEmail,omitempty"`
+	Text        string    `json:"text,omitempty"`
+	File        string    `json:"file,omitempty"`
+	Created     time.Time `json:"created"`
+	Attempts    int       `json:"attempts"`
+	LastError   string    `json:"lastError,omitempty"`
+	Failed      bool      `json:"failed"`
+}
+
+// outboxStore is the persisted, ordered list of queued messages
+type outboxStore struct {
+	Entries []*OutboxEntry `json:"entries"`
+}
+
+// OutboxCMD function
+func (app *Application) OutboxCMD() *cli.Command {
+	return &cli.Command{
+		Name:    "outbox",
+		Aliases: []string{"ob"},
+		Usage:   "Inspect and deliver messages queued by room msg --queue-on-failure",
+		Subcommands: []*cli.Command{
+			app.outboxListCMD(),
+			app.outboxFlushCMD(),
+			app.outboxPurgeCMD(),
+		},
+		Action: func(c *cli.Context) error {
+			return nil
+		},
+	}
+}
+
+func (app *Application) outboxListCMD() *cli.Command {
+	return &cli.Command{
+		Name:        "list",
+		Aliases:     []string{"ls"},
+		Description: "List queued messages in delivery order",
+		Action: func(c *cli.Context) error {
+			store, err := app.loadOutbox()
+			if err != nil {
+				return err
+			}
+			if store.Entries == nil {
+				store.Entries = make([]*OutboxEntry, 0)
+			}
+			m, err := json.Marshal(store.Entries)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("%s", string(m))
+			return nil
+		},
+	}
+}
+
+func (app *Application) outboxFlushCMD() *cli.Command {
+	return &cli.Command{
+		Name:        "flush",
+		Aliases:     []string{"f"},
+		Description: "Deliver queued messages in order. Stops at the first message that still cannot be delivered so order is preserved",
+		Flags: []cli.Flag{
+			&cli.IntFlag{
+				Name:     "retries",
+				Aliases:  []string{"r"},
+				Value:    3,
+				Usage:    "Number of retries per message, with exponential backoff. Default is 3",
+				Required: false,
+			},
+			&cli.DurationFlag{
+				Name:     "backoff",
+				Aliases:  []string{"b"},
+				Value:    2 * time.Second,
+				Usage:    "Delay before the first retry, doubled on every further retry. Default is 2s",
+				Required: false,
+			},
+		},
+		Action: func(c *cli.Context) error {
+			delivered, err := app.FlushOutbox(c.Int("retries"), c.Duration("backoff"))
+			log.Infof("Delivered %d queued message(s)", delivered)
+			return err
+		},
+	}
+}
+
+func (app *Application) outboxPurgeCMD() *cli.Command {
+	return &cli.Command{
+		Name:        "purge",
+		Aliases:     []string{"p"},
+		Description: "Remove queued messages without delivering them. Removes the given IDs, failed messages with --failed, or everything",
+		ArgsUsage:   "[message-id...]",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:     "failed",
+				Value:    false,
+				Usage:    "Only purge messages that failed permanently",
+				Required: false,
+			},
+			&cli.StringFlag{
+				Name:     "confirm",
+				Aliases:  []string{"c"},
+				Value:    "",
+				Usage:    "Continue without confirmation? Allowed values are 'y' or 'n' ",
+				Required: false,
+			},
+		},
+		Action: func(c *cli.Context) error {
+			ids := make(map[string]bool)
+			for _, id := range c.Args().Slice() {
+				ids[id] = true
+			}
+			failedOnly := c.Bool("failed")
+
+			if len(ids) == 0 && !failedOnly {
+				if !confirmPrompt(c.String("confirm"), "Purge all queued messages?") {
+					return nil
+				}
+			}
+
+			purged := 0
+			err := app.updateOutbox(func(store *outboxStore) error {
+				var kept []*OutboxEntry
+				for _, entry := range store.Entries {
+					purge := ids[entry.ID] || (failedOnly && entry.Failed) || (len(ids) == 0 && !failedOnly)
+					if !purge {
+						kept = append(kept, entry)
+						continue
+					}
+					if err := app.removeOutboxFiles(entry); err != nil {
+						log.Error(err.Error())
+					}
+					purged++
+				}
+				store.Entries = kept
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			log.Infof("Purged %d queued message(s)", purged)
+			return nil
+		},
+	}
+}
+
+// isRetryableSendError reports whether a send failed for a transient reason
+// (network error, 5xx or 429) and is worth queueing for a later attempt
+func isRetryableSendError(err error) bool {
+	if err == nil {
+		return false
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return true
+	}
+	if m := apiErrorStatus.FindStringSubmatch(err.Error()); m != nil {
+		status, _ := strconv.Atoi(m[1])
+		return status == 429 || status >= 500
+	}
+	return false
+}
+
+func (app *Application) outboxPath(elem ...string) (string, error) {
+	statePath, err := app.statePath(outboxDir)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(append([]string{statePath}, elem...)...), nil
+}
+
+func (app *Application) loadOutbox() (*outboxStore, error) {
+	indexPath, err := app.outboxPath(outboxIndexFile)
+	if err != nil {
+		return nil, err
+	}
+	store := &outboxStore{}
+	if err := loadStateFile(indexPath, store); err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+// updateOutbox loads the outbox, applies update and saves it back. The
+// state lock is held throughout, so queueing while a flush runs loses
+// neither change.
+func (app *Application) updateOutbox(update func(store *outboxStore) error) error {
+	indexPath, err := app.outboxPath(outboxIndexFile)
+	if err != nil {
+		return err
+	}
+	unlock, err := lockStateFile(indexPath)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	store := &outboxStore{}
+	if err := loadStateFile(indexPath, store); err != nil {
+		return err
+	}
+	if err := update(store); err != nil {
+		return err
+	}
+	return saveStateFile(indexPath, store)
+}
+
+func (app *Application) removeOutboxFiles(entry *OutboxEntry) error {
+	filesPath, err := app.outboxPath(outboxFilesDir, entry.ID)
+	if err != nil {
+		return err
+	}
+	return os.RemoveAll(filesPath)
+}
+
+// QueueMessage persists a message, along with a copy of its file, so it can
+// be delivered later by FlushOutbox
+func (app *Application) QueueMessage(params *SendMessageParams, sendErr error) (*OutboxEntry, error) {
+	id, err := newStateID()
+	if err != nil {
+		return nil, err
+	}
+	entry := &OutboxEntry{
+		ID:          id,
+		RoomID:      params.RoomID,
+		PersonID:    params.PersonID,
+		PersonEmail: params.PersonEmail,
+		Text:        params.Text,
+		File:        params.Filename,
+		Created:     time.Now(),
+		Attempts:    1,
+	}
+	if sendErr != nil {
+		entry.LastError = sendErr.Error()
+	}
+
+	if params.Filename != "" {
+		// Keep a copy of the file so it is still around when the outbox is flushed.
+		// If it cannot be resolved now (eg. remote file while offline), the
+		// original path is retried at flush time.
+		fileUpload, err := app.resolveFile(params)
+		if err == nil {
+			filesPath, err := app.outboxPath(outboxFilesDir, id)
+			if err != nil {
+				return nil, err
+			}
+			if err := os.MkdirAll(filesPath, 0700); err != nil {
+				return nil, err
+			}
+			filePath := filepath.Join(filesPath, fileUpload.FileName)
+			if err := os.WriteFile(filePath, fileUpload.FileBytes, 0600); err != nil {
+				return nil, err
+			}
+			entry.File = filePath
+		}
+	}
+
+	err = app.updateOutbox(func(store *outboxStore) error {
+		store.Entries = append(store.Entries, entry)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// FlushOutbox delivers queued messages in order, retrying each with
+// exponential backoff. Messages that fail permanently are marked as failed
+// and skipped; a message that still fails transiently stops the flush so
+// later messages are not delivered ahead of it.
+func (app *Application) FlushOutbox(retries int, backoff time.Duration) (int, error) {
+	store, err := app.loadOutbox()
+	if err != nil {
+		return 0, err
+	}
+
+	delivered := 0
+	for _, entry := range store.Entries {
+		if entry.Failed {
+			continue
+		}
+		params := &SendMessageParams{
+			RoomID:                   entry.RoomID,
+			PersonID:                 entry.PersonID,
+			PersonEmail:              entry.PersonEmail,
+			Text:                     entry.Text,
+			Filename:                 entry.File,
+			RemoteFileRequestTimeout: time.Duration(10),
+		}
+
+		var sendErr error
+		attempts := 0
+		for attempt := 0; attempt <= retries; attempt++ {
+			if attempt > 0 {
+				time.Sleep(backoff * (1 << uint(attempt-1)))
+			}
+			attempts++
+			sentMessage, err := app.SendMessage2Room(params)
+			if err == nil {
+				log.Infof("Delivered queued message %s: %s", entry.ID, sentMessage.ID)
+				sendErr = nil
+				break
+			}
+			sendErr = err
+			if !isRetryableSendError(err) {
+				break
+			}
+		}
+
+		entryID := entry.ID
+		if sendErr == nil {
+			delivered++
+			if err := app.removeOutboxFiles(entry); err != nil {
+				log.Error(err.Error())
+			}
+			err = app.updateOutbox(func(store *outboxStore) error {
+				for i, stored := range store.Entries {
+					if stored.ID == entryID {
+						store.Entries = append(store.Entries[:i], store.Entries[i+1:]...)
+						break
+					}
+				}
+				return nil
+			})
+			if err != nil {
+				return delivered, err
+			}
+			continue
+		}
+
+		retryable := isRetryableSendError(sendErr)
+		err = app.updateOutbox(func(store *outboxStore) error {
+			for _, stored := range store.Entries {
+				if stored.ID == entryID {
+					stored.Attempts += attempts
+					stored.LastError = sendErr.Error()
+					stored.Failed = !retryable
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return delivered, err
+		}
+		if retryable {
+			return delivered, fmt.Errorf("Stopped flushing at message %s: %w", entryID, sendErr)
+		}
+		log.Errorf("Queued message %s failed permanently: %s", entryID, sendErr.Error())
+	}
+	return delivered, nil
+}
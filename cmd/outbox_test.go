@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestIsRetryableSendError(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{"nil", nil, false},
+		{"url error", &url.Error{Op: "Post", URL: "https://webexapis.com/v1/messages", Err: errors.New("connection refused")}, true},
+		{"dns error", &net.DNSError{Err: "no such host", Name: "webexapis.com"}, true},
+		{"wrapped network error", fmt.Errorf("send: %w", &net.OpError{Op: "dial", Err: errors.New("timeout")}), true},
+		{"rate limited", errors.New("API error: 429 - Too Many Requests"), true},
+		{"server error", errors.New("API error: 503 - Service Unavailable"), true},
+		{"not found", errors.New("API error: 404 - Not Found"), false},
+		{"bad request", errors.New("API error: 400 - Bad Request"), false},
+		{"validation", errors.New("roomID or PersonID or PersonEmail is required"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isRetryableSendError(tt.err); got != tt.expected {
+				t.Errorf("isRetryableSendError(%v) = %v, want %v", tt.err, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestQueueMessage(t *testing.T) {
+	app := &Application{StateDir: t.TempDir()}
+
+	srcFile := filepath.Join(t.TempDir(), "report.txt")
+	if err := os.WriteFile(srcFile, []byte("report"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	first, err := app.QueueMessage(&SendMessageParams{RoomID: "room1", Text: "first"}, errors.New("API error: 503 - Service Unavailable"))
+	if err != nil {
+		t.Fatalf("QueueMessage() error = %v", err)
+	}
+	second, err := app.QueueMessage(&SendMessageParams{RoomID: "room1", Text: "second", Filename: srcFile}, nil)
+	if err != nil {
+		t.Fatalf("QueueMessage() error = %v", err)
+	}
+
+	if first.LastError == "" {
+		t.Error("Expected the send error to be recorded")
+	}
+
+	// The file is copied into the outbox, keeping its name
+	if second.File == srcFile || filepath.Base(second.File) != "report.txt" {
+		t.Errorf("Expected a copy of the file in the outbox, got %q", second.File)
+	}
+	if err := os.Remove(srcFile); err != nil {
+		t.Fatal(err)
+	}
+	content, err := os.ReadFile(second.File)
+	if err != nil || string(content) != "report" {
+		t.Errorf("Expected the outbox copy to survive the original, got %q, %v", string(content), err)
+	}
+
+	store, err := app.loadOutbox()
+	if err != nil {
+		t.Fatalf("loadOutbox() error = %v", err)
+	}
+	if len(store.Entries) != 2 || store.Entries[0].ID != first.ID || store.Entries[1].ID != second.ID {
+		t.Fatalf("Expected entries in queue order, got %+v", store.Entries)
+	}
+
+	if err := app.removeOutboxFiles(second); err != nil {
+		t.Fatalf("removeOutboxFiles() error = %v", err)
+	}
+	if _, err := os.Stat(second.File); !os.IsNotExist(err) {
+		t.Error("Expected the outbox copy to be removed")
+	}
+}
+
+func TestQueueMessageConcurrent(t *testing.T) {
+	app := &Application{StateDir: t.TempDir()}
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if _, err := app.QueueMessage(&SendMessageParams{RoomID: "room1", Text: fmt.Sprintf("message %d", i)}, nil); err != nil {
+				t.Errorf("QueueMessage() error = %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	store, err := app.loadOutbox()
+	if err != nil {
+		t.Fatalf("loadOutbox() error = %v", err)
+	}
+	if len(store.Entries) != 10 {
+		t.Errorf("Expected every queued message to be kept, got %d", len(store.Entries))
+	}
+}
@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+// Output formats of report style commands
+const (
+	outputTable = "table"
+	outputCSV   = "csv"
+	outputJSON  = "json"
+)
+
+// validateOutputFormat checks the value of an --output flag
+func validateOutputFormat(format string) error {
+	if format != outputTable && format != outputCSV && format != outputJSON {
+		return fmt.Errorf("Allowed values for output flag are %s, %s and %s", outputTable, outputCSV, outputJSON)
+	}
+	return nil
+}
+
+// writeOutput writes rows under header as an aligned table or CSV, or
+// marshals v for JSON
+func writeOutput(w io.Writer, format string, header []string, rows [][]string, v interface{}) error {
+	switch format {
+	case outputJSON:
+		m, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(w, "%s", string(m))
+		return err
+	case outputCSV:
+		csvWriter := csv.NewWriter(w)
+		csvWriter.Write(header)
+		csvWriter.WriteAll(rows)
+		return csvWriter.Error()
+	default:
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		upper := make([]string, len(header))
+		for i, column := range header {
+			upper[i] = strings.ToUpper(column)
+		}
+		fmt.Fprintln(tw, strings.Join(upper, "\t"))
+		for _, row := range rows {
+			fmt.Fprintln(tw, strings.Join(row, "\t"))
+		}
+		return tw.Flush()
+	}
+}
@@ -0,0 +1,201 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/WebexCommunity/webex-go-sdk/v2/memberships"
+	"github.com/WebexCommunity/webex-go-sdk/v2/messages"
+	"github.com/WebexCommunity/webex-go-sdk/v2/rooms"
+	"github.com/WebexCommunity/webex-go-sdk/v2/teammemberships"
+	"github.com/WebexCommunity/webex-go-sdk/v2/teams"
+	"github.com/urfave/cli/v2"
+)
+
+var linkNextPattern = regexp.MustCompile(`<([^>]+)>\s*;\s*rel="?next"?`)
+
+// errStopPaging is returned by a visit function to stop listing without an
+// error
+var errStopPaging = errors.New("stop paging")
+
+// limitFlag is the --limit flag of commands that list or act on a
+// collection
+func limitFlag() *cli.IntFlag {
+	return &cli.IntFlag{
+		Name:     "limit",
+		Aliases:  []string{"lim"},
+		Value:    0,
+		Usage:    "Stop after this many items. Default is no limit, every page is read",
+		Required: false,
+	}
+}
+
+// nextPageLink returns the URL of the next page from a response's Link
+// header, or an empty string on the last page
+func nextPageLink(header http.Header) string {
+	for _, link := range header.Values("Link") {
+		if m := linkNextPattern.FindStringSubmatch(link); m != nil {
+			return m[1]
+		}
+	}
+	return ""
+}
+
+// listAllPages requests a list endpoint and follows the Link headers that the
+// SDK list calls ignore, passing each page's raw items to page
+func (app *Application) listAllPages(apiPath string, params url.Values, page func(items json.RawMessage) error) error {
+	baseURL := strings.TrimSuffix(app.Client.Core().BaseURL.String(), "/") + "/"
+	for apiPath != "" {
+		resp, err := app.Client.Core().Request(http.MethodGet, apiPath, params, nil)
+		if err != nil {
+			return err
+		}
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return err
+		}
+		if resp.StatusCode >= 400 {
+			return fmt.Errorf("API error: %d - %s", resp.StatusCode, string(body))
+		}
+		var result struct {
+			Items json.RawMessage `json:"items"`
+		}
+		if err := json.Unmarshal(body, &result); err != nil {
+			return err
+		}
+		if err := page(result.Items); err != nil {
+			return err
+		}
+
+		apiPath, params = "", nil
+		next := nextPageLink(resp.Header)
+		if next == "" {
+			break
+		}
+		if !strings.HasPrefix(next, baseURL) {
+			return fmt.Errorf("Unexpected next page URL %s", next)
+		}
+		nextURL, err := url.Parse(next)
+		if err != nil {
+			return err
+		}
+		apiPath = strings.TrimPrefix(nextURL.Path, strings.TrimSuffix(app.Client.Core().BaseURL.Path, "/")+"/")
+		params = nextURL.Query()
+	}
+	return nil
+}
+
+// eachItem decodes the items of every page of a list endpoint and passes
+// them to visit one by one, stopping after limit items when limit is greater
+// than 0. The page size in params is lowered to the limit when it is larger.
+func eachItem[T any](app *Application, apiPath string, params url.Values, limit int, visit func(item T) error) error {
+	if limit > 0 {
+		if max, err := strconv.Atoi(params.Get("max")); err != nil || max > limit {
+			params.Set("max", strconv.Itoa(limit))
+		}
+	}
+	visited := 0
+	err := app.listAllPages(apiPath, params, func(items json.RawMessage) error {
+		var pageItems []T
+		if err := json.Unmarshal(items, &pageItems); err != nil {
+			return err
+		}
+		for _, item := range pageItems {
+			if err := visit(item); err != nil {
+				return err
+			}
+			visited++
+			if limit > 0 && visited >= limit {
+				return errStopPaging
+			}
+		}
+		return nil
+	})
+	if errors.Is(err, errStopPaging) {
+		return nil
+	}
+	return err
+}
+
+// listItems returns the items of every page of a list endpoint, up to limit
+// items when limit is greater than 0
+func listItems[T any](app *Application, apiPath string, params url.Values, limit int) ([]T, error) {
+	all := make([]T, 0)
+	err := eachItem(app, apiPath, params, limit, func(item T) error {
+		all = append(all, item)
+		return nil
+	})
+	return all, err
+}
+
+// ListAllMemberships retrieves every membership of a room across all pages,
+// or the authenticated user's memberships when roomID is empty
+func (app *Application) ListAllMemberships(roomID string) ([]memberships.Membership, error) {
+	return app.ListMemberships(roomID, 0)
+}
+
+// ListMemberships retrieves up to limit memberships of a room, or of the
+// authenticated user when roomID is empty. A limit of 0 reads every page.
+func (app *Application) ListMemberships(roomID string, limit int) ([]memberships.Membership, error) {
+	params := url.Values{}
+	if roomID != "" {
+		params.Set("roomId", roomID)
+	}
+	params.Set("max", "1000")
+	return listItems[memberships.Membership](app, "memberships", params, limit)
+}
+
+// ListAllRooms retrieves every room the authenticated user belongs to across
+// all pages, optionally filtered by type and team
+func (app *Application) ListAllRooms(roomType string, teamID string) ([]rooms.Room, error) {
+	return app.ListRooms(roomType, teamID, 0)
+}
+
+// ListRooms retrieves up to limit rooms of the authenticated user sorted by
+// last activity, optionally filtered by type and team. A limit of 0 reads
+// every page.
+func (app *Application) ListRooms(roomType string, teamID string, limit int) ([]rooms.Room, error) {
+	params := url.Values{}
+	if roomType != "" {
+		params.Set("type", roomType)
+	}
+	if teamID != "" {
+		params.Set("teamId", teamID)
+	}
+	params.Set("sortBy", "lastactivity")
+	params.Set("max", "1000")
+	return listItems[rooms.Room](app, "rooms", params, limit)
+}
+
+// ListTeams retrieves up to limit teams of the authenticated user. A limit
+// of 0 reads every page.
+func (app *Application) ListTeams(limit int) ([]teams.Team, error) {
+	params := url.Values{"max": {"1000"}}
+	return listItems[teams.Team](app, "teams", params, limit)
+}
+
+// ListTeamMemberships retrieves up to limit memberships of a team. A limit
+// of 0 reads every page.
+func (app *Application) ListTeamMemberships(teamID string, limit int) ([]teammemberships.TeamMembership, error) {
+	params := url.Values{"teamId": {teamID}, "max": {"1000"}}
+	return listItems[teammemberships.TeamMembership](app, "team/memberships", params, limit)
+}
+
+// eachMessage passes the messages of a room to visit, newest first, until
+// limit messages were visited or visit returns errStopPaging. Only messages
+// mentioning the authenticated user are listed when mentionedMe is set.
+func (app *Application) eachMessage(roomID string, mentionedMe bool, limit int, visit func(message messages.Message) error) error {
+	params := url.Values{"roomId": {roomID}, "max": {"100"}}
+	if mentionedMe {
+		params.Set("mentionedPeople", "me")
+	}
+	return eachItem(app, "messages", params, limit, visit)
+}
@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+
+	webex "github.com/WebexCommunity/webex-go-sdk/v2"
+	"github.com/WebexCommunity/webex-go-sdk/v2/rooms"
+	"github.com/WebexCommunity/webex-go-sdk/v2/webexsdk"
+)
+
+func TestNextPageLink(t *testing.T) {
+	header := http.Header{}
+	header.Add("Link", `<https://webexapis.com/v1/memberships?roomId=abc&max=2&cursor=xyz>; rel="next"`)
+	if got := nextPageLink(header); got != "https://webexapis.com/v1/memberships?roomId=abc&max=2&cursor=xyz" {
+		t.Errorf("Unexpected next link %q", got)
+	}
+
+	header = http.Header{}
+	header.Add("Link", `<https://webexapis.com/v1/rooms?cursor=a>; rel="prev", <https://webexapis.com/v1/rooms?cursor=b>; rel="next"`)
+	if got := nextPageLink(header); got != "https://webexapis.com/v1/rooms?cursor=b" {
+		t.Errorf("Unexpected next link %q", got)
+	}
+
+	if got := nextPageLink(http.Header{}); got != "" {
+		t.Errorf("Expected no next link, got %q", got)
+	}
+}
+
+// newTestPagingApp returns an application whose client talks to a fake rooms
+// API serving total rooms in pages of the requested size
+func newTestPagingApp(t *testing.T, total int, requests *[]url.Values) *Application {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		*requests = append(*requests, query)
+		max, _ := strconv.Atoi(query.Get("max"))
+		start, _ := strconv.Atoi(query.Get("cursor"))
+		end := start + max
+		if end > total {
+			end = total
+		}
+		items := []rooms.Room{}
+		for i := start; i < end; i++ {
+			items = append(items, rooms.Room{ID: fmt.Sprintf("room%d", i)})
+		}
+		if end < total {
+			w.Header().Set("Link", fmt.Sprintf(`<%s/rooms?max=%d&cursor=%d>; rel="next"`, server.URL, max, end))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"items": items})
+	}))
+	t.Cleanup(server.Close)
+
+	client, err := webex.NewClient("test-token", &webexsdk.Config{BaseURL: server.URL, MaxRetries: 0})
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	return &Application{Client: client}
+}
+
+func TestListItemsFollowsPages(t *testing.T) {
+	var requests []url.Values
+	app := newTestPagingApp(t, 5, &requests)
+	items, err := listItems[rooms.Room](app, "rooms", url.Values{"max": {"2"}}, 0)
+	if err != nil {
+		t.Fatalf("listItems() error = %v", err)
+	}
+	if len(items) != 5 || items[4].ID != "room4" {
+		t.Errorf("Expected all 5 rooms, got %+v", items)
+	}
+	if len(requests) != 3 {
+		t.Errorf("Expected 3 page requests, got %d", len(requests))
+	}
+}
+
+func TestListItemsLimit(t *testing.T) {
+	var requests []url.Values
+	app := newTestPagingApp(t, 10, &requests)
+	items, err := listItems[rooms.Room](app, "rooms", url.Values{"max": {"4"}}, 6)
+	if err != nil {
+		t.Fatalf("listItems() error = %v", err)
+	}
+	if len(items) != 6 || items[5].ID != "room5" {
+		t.Errorf("Expected 6 rooms, got %+v", items)
+	}
+	if len(requests) != 2 {
+		t.Errorf("Expected the listing to stop after 2 pages, got %d", len(requests))
+	}
+
+	requests = nil
+	items, err = app.ListRooms("group", "", 3)
+	if err != nil {
+		t.Fatalf("ListRooms() error = %v", err)
+	}
+	if len(items) != 3 || len(requests) != 1 || requests[0].Get("max") != "3" {
+		t.Errorf("Expected one page of 3 rooms, got %d rooms in %d requests", len(items), len(requests))
+	}
+}
+
+func TestEachItemStop(t *testing.T) {
+	var requests []url.Values
+	app := newTestPagingApp(t, 10, &requests)
+	visited := 0
+	err := eachItem(app, "rooms", url.Values{"max": {"2"}}, 0, func(room rooms.Room) error {
+		visited++
+		if room.ID == "room2" {
+			return errStopPaging
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("eachItem() error = %v", err)
+	}
+	if visited != 3 || len(requests) != 2 {
+		t.Errorf("Expected to stop at the third room, visited %d in %d requests", visited, len(requests))
+	}
+}
@@ -0,0 +1,334 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/WebexCommunity/webex-go-sdk/v2/people"
+	"github.com/gammazero/workerpool"
+	log "github.com/sirupsen/logrus"
+	"github.com/tejzpr/webex-teams-cli/cmd/webexid"
+	"github.com/urfave/cli/v2"
+)
+
+// Statuses of people resolve
+const (
+	personFound       = "found"
+	personNotFound    = "not found"
+	personExternal    = "external"
+	personDeactivated = "deactivated"
+	personLookupError = "error"
+)
+
+// personDetails extends the SDK person with fields it does not map
+type personDetails struct {
+	people.Person
+	LoginEnabled  *bool `json:"loginEnabled,omitempty"`
+	InvitePending bool  `json:"invitePending,omitempty"`
+}
+
+// personResolution is the outcome of looking up an email address
+type personResolution struct {
+	Email       string `json:"email"`
+	PersonID    string `json:"personId,omitempty"`
+	DisplayName string `json:"displayName,omitempty"`
+	OrgID       string `json:"orgId,omitempty"`
+	Status      string `json:"status"`
+	Detail      string `json:"detail,omitempty"`
+}
+
+// resolvable reports whether the person can be added to rooms
+func (resolution *personResolution) resolvable() bool {
+	return resolution.Status == personFound || resolution.Status == personExternal
+}
+
+// unavailable reports whether the person is known not to be addable. A
+// failed lookup says nothing about the address, so it is not unavailable.
+func (resolution *personResolution) unavailable() bool {
+	return resolution.Status == personNotFound || resolution.Status == personDeactivated
+}
+
+var peopleSearchHeader = []string{"id", "displayName", "email", "orgId", "type"}
+
+var resolvedColumns = []string{"personId", "displayName", "orgId", "status"}
+
+// PeopleCMD function
+func (app *Application) PeopleCMD() *cli.Command {
+	return &cli.Command{
+		Name:    "people",
+		Aliases: []string{"pp"},
+		Usage:   "Look up Webex users",
+		Subcommands: []*cli.Command{
+			app.GetPersonCMD(),
+			app.SearchPeopleCMD(),
+			app.ResolvePeopleCMD(),
+			app.ExportOrgPeopleCMD(),
+		},
+		Action: func(c *cli.Context) error {
+			return nil
+		},
+	}
+}
+
+// GetPersonCMD function
+func (app *Application) GetPersonCMD() *cli.Command {
+	return &cli.Command{
+		Name:        "get",
+		Aliases:     []string{"g"},
+		Usage:       "get <email|id>",
+		Description: "Show a person by email address or person ID",
+		Action: func(c *cli.Context) error {
+			if c.Args().Len() != 1 {
+				return errors.New("an email address or person ID is required")
+			}
+			ref := c.Args().First()
+			person, err := app.lookupPerson(ref)
+			if err != nil {
+				return err
+			}
+			if person == nil {
+				return fmt.Errorf("No person found for %s", ref)
+			}
+			m, err := json.Marshal(person)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("%s", string(m))
+			return nil
+		},
+	}
+}
+
+// SearchPeopleCMD function
+func (app *Application) SearchPeopleCMD() *cli.Command {
+	return &cli.Command{
+		Name:        "search",
+		Aliases:     []string{"s"},
+		Description: "Search people by the start of their display name",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:     "name",
+				Aliases:  []string{"n"},
+				Value:    "",
+				Usage:    "Start of the display name to search for",
+				Required: true,
+			},
+			&cli.IntFlag{
+				Name:     "max",
+				Aliases:  []string{"m"},
+				Value:    50,
+				Usage:    "Maximum number of people to return. Default is 50",
+				Required: false,
+			},
+			&cli.StringFlag{
+				Name:     "output",
+				Aliases:  []string{"o"},
+				Value:    outputTable,
+				Usage:    "Output format, one of table, csv or json. Default is table",
+				Required: false,
+			},
+		},
+		Action: func(c *cli.Context) error {
+			format := c.String("output")
+			if err := validateOutputFormat(format); err != nil {
+				return err
+			}
+			found, err := app.searchPeople(c.String("name"), c.Int("max"))
+			if err != nil {
+				return err
+			}
+			rows := make([][]string, 0, len(found))
+			for _, person := range found {
+				rows = append(rows, []string{person.ID, person.DisplayName, strings.Join(person.Emails, " "), person.OrgID, person.Type})
+			}
+			return writeOutput(os.Stdout, format, peopleSearchHeader, rows, found)
+		},
+	}
+}
+
+// ResolvePeopleCMD function
+func (app *Application) ResolvePeopleCMD() *cli.Command {
+	return &cli.Command{
+		Name:        "resolve",
+		Aliases:     []string{"r"},
+		Description: "Look up every email address of a CSV and write it back with personId, displayName, orgId and status (found, not found, external or deactivated) columns",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:     "csv",
+				Aliases:  []string{"in"},
+				Value:    "",
+				Usage:    "Path to a CSV with an email column",
+				Required: true,
+			},
+			&cli.StringFlag{
+				Name:     "out",
+				Aliases:  []string{"o"},
+				Value:    "",
+				Usage:    "Path of the annotated CSV. Defaults to stdout",
+				Required: false,
+			},
+		},
+		Action: func(c *cli.Context) error {
+			in, err := openCSVFile(c.String("csv"))
+			if err != nil {
+				return err
+			}
+			defer in.Close()
+
+			var out io.Writer = os.Stdout
+			if outPath := c.String("out"); outPath != "" {
+				outFile, err := os.Create(outPath)
+				if err != nil {
+					return err
+				}
+				defer outFile.Close()
+				out = outFile
+			}
+			return app.ResolvePeopleCSV(in, out)
+		},
+	}
+}
+
+// ResolvePeopleCSV copies the CSV read from r to w, appending the resolved
+// person columns to each row
+func (app *Application) ResolvePeopleCSV(r io.Reader, w io.Writer) error {
+	records, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return err
+	}
+	if len(records) == 0 {
+		return errors.New("CSV is empty")
+	}
+	emailColumn := -1
+	for i, column := range records[0] {
+		if strings.EqualFold(strings.TrimSpace(column), "email") {
+			emailColumn = i
+		}
+	}
+	if emailColumn < 0 {
+		return errors.New("CSV has no email column")
+	}
+
+	emails := make([]string, 0, len(records)-1)
+	for _, record := range records[1:] {
+		if emailColumn < len(record) {
+			emails = append(emails, record[emailColumn])
+		}
+	}
+	resolved := app.resolvePeople(emails)
+
+	csvWriter := csv.NewWriter(w)
+	csvWriter.Write(append(records[0], resolvedColumns...))
+	for _, record := range records[1:] {
+		resolution := &personResolution{Status: personNotFound}
+		if emailColumn < len(record) {
+			resolution = resolved[normalizeEmail(record[emailColumn])]
+		}
+		csvWriter.Write(append(record, resolution.PersonID, resolution.DisplayName, resolution.OrgID, resolution.Status))
+	}
+	csvWriter.Flush()
+	return csvWriter.Error()
+}
+
+// resolvePeople looks up each distinct email address once. Results are keyed
+// by the normalized address.
+func (app *Application) resolvePeople(emails []string) map[string]*personResolution {
+	var mu sync.Mutex
+	resolved := make(map[string]*personResolution)
+	wp := workerpool.New(4)
+	for _, address := range emails {
+		key := normalizeEmail(address)
+		if _, ok := resolved[key]; ok {
+			continue
+		}
+		resolution := &personResolution{Email: key}
+		resolved[key] = resolution
+		wp.Submit(func() {
+			person, err := app.lookupPerson(key)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				resolution.Status = personLookupError
+				resolution.Detail = err.Error()
+				log.Debugf("Error looking up %s: %s", key, err.Error())
+				return
+			}
+			app.annotateResolution(resolution, person)
+		})
+	}
+	wp.StopWait()
+	return resolved
+}
+
+// annotateResolution fills resolution from the looked up person, which is
+// nil when nobody has the address
+func (app *Application) annotateResolution(resolution *personResolution, person *personDetails) {
+	if person == nil {
+		resolution.Status = personNotFound
+		return
+	}
+	resolution.PersonID = person.ID
+	resolution.DisplayName = person.DisplayName
+	resolution.OrgID = person.OrgID
+	switch {
+	case person.LoginEnabled != nil && !*person.LoginEnabled:
+		resolution.Status = personDeactivated
+	case app.isExternal(person.OrgID):
+		resolution.Status = personExternal
+	default:
+		resolution.Status = personFound
+	}
+}
+
+// lookupPerson finds a person by email address or ID. It returns nil
+// without an error when no one has the email address.
+func (app *Application) lookupPerson(ref string) (*personDetails, error) {
+	ref = strings.TrimSpace(ref)
+	if !strings.Contains(ref, "@") {
+		personID, err := webexid.Parse(ref, webexid.People)
+		if err != nil {
+			return nil, err
+		}
+		person := &personDetails{}
+		if err := app.apiGet("people/"+personID, nil, person); err != nil {
+			return nil, err
+		}
+		return person, nil
+	}
+
+	page := struct {
+		Items []personDetails `json:"items"`
+	}{}
+	params := url.Values{"email": {ref}}
+	if err := app.apiGet("people", params, &page); err != nil {
+		return nil, err
+	}
+	if len(page.Items) == 0 {
+		return nil, nil
+	}
+	return &page.Items[0], nil
+}
+
+func (app *Application) searchPeople(name string, max int) ([]personDetails, error) {
+	page := struct {
+		Items []personDetails `json:"items"`
+	}{}
+	params := url.Values{"displayName": {name}, "max": {strconv.Itoa(max)}}
+	if err := app.apiRequest(http.MethodGet, "people", params, nil, &page); err != nil {
+		return nil, err
+	}
+	return page.Items, nil
+}
+
+func normalizeEmail(address string) string {
+	return strings.ToLower(strings.TrimSpace(address))
+}
@@ -0,0 +1,148 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	webex "github.com/WebexCommunity/webex-go-sdk/v2"
+	"github.com/WebexCommunity/webex-go-sdk/v2/people"
+	"github.com/WebexCommunity/webex-go-sdk/v2/webexsdk"
+)
+
+// newTestPeopleApp returns an application whose client talks to a fake
+// people API knowing the given people by email
+func newTestPeopleApp(t *testing.T, known map[string]personDetails) *Application {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		items := []personDetails{}
+		if person, ok := known[r.URL.Query().Get("email")]; ok {
+			items = append(items, person)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"items": items})
+	}))
+	t.Cleanup(server.Close)
+
+	client, err := webex.NewClient("test-token", &webexsdk.Config{BaseURL: server.URL, MaxRetries: 0})
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	return &Application{Client: client, Me: &people.Person{ID: "me", OrgID: "org1"}}
+}
+
+func TestAnnotateResolution(t *testing.T) {
+	app := &Application{Me: &people.Person{OrgID: "org1"}}
+	disabled := false
+
+	tests := []struct {
+		name     string
+		person   *personDetails
+		expected string
+	}{
+		{"missing", nil, personNotFound},
+		{"same org", &personDetails{Person: people.Person{ID: "p1", OrgID: "org1"}}, personFound},
+		{"other org", &personDetails{Person: people.Person{ID: "p2", OrgID: "org2"}}, personExternal},
+		{"login disabled", &personDetails{Person: people.Person{ID: "p3", OrgID: "org1"}, LoginEnabled: &disabled}, personDeactivated},
+	}
+	for _, tt := range tests {
+		resolution := &personResolution{}
+		app.annotateResolution(resolution, tt.person)
+		if resolution.Status != tt.expected {
+			t.Errorf("%s: expected status %q, got %q", tt.name, tt.expected, resolution.Status)
+		}
+	}
+
+	if !(&personResolution{Status: personExternal}).resolvable() {
+		t.Error("Expected external people to be resolvable")
+	}
+	if (&personResolution{Status: personDeactivated}).resolvable() {
+		t.Error("Expected deactivated people not to be resolvable")
+	}
+}
+
+func TestResolvePeopleCSV(t *testing.T) {
+	app := newTestPeopleApp(t, map[string]personDetails{
+		"user1@example.com": {Person: people.Person{ID: "p1", DisplayName: "User One", OrgID: "org1"}},
+		"guest@other.com":   {Person: people.Person{ID: "p2", DisplayName: "Guest", OrgID: "org2"}},
+	})
+
+	in := "name,Email\nOne,User1@example.com\nGuest,guest@other.com\nNobody,nobody@example.com\n"
+	var out bytes.Buffer
+	if err := app.ResolvePeopleCSV(strings.NewReader(in), &out); err != nil {
+		t.Fatalf("ResolvePeopleCSV() error = %v", err)
+	}
+	expected := "name,Email,personId,displayName,orgId,status\n" +
+		"One,User1@example.com,p1,User One,org1,found\n" +
+		"Guest,guest@other.com,p2,Guest,org2,external\n" +
+		"Nobody,nobody@example.com,,,,not found\n"
+	if out.String() != expected {
+		t.Errorf("ResolvePeopleCSV() =\n%s\nwant\n%s", out.String(), expected)
+	}
+
+	if err := app.ResolvePeopleCSV(strings.NewReader("name\nOne\n"), &out); err == nil {
+		t.Error("Expected an error for a CSV without an email column")
+	}
+}
+
+func TestResolvePeopleLookupErrors(t *testing.T) {
+	var limited atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Query().Get("email") {
+		case "limited@example.com":
+			if limited.CompareAndSwap(false, true) {
+				w.Header().Set("Retry-After", "1")
+				w.WriteHeader(http.StatusTooManyRequests)
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"items": []personDetails{{Person: people.Person{ID: "p1", OrgID: "org1"}}}})
+		case "broken@example.com":
+			w.WriteHeader(http.StatusInternalServerError)
+		case "gone@example.com":
+			disabled := false
+			json.NewEncoder(w).Encode(map[string]interface{}{"items": []personDetails{{Person: people.Person{ID: "p2", OrgID: "org1"}, LoginEnabled: &disabled}}})
+		default:
+			json.NewEncoder(w).Encode(map[string]interface{}{"items": []personDetails{}})
+		}
+	}))
+	defer server.Close()
+	client, err := webex.NewClient("test-token", &webexsdk.Config{BaseURL: server.URL, MaxRetries: 1, RetryBaseDelay: time.Millisecond})
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	app := &Application{Client: client, Me: &people.Person{ID: "me", OrgID: "org1"}}
+
+	csvPath := filepath.Join(t.TempDir(), "people.csv")
+	members := "email,moderator\nlimited@example.com,false\nbroken@example.com,false\ngone@example.com,false\nnobody@example.com,false\n"
+	if err := os.WriteFile(csvPath, []byte(members), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	started := time.Now()
+	resolved := app.resolvePeople([]string{"limited@example.com", "broken@example.com"})
+	if resolved["limited@example.com"].Status != personFound {
+		t.Errorf("Expected a rate limited lookup to be retried, got %+v", resolved["limited@example.com"])
+	}
+	if time.Since(started) < time.Second {
+		t.Error("Expected the retry to wait for Retry-After")
+	}
+	if resolved["broken@example.com"].Status != personLookupError {
+		t.Errorf("Expected a failed lookup to be an error, got %+v", resolved["broken@example.com"])
+	}
+
+	limited.Store(false)
+	addApp := &AddPeopleApplication{Application: app, PeopleCSVPath: csvPath}
+	if err := addApp.validateMembers(); err != nil {
+		t.Fatalf("validateMembers() error = %v", err)
+	}
+	if len(addApp.Skip) != 2 || !addApp.Skip["gone@example.com"] || !addApp.Skip["nobody@example.com"] {
+		t.Errorf("Expected only deactivated and unknown people to be skipped, got %v", addApp.Skip)
+	}
+}
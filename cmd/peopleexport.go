@@ -0,0 +1,340 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/urfave/cli/v2"
+)
+
+// Formats of people export
+const (
+	directoryFormatCSV   = "csv"
+	directoryFormatJSONL = "jsonl"
+)
+
+// Changes of people export --since
+const (
+	directoryNew       = "new"
+	directoryModified  = "modified"
+	directoryUnchanged = "unchanged"
+	directoryRemoved   = "removed"
+)
+
+// directoryPerson is a person of the organization as listed by the API
+type directoryPerson struct {
+	ID           string     `json:"id"`
+	Emails       []string   `json:"emails"`
+	DisplayName  string     `json:"displayName"`
+	Department   string     `json:"department,omitempty"`
+	Title        string     `json:"title,omitempty"`
+	Status       string     `json:"status,omitempty"`
+	Created      *time.Time `json:"created,omitempty"`
+	LastActivity *time.Time `json:"lastActivity,omitempty"`
+	LastModified *time.Time `json:"lastModified,omitempty"`
+	Licenses     []string   `json:"licenses,omitempty"`
+	Roles        []string   `json:"roles,omitempty"`
+}
+
+// exportedPerson is a row of people export
+type exportedPerson struct {
+	ID           string `json:"id"`
+	Email        string `json:"email"`
+	DisplayName  string `json:"displayName"`
+	Department   string `json:"department"`
+	Title        string `json:"title"`
+	Status       string `json:"status"`
+	Created      string `json:"created"`
+	LastActivity string `json:"lastActivity"`
+	LastModified string `json:"lastModified"`
+	Licenses     int    `json:"licenses"`
+	Roles        int    `json:"roles"`
+	// Change compares the person to the --since export
+	Change string `json:"change,omitempty"`
+}
+
+var exportedPersonHeader = []string{"id", "email", "displayName", "department", "title", "status", "created", "lastActivity", "lastModified", "licenses", "roles"}
+
+const exportedPersonChangeColumn = "change"
+
+func newExportedPerson(person directoryPerson) exportedPerson {
+	email := ""
+	if len(person.Emails) > 0 {
+		email = person.Emails[0]
+	}
+	return exportedPerson{
+		ID:           person.ID,
+		Email:        email,
+		DisplayName:  person.DisplayName,
+		Department:   person.Department,
+		Title:        person.Title,
+		Status:       person.Status,
+		Created:      formatReportTime(person.Created),
+		LastActivity: formatReportTime(person.LastActivity),
+		LastModified: formatReportTime(person.LastModified),
+		Licenses:     len(person.Licenses),
+		Roles:        len(person.Roles),
+	}
+}
+
+func (person exportedPerson) record(withChange bool) []string {
+	record := []string{
+		person.ID,
+		person.Email,
+		person.DisplayName,
+		person.Department,
+		person.Title,
+		person.Status,
+		person.Created,
+		person.LastActivity,
+		person.LastModified,
+		strconv.Itoa(person.Licenses),
+		strconv.Itoa(person.Roles),
+	}
+	if withChange {
+		record = append(record, person.Change)
+	}
+	return record
+}
+
+// ExportOrgPeopleCMD function
+func (app *Application) ExportOrgPeopleCMD() *cli.Command {
+	return &cli.Command{
+		Name:        "export",
+		Aliases:     []string{"e"},
+		Description: "Export everyone in the organization. Requires an administrator token",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:     "org",
+				Value:    false,
+				Usage:    "Export the whole organization",
+				Required: true,
+			},
+			&cli.StringFlag{
+				Name:     "orgID",
+				Aliases:  []string{"oid"},
+				Value:    "",
+				Usage:    "ID of the organization to export. Defaults to your organization",
+				Required: false,
+			},
+			&cli.StringFlag{
+				Name:     "format",
+				Aliases:  []string{"fmt"},
+				Value:    directoryFormatCSV,
+				Usage:    "Output format, one of csv or jsonl. Default is csv",
+				Required: false,
+			},
+			&cli.StringFlag{
+				Name:     "out",
+				Aliases:  []string{"o"},
+				Value:    "",
+				Usage:    "Path of the export. Defaults to stdout",
+				Required: false,
+			},
+			&cli.StringFlag{
+				Name:     "since",
+				Aliases:  []string{"s"},
+				Value:    "",
+				Usage:    "Path of a previous export. A change column tells whether each person is new, modified or unchanged since, and people no longer listed are added as removed. The export can be the --since of the next run",
+				Required: false,
+			},
+			limitFlag(),
+		},
+		Action: func(c *cli.Context) error {
+			format := c.String("format")
+			if format != directoryFormatCSV && format != directoryFormatJSONL {
+				return errors.New("Allowed values for format flag are csv and jsonl")
+			}
+			orgID := c.String("orgID")
+			if orgID == "" && app.Me != nil {
+				orgID = app.Me.OrgID
+			}
+
+			var previous map[string]string
+			if since := c.String("since"); since != "" {
+				var err error
+				if previous, err = loadPreviousExport(since); err != nil {
+					return err
+				}
+			}
+
+			var out io.Writer = os.Stdout
+			if outPath := c.String("out"); outPath != "" {
+				outFile, err := os.Create(outPath)
+				if err != nil {
+					return err
+				}
+				defer outFile.Close()
+				out = outFile
+			}
+
+			count, err := app.ExportOrgPeople(orgID, out, format, previous, c.Int("limit"))
+			if err != nil {
+				return err
+			}
+			log.Infof("Exported %d people", count)
+			return nil
+		},
+	}
+}
+
+// ExportOrgPeople streams the people of an organization to w as the pages
+// are read, stopping after limit people when limit is greater than 0. When
+// previous is set, each person's change since the previous export is
+// written along, followed by the people of the previous export who are no
+// longer listed. It returns the number of rows written.
+func (app *Application) ExportOrgPeople(orgID string, w io.Writer, format string, previous map[string]string, limit int) (int, error) {
+	write, flush := directoryWriter(w, format, previous != nil)
+	params := url.Values{}
+	if orgID != "" {
+		params.Set("orgId", orgID)
+	}
+	params.Set("max", "1000")
+
+	count := 0
+	listed := make(map[string]bool)
+	err := eachItem(app, "people", params, limit, func(person directoryPerson) error {
+		exported := newExportedPerson(person)
+		if previous != nil {
+			listed[exported.ID] = true
+			exported.Change = directoryNew
+			if lastModified, ok := previous[exported.ID]; ok {
+				exported.Change = directoryModified
+				if lastModified == exported.LastModified {
+					exported.Change = directoryUnchanged
+				}
+			}
+		}
+		if err := write(exported); err != nil {
+			return err
+		}
+		count++
+		return nil
+	})
+	if err != nil {
+		flush()
+		return count, err
+	}
+
+	// A limited export does not list everyone, so nobody can be told removed
+	if previous != nil && limit <= 0 {
+		removed := make([]string, 0)
+		for personID := range previous {
+			if !listed[personID] {
+				removed = append(removed, personID)
+			}
+		}
+		sort.Strings(removed)
+		for _, personID := range removed {
+			if err := write(exportedPerson{ID: personID, Change: directoryRemoved}); err != nil {
+				flush()
+				return count, err
+			}
+			count++
+		}
+	}
+	return count, flush()
+}
+
+// directoryWriter returns functions writing exported people to w in format.
+// The CSV header is written before the first row, with a change column when
+// withChange is set.
+func directoryWriter(w io.Writer, format string, withChange bool) (func(exportedPerson) error, func() error) {
+	if format == directoryFormatJSONL {
+		encoder := json.NewEncoder(w)
+		return func(person exportedPerson) error {
+			return encoder.Encode(person)
+		}, func() error { return nil }
+	}
+
+	csvWriter := csv.NewWriter(w)
+	headerWritten := false
+	writeHeader := func() {
+		if !headerWritten {
+			header := exportedPersonHeader
+			if withChange {
+				header = append(header[:len(header):len(header)], exportedPersonChangeColumn)
+			}
+			csvWriter.Write(header)
+			headerWritten = true
+		}
+	}
+	return func(person exportedPerson) error {
+			writeHeader()
+			return csvWriter.Write(person.record(withChange))
+		}, func() error {
+			writeHeader()
+			csvWriter.Flush()
+			return csvWriter.Error()
+		}
+}
+
+// loadPreviousExport reads the id and lastModified columns of a CSV or JSONL
+// export. People an incremental export lists as removed are left out.
+func loadPreviousExport(exportPath string) (map[string]string, error) {
+	file, err := os.Open(exportPath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return readPreviousExport(file, strings.EqualFold(filepath.Ext(exportPath), "."+directoryFormatJSONL))
+}
+
+func readPreviousExport(r io.Reader, jsonl bool) (map[string]string, error) {
+	previous := make(map[string]string)
+	if jsonl {
+		decoder := json.NewDecoder(r)
+		for {
+			var person exportedPerson
+			if err := decoder.Decode(&person); err == io.EOF {
+				return previous, nil
+			} else if err != nil {
+				return nil, err
+			}
+			if person.Change != directoryRemoved {
+				previous[person.ID] = person.LastModified
+			}
+		}
+	}
+
+	records, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return previous, nil
+	}
+	idColumn, modifiedColumn, changeColumn := -1, -1, -1
+	for i, column := range records[0] {
+		switch column {
+		case "id":
+			idColumn = i
+		case "lastModified":
+			modifiedColumn = i
+		case exportedPersonChangeColumn:
+			changeColumn = i
+		}
+	}
+	if idColumn < 0 || modifiedColumn < 0 {
+		return nil, errors.New("previous export has no id and lastModified columns")
+	}
+	for _, record := range records[1:] {
+		if changeColumn >= 0 && changeColumn < len(record) && record[changeColumn] == directoryRemoved {
+			continue
+		}
+		if idColumn < len(record) && modifiedColumn < len(record) {
+			previous[record[idColumn]] = record[modifiedColumn]
+		}
+	}
+	return previous, nil
+}
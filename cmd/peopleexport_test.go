@@ -0,0 +1,151 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	webex "github.com/WebexCommunity/webex-go-sdk/v2"
+	"github.com/WebexCommunity/webex-go-sdk/v2/webexsdk"
+)
+
+// newTestDirectoryApp serves two pages of people linked by a Link header
+func newTestDirectoryApp(t *testing.T) *Application {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Query().Get("orgId") != "org1" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if r.URL.Query().Get("cursor") == "" {
+			w.Header().Set("Link", fmt.Sprintf(`<%s/people?orgId=org1&max=1000&cursor=2>; rel="next"`, server.URL))
+			fmt.Fprint(w, `{"items":[
+				{"id":"p1","emails":["a@example.com"],"displayName":"A","department":"Sales","title":"Rep","status":"active","created":"2020-01-01T00:00:00Z","lastModified":"2024-01-01T00:00:00Z","licenses":["l1","l2"],"roles":["r1"]},
+				{"id":"p2","emails":["b@example.com"],"displayName":"B","lastModified":"2024-02-01T00:00:00Z"}]}`)
+			return
+		}
+		fmt.Fprint(w, `{"items":[{"id":"p3","emails":["c@example.com"],"displayName":"C","lastModified":"2024-03-01T00:00:00Z"}]}`)
+	}))
+	t.Cleanup(server.Close)
+
+	client, err := webex.NewClient("test-token", &webexsdk.Config{BaseURL: server.URL, MaxRetries: 0})
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	return &Application{Client: client}
+}
+
+func TestExportOrgPeopleCSV(t *testing.T) {
+	app := newTestDirectoryApp(t)
+	var out bytes.Buffer
+	count, err := app.ExportOrgPeople("org1", &out, directoryFormatCSV, nil, 0)
+	if err != nil {
+		t.Fatalf("ExportOrgPeople() error = %v", err)
+	}
+	if count != 3 {
+		t.Errorf("Expected 3 people across both pages, got %d", count)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("Expected a header and 3 rows, got %q", out.String())
+	}
+	if lines[0] != strings.Join(exportedPersonHeader, ",") {
+		t.Errorf("Unexpected header %q", lines[0])
+	}
+	if lines[1] != "p1,a@example.com,A,Sales,Rep,active,2020-01-01T00:00:00Z,,2024-01-01T00:00:00Z,2,1" {
+		t.Errorf("Unexpected first row %q", lines[1])
+	}
+}
+
+func TestExportOrgPeopleSince(t *testing.T) {
+	app := newTestDirectoryApp(t)
+	var previousExport bytes.Buffer
+	if _, err := app.ExportOrgPeople("org1", &previousExport, directoryFormatJSONL, nil, 0); err != nil {
+		t.Fatalf("ExportOrgPeople() error = %v", err)
+	}
+	previous, err := readPreviousExport(strings.NewReader(previousExport.String()), true)
+	if err != nil {
+		t.Fatalf("readPreviousExport() error = %v", err)
+	}
+	// p2 changed since the previous export, p3 is new and p9 left
+	previous["p2"] = "2023-12-01T00:00:00Z"
+	delete(previous, "p3")
+	previous["p9"] = "2023-01-01T00:00:00Z"
+
+	var out bytes.Buffer
+	count, err := app.ExportOrgPeople("org1", &out, directoryFormatJSONL, previous, 0)
+	if err != nil {
+		t.Fatalf("ExportOrgPeople() error = %v", err)
+	}
+	if count != 4 {
+		t.Fatalf("Expected 4 people, got %d: %s", count, out.String())
+	}
+	decoder := json.NewDecoder(&out)
+	for _, expected := range []string{"p1 unchanged", "p2 modified", "p3 new", "p9 removed"} {
+		var person exportedPerson
+		if err := decoder.Decode(&person); err != nil {
+			t.Fatalf("Decode() error = %v", err)
+		}
+		if got := person.ID + " " + person.Change; got != expected {
+			t.Errorf("Expected %s, got %s", expected, got)
+		}
+	}
+}
+
+func TestExportOrgPeopleSinceChained(t *testing.T) {
+	app := newTestDirectoryApp(t)
+	previous := map[string]string{"p1": "2024-01-01T00:00:00Z", "p9": "2023-01-01T00:00:00Z"}
+	var first bytes.Buffer
+	if _, err := app.ExportOrgPeople("org1", &first, directoryFormatCSV, previous, 0); err != nil {
+		t.Fatalf("ExportOrgPeople() error = %v", err)
+	}
+	if header := strings.SplitN(first.String(), "\n", 2)[0]; !strings.HasSuffix(header, ",roles,change") {
+		t.Errorf("Expected a change column, got header %s", header)
+	}
+
+	// The incremental export is a full snapshot, so it is the next baseline
+	baseline, err := readPreviousExport(strings.NewReader(first.String()), false)
+	if err != nil {
+		t.Fatalf("readPreviousExport() error = %v", err)
+	}
+	if _, ok := baseline["p9"]; ok {
+		t.Error("Expected people listed as removed to be left out of the baseline")
+	}
+	var second bytes.Buffer
+	count, err := app.ExportOrgPeople("org1", &second, directoryFormatCSV, baseline, 0)
+	if err != nil {
+		t.Fatalf("ExportOrgPeople() error = %v", err)
+	}
+	records, err := csv.NewReader(&second).ReadAll()
+	if err != nil {
+		t.Fatalf("ReadAll() error = %v", err)
+	}
+	if count != len(records)-1 {
+		t.Errorf("Expected %d rows, got %d", count, len(records)-1)
+	}
+	for _, record := range records[1:] {
+		if change := record[len(record)-1]; change != directoryUnchanged {
+			t.Errorf("Expected %s unchanged in a chained export, got %s", record[0], change)
+		}
+	}
+}
+
+func TestReadPreviousExportCSV(t *testing.T) {
+	csvExport := strings.Join(exportedPersonHeader, ",") + "\np1,a@example.com,A,,,,,,2024-01-01T00:00:00Z,0,0\n"
+	previous, err := readPreviousExport(strings.NewReader(csvExport), false)
+	if err != nil {
+		t.Fatalf("readPreviousExport() error = %v", err)
+	}
+	if previous["p1"] != "2024-01-01T00:00:00Z" {
+		t.Errorf("Unexpected previous export %v", previous)
+	}
+	if _, err := readPreviousExport(strings.NewReader("email\na@example.com\n"), false); err == nil {
+		t.Error("Expected an error for a CSV without id and lastModified columns")
+	}
+}
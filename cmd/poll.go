@@ -0,0 +1,686 @@
+package cmd
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/WebexCommunity/webex-go-sdk/v2/attachmentactions"
+	"github.com/WebexCommunity/webex-go-sdk/v2/conversation"
+	"github.com/WebexCommunity/webex-go-sdk/v2/messages"
+	"github.com/WebexCommunity/webex-go-sdk/v2/people"
+	log "github.com/sirupsen/logrus"
+	"github.com/tejzpr/webex-teams-cli/cmd/webexid"
+	"github.com/urfave/cli/v2"
+)
+
+const pollStateFile = "polls.json"
+
+// pollBarWidth is the number of characters of a full result bar
+const pollBarWidth = 20
+
+var pollHeader = []string{"id", "question", "roomId", "votes", "closesAt", "closed"}
+
+// Poll is a question posted as an Adaptive Card. Votes are keyed by person
+// ID so each person has one vote, replaced when they vote again. Anonymous
+// polls key them by a hash of the person ID salted per poll instead.
+type Poll struct {
+	ID               string               `json:"id"`
+	RoomID           string               `json:"roomId"`
+	Question         string               `json:"question"`
+	Options          []string             `json:"options"`
+	Multi            bool                 `json:"multi"`
+	Anonymous        bool                 `json:"anonymous"`
+	Live             bool                 `json:"live"`
+	MessageID        string               `json:"messageId"`
+	ResultsMessageID string               `json:"resultsMessageId,omitempty"`
+	Created          time.Time            `json:"created"`
+	ClosesAt         time.Time            `json:"closesAt"`
+	Closed           bool                 `json:"closed"`
+	Votes            map[string]*pollVote `json:"votes"`
+	VoterSalt        string               `json:"voterSalt,omitempty"`
+}
+
+// pollListItem is a poll as listed, without its votes
+type pollListItem struct {
+	ID        string    `json:"id"`
+	Question  string    `json:"question"`
+	RoomID    string    `json:"roomId"`
+	Anonymous bool      `json:"anonymous"`
+	Votes     int       `json:"votes"`
+	ClosesAt  time.Time `json:"closesAt"`
+	Closed    bool      `json:"closed"`
+}
+
+// pollVote is the latest vote of a person
+type pollVote struct {
+	PersonEmail string    `json:"personEmail,omitempty"`
+	Choices     []int     `json:"choices"`
+	At          time.Time `json:"at"`
+}
+
+// pollStore is the persisted set of polls by ID
+type pollStore struct {
+	Polls map[string]*Poll `json:"polls"`
+}
+
+// PollCMD function
+func (app *Application) PollCMD() *cli.Command {
+	return &cli.Command{
+		Name:    "poll",
+		Aliases: []string{"pl"},
+		Usage:   "Run polls with Adaptive Cards",
+		Subcommands: []*cli.Command{
+			app.pollCreateCMD(),
+			app.pollResumeCMD(),
+			app.pollCloseCMD(),
+			app.pollListCMD(),
+		},
+		Action: func(c *cli.Context) error {
+			return nil
+		},
+	}
+}
+
+func (app *Application) pollCreateCMD() *cli.Command {
+	return &cli.Command{
+		Name:        "create",
+		Aliases:     []string{"c"},
+		Description: "Post a poll card to a room, collect votes until it closes and post the results in the card's thread",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:     "roomID",
+				Aliases:  []string{"room", "rid"},
+				Value:    "",
+				Usage:    "Webex room ID to post the poll to",
+				Required: true,
+				EnvVars:  []string{"WEBEX_ROOM_ID"},
+			},
+			&cli.StringFlag{
+				Name:     "question",
+				Aliases:  []string{"q"},
+				Value:    "",
+				Usage:    "Question of the poll",
+				Required: true,
+			},
+			&cli.StringSliceFlag{
+				Name:     "option",
+				Aliases:  []string{"opt"},
+				Usage:    "An answer people can vote for. Repeat for each option or separate them with commas",
+				Required: true,
+			},
+			&cli.BoolFlag{
+				Name:     "multi",
+				Value:    false,
+				Usage:    "Allow voting for more than one option",
+				Required: false,
+			},
+			&cli.BoolFlag{
+				Name:     "anonymous",
+				Value:    false,
+				Usage:    "Leave out who voted for what from the results",
+				Required: false,
+			},
+			&cli.BoolFlag{
+				Name:     "live",
+				Value:    false,
+				Usage:    "Post the results right away and update them on every vote",
+				Required: false,
+			},
+			&cli.DurationFlag{
+				Name:     "close-in",
+				Aliases:  []string{"ci"},
+				Value:    time.Hour,
+				Usage:    "How long the poll stays open. Default is 1h",
+				Required: false,
+			},
+		},
+		Action: func(c *cli.Context) error {
+			roomID, err := app.parseRoomID(c.String("roomID"))
+			if err != nil {
+				return err
+			}
+			closeIn := c.Duration("close-in")
+			if closeIn <= 0 {
+				return errors.New("The close-in duration should be greater than 0")
+			}
+			options, err := parsePollOptions(c.StringSlice("option"))
+			if err != nil {
+				return err
+			}
+			id, err := newStateID()
+			if err != nil {
+				return err
+			}
+			now := time.Now()
+			poll := &Poll{
+				ID:        id,
+				RoomID:    roomID,
+				Question:  c.String("question"),
+				Options:   options,
+				Multi:     c.Bool("multi"),
+				Anonymous: c.Bool("anonymous"),
+				Live:      c.Bool("live"),
+				Created:   now,
+				ClosesAt:  now.Add(closeIn),
+				Votes:     make(map[string]*pollVote),
+			}
+			if poll.Anonymous {
+				poll.VoterSalt = rand.Text()
+			}
+			if err := app.PostPoll(poll); err != nil {
+				return err
+			}
+			log.Infof("Posted poll %s, it closes at %s", poll.ID, poll.ClosesAt.Format(time.RFC3339))
+			return app.runPoll(poll.ID)
+		},
+	}
+}
+
+func (app *Application) pollResumeCMD() *cli.Command {
+	return &cli.Command{
+		Name:        "resume",
+		Aliases:     []string{"r"},
+		Usage:       "resume <pollID>",
+		Description: "Collect votes of an open poll again, eg. after a restart, and close it when due. Votes made while nothing was collecting are not counted",
+		Action: func(c *cli.Context) error {
+			if c.Args().Len() != 1 {
+				return errors.New("a poll ID is required")
+			}
+			return app.runPoll(c.Args().First())
+		},
+	}
+}
+
+func (app *Application) pollCloseCMD() *cli.Command {
+	return &cli.Command{
+		Name:        "close",
+		Usage:       "close <pollID>",
+		Description: "Close a poll now and post its results",
+		Action: func(c *cli.Context) error {
+			if c.Args().Len() != 1 {
+				return errors.New("a poll ID is required")
+			}
+			return app.ClosePoll(c.Args().First())
+		},
+	}
+}
+
+func (app *Application) pollListCMD() *cli.Command {
+	return &cli.Command{
+		Name:        "list",
+		Aliases:     []string{"ls"},
+		Description: "List the polls in the state directory",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:     "output",
+				Aliases:  []string{"o"},
+				Value:    outputTable,
+				Usage:    "Output format, one of table, csv or json. Default is table",
+				Required: false,
+			},
+		},
+		Action: func(c *cli.Context) error {
+			format := c.String("output")
+			if err := validateOutputFormat(format); err != nil {
+				return err
+			}
+			store, err := app.loadPolls()
+			if err != nil {
+				return err
+			}
+			polls := make([]*Poll, 0, len(store.Polls))
+			for _, poll := range store.Polls {
+				polls = append(polls, poll)
+			}
+			sort.Slice(polls, func(i, j int) bool {
+				return polls[i].Created.Before(polls[j].Created)
+			})
+			rows := make([][]string, 0, len(polls))
+			items := make([]pollListItem, 0, len(polls))
+			for _, poll := range polls {
+				rows = append(rows, []string{poll.ID, poll.Question, poll.RoomID, strconv.Itoa(len(poll.Votes)), poll.ClosesAt.Format(time.RFC3339), strconv.FormatBool(poll.Closed)})
+				items = append(items, pollListItem{
+					ID:        poll.ID,
+					Question:  poll.Question,
+					RoomID:    poll.RoomID,
+					Anonymous: poll.Anonymous,
+					Votes:     len(poll.Votes),
+					ClosesAt:  poll.ClosesAt,
+					Closed:    poll.Closed,
+				})
+			}
+			return writeOutput(os.Stdout, format, pollHeader, rows, items)
+		},
+	}
+}
+
+// parsePollOptions trims the options and checks there are at least two
+// distinct ones
+func parsePollOptions(raw []string) ([]string, error) {
+	options := make([]string, 0, len(raw))
+	seen := make(map[string]bool)
+	for _, option := range raw {
+		option = strings.TrimSpace(option)
+		if option == "" {
+			continue
+		}
+		if seen[strings.ToLower(option)] {
+			return nil, fmt.Errorf("Option %s is given more than once", option)
+		}
+		seen[strings.ToLower(option)] = true
+		options = append(options, option)
+	}
+	if len(options) < 2 {
+		return nil, errors.New("At least two options are required")
+	}
+	return options, nil
+}
+
+// pollCard builds the Adaptive Card of a poll. Choices carry the option
+// index as value, since multi-select submissions join values with commas.
+func (poll *Poll) pollCard() map[string]interface{} {
+	choices := make([]interface{}, 0, len(poll.Options))
+	for i, option := range poll.Options {
+		choices = append(choices, map[string]interface{}{
+			"title": option,
+			"value": strconv.Itoa(i),
+		})
+	}
+	hint := "Pick one option. You can change your vote until the poll closes"
+	if poll.Multi {
+		hint = "Pick one or more options. You can change your vote until the poll closes"
+	}
+	if poll.Anonymous {
+		hint += ". Votes are anonymous"
+	}
+
+	return map[string]interface{}{
+		"$schema": "http://adaptivecards.io/schemas/adaptive-card.json",
+		"type":    "AdaptiveCard",
+		"version": "1.3",
+		"body": []interface{}{
+			map[string]interface{}{
+				"type":   "TextBlock",
+				"text":   poll.Question,
+				"size":   "Medium",
+				"weight": "Bolder",
+				"wrap":   true,
+			},
+			map[string]interface{}{
+				"type":          "Input.ChoiceSet",
+				"id":            "choice",
+				"style":         "expanded",
+				"isMultiSelect": poll.Multi,
+				"choices":       choices,
+			},
+			map[string]interface{}{
+				"type":     "TextBlock",
+				"text":     hint,
+				"size":     "Small",
+				"isSubtle": true,
+				"wrap":     true,
+			},
+		},
+		"actions": []interface{}{
+			map[string]interface{}{
+				"type":  "Action.Submit",
+				"title": "Vote",
+				"data":  map[string]interface{}{"pollId": poll.ID},
+			},
+		},
+	}
+}
+
+// choicesFromInputs returns the option indexes submitted with the card.
+// Anything out of range is dropped, and only the first choice is kept when
+// the poll is not multiple choice.
+func (poll *Poll) choicesFromInputs(inputs map[string]interface{}) []int {
+	value, _ := inputs["choice"].(string)
+	choices := make([]int, 0)
+	seen := make(map[int]bool)
+	for _, part := range strings.Split(value, ",") {
+		index, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil || index < 0 || index >= len(poll.Options) || seen[index] {
+			continue
+		}
+		seen[index] = true
+		choices = append(choices, index)
+		if !poll.Multi {
+			break
+		}
+	}
+	sort.Ints(choices)
+	return choices
+}
+
+// vote records the vote of a person, replacing an earlier one. Votes
+// without a valid choice are ignored.
+func (poll *Poll) vote(personID string, personEmail string, choices []int, at time.Time) bool {
+	if poll.Closed || len(choices) == 0 {
+		return false
+	}
+	if poll.Votes == nil {
+		poll.Votes = make(map[string]*pollVote)
+	}
+	if poll.Anonymous {
+		personEmail = ""
+		// Polls saved before votes were hashed keep the person ID as key
+		delete(poll.Votes, personID)
+	}
+	poll.Votes[poll.voterKey(personID)] = &pollVote{PersonEmail: personEmail, Choices: choices, At: at}
+	return true
+}
+
+// voterKey returns the key of the votes of a person. For anonymous polls it
+// is a hash of the person ID with the poll's salt, so the state file does
+// not tell who voted.
+func (poll *Poll) voterKey(personID string) string {
+	if !poll.Anonymous {
+		return personID
+	}
+	if poll.VoterSalt == "" {
+		poll.VoterSalt = rand.Text()
+	}
+	sum := sha256.Sum256([]byte(poll.VoterSalt + personID))
+	return hex.EncodeToString(sum[:])
+}
+
+// results renders the tallies of a poll as markdown with a bar per option
+func (poll *Poll) results() string {
+	counts := make([]int, len(poll.Options))
+	voters := make([][]string, len(poll.Options))
+	for _, vote := range poll.Votes {
+		for _, choice := range vote.Choices {
+			if choice < len(counts) {
+				counts[choice]++
+				if vote.PersonEmail != "" {
+					voters[choice] = append(voters[choice], vote.PersonEmail)
+				}
+			}
+		}
+	}
+
+	width := 0
+	for _, option := range poll.Options {
+		if len(option) > width {
+			width = len(option)
+		}
+	}
+
+	var sb strings.Builder
+	state := "Poll results"
+	if poll.Closed {
+		state = "Poll closed"
+	}
+	sb.WriteString(fmt.Sprintf("**%s: %s**\n", state, poll.Question))
+	sb.WriteString("```\n")
+	for i, option := range poll.Options {
+		percent := 0
+		filled := 0
+		if len(poll.Votes) > 0 {
+			percent = counts[i] * 100 / len(poll.Votes)
+			filled = counts[i] * pollBarWidth / len(poll.Votes)
+		}
+		bar := strings.Repeat("█", filled) + strings.Repeat("░", pollBarWidth-filled)
+		sb.WriteString(fmt.Sprintf("%-*s %s %d (%d%%)\n", width, option, bar, counts[i], percent))
+	}
+	sb.WriteString("```\n")
+	votes := "votes"
+	if len(poll.Votes) == 1 {
+		votes = "vote"
+	}
+	sb.WriteString(fmt.Sprintf("%d %s", len(poll.Votes), votes))
+
+	if !poll.Anonymous {
+		for i, option := range poll.Options {
+			if len(voters[i]) == 0 {
+				continue
+			}
+			sort.Strings(voters[i])
+			sb.WriteString(fmt.Sprintf("\n- %s: %s", option, strings.Join(voters[i], ", ")))
+		}
+	}
+	return sb.String()
+}
+
+// PostPoll posts the poll card, and the initial results for live polls, and
+// saves the poll
+func (app *Application) PostPoll(poll *Poll) error {
+	fallback := fmt.Sprintf("Poll: %s", poll.Question)
+	sentCard, err := app.Client.Messages().CreateWithAdaptiveCard(&messages.Message{RoomID: poll.RoomID}, messages.NewAdaptiveCard(poll.pollCard()), fallback)
+	if err != nil {
+		return err
+	}
+	poll.MessageID = sentCard.ID
+	if poll.Live {
+		if err := app.publishPollResults(poll); err != nil {
+			log.Errorf("Failed to post poll results: %s", err.Error())
+		}
+	}
+	return app.updatePolls(func(store *pollStore) error {
+		store.Polls[poll.ID] = poll
+		return nil
+	})
+}
+
+// publishPollResults posts the results in the card's thread, or updates the
+// results message posted before
+func (app *Application) publishPollResults(poll *Poll) error {
+	if poll.ResultsMessageID != "" {
+		_, err := app.Client.Messages().Update(poll.ResultsMessageID, &messages.Message{
+			RoomID:   poll.RoomID,
+			Markdown: poll.results(),
+		})
+		return err
+	}
+	resultsMessage, err := app.Client.Messages().Create(&messages.Message{
+		RoomID:   poll.RoomID,
+		ParentID: poll.MessageID,
+		Markdown: poll.results(),
+	})
+	if err != nil {
+		return err
+	}
+	poll.ResultsMessageID = resultsMessage.ID
+	return nil
+}
+
+// ClosePoll marks a poll closed and publishes its final results
+func (app *Application) ClosePoll(pollID string) error {
+	return app.updatePolls(func(store *pollStore) error {
+		poll, ok := store.Polls[pollID]
+		if !ok {
+			return fmt.Errorf("No poll with ID %s", pollID)
+		}
+		if poll.Closed {
+			return fmt.Errorf("Poll %s is already closed", pollID)
+		}
+		poll.Closed = true
+		if err := app.publishPollResults(poll); err != nil {
+			return err
+		}
+		log.Infof("Closed poll %s with %d votes", poll.ID, len(poll.Votes))
+		return nil
+	})
+}
+
+// runPoll collects the votes of an open poll until it is due or the
+// process is interrupted, then closes it when due
+func (app *Application) runPoll(pollID string) error {
+	store, err := app.loadPolls()
+	if err != nil {
+		return err
+	}
+	poll, ok := store.Polls[pollID]
+	if !ok {
+		return fmt.Errorf("No poll with ID %s", pollID)
+	}
+	if poll.Closed {
+		return fmt.Errorf("Poll %s is already closed", pollID)
+	}
+
+	if wait := time.Until(poll.ClosesAt); wait > 0 {
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		ctx, cancel := context.WithTimeout(ctx, wait)
+		defer cancel()
+		if err := app.collectVotes(ctx, poll); err != nil {
+			return err
+		}
+		if time.Now().Before(poll.ClosesAt) {
+			log.Infof("Stopped collecting votes, run poll resume %s to continue", poll.ID)
+			return nil
+		}
+	}
+	if store, err := app.loadPolls(); err == nil && store.Polls[poll.ID] != nil && store.Polls[poll.ID].Closed {
+		log.Infof("Poll %s was closed meanwhile", poll.ID)
+		return nil
+	}
+	return app.ClosePoll(poll.ID)
+}
+
+// collectVotes records votes on the poll card until ctx is done
+func (app *Application) collectVotes(ctx context.Context, poll *Poll) error {
+	conv, err := app.Client.Conversation()
+	if err != nil {
+		return err
+	}
+	collector := &pollCollector{Application: app, PollID: poll.ID, MessageID: poll.MessageID, Anonymous: poll.Anonymous, people: app.Client.People()}
+	attachmentActions := app.Client.AttachmentActions()
+	conv.On("cardAction", func(activity *conversation.Activity) {
+		actionID, err := webexid.Encode(webexid.AttachmentAction, activity.ID)
+		if err != nil {
+			log.Debugf("Invalid attachment action %s: %s", activity.ID, err.Error())
+			return
+		}
+		action, err := attachmentActions.Get(actionID)
+		if err != nil {
+			log.Debugf("Error fetching attachment action %s: %s", activity.ID, err.Error())
+			return
+		}
+		if err := collector.record(action); err != nil {
+			log.Error(err.Error())
+		}
+	})
+	if err := conv.Connect(); err != nil {
+		return err
+	}
+	defer conv.Disconnect()
+	log.Infof("Collecting votes for poll %s", poll.ID)
+	<-ctx.Done()
+	return nil
+}
+
+// pollCollector saves the votes cast on a poll card
+type pollCollector struct {
+	*Application
+	PollID    string
+	MessageID string
+	Anonymous bool
+
+	mu           sync.Mutex
+	personEmails sync.Map
+	// people is taken before handlers start, the SDK creates its clients
+	// lazily and without a lock
+	people *people.Client
+}
+
+// record saves a vote from an attachment action on the poll card, and
+// refreshes the results of live polls
+func (collector *pollCollector) record(action *attachmentactions.AttachmentAction) error {
+	if action.MessageID != collector.MessageID {
+		return nil
+	}
+	personEmail := ""
+	if !collector.Anonymous {
+		personEmail = collector.personEmail(action.PersonID)
+	}
+	at := time.Now()
+	if action.Created != nil {
+		at = *action.Created
+	}
+
+	collector.mu.Lock()
+	defer collector.mu.Unlock()
+	return collector.updatePolls(func(store *pollStore) error {
+		poll, ok := store.Polls[collector.PollID]
+		if !ok {
+			return fmt.Errorf("Poll %s is no longer in the state directory", collector.PollID)
+		}
+		if !poll.vote(action.PersonID, personEmail, poll.choicesFromInputs(action.Inputs), at) {
+			return nil
+		}
+		log.Infof("Recorded vote %d on poll %s", len(poll.Votes), poll.ID)
+		if poll.Live {
+			if err := collector.publishPollResults(poll); err != nil {
+				log.Errorf("Failed to update poll results: %s", err.Error())
+			}
+		}
+		return nil
+	})
+}
+
+// personEmail resolves and caches the primary email of a person. Lookup
+// failures leave it empty.
+func (collector *pollCollector) personEmail(personID string) string {
+	if cached, ok := collector.personEmails.Load(personID); ok {
+		return cached.(string)
+	}
+	person, err := collector.people.Get(personID)
+	if err != nil || len(person.Emails) == 0 {
+		return ""
+	}
+	personEmail := strings.ToLower(person.Emails[0])
+	collector.personEmails.Store(personID, personEmail)
+	return personEmail
+}
+
+func (app *Application) loadPolls() (*pollStore, error) {
+	statePath, err := app.statePath(pollStateFile)
+	if err != nil {
+		return nil, err
+	}
+	store := &pollStore{Polls: make(map[string]*Poll)}
+	if err := loadStateFile(statePath, store); err != nil {
+		return nil, err
+	}
+	if store.Polls == nil {
+		store.Polls = make(map[string]*Poll)
+	}
+	return store, nil
+}
+
+// updatePolls loads the store, applies update and saves it back. The state
+// lock is held throughout, so a vote never saves a poll loaded before it
+// was closed.
+func (app *Application) updatePolls(update func(store *pollStore) error) error {
+	statePath, err := app.statePath(pollStateFile)
+	if err != nil {
+		return err
+	}
+	unlock, err := lockStateFile(statePath)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	store, err := app.loadPolls()
+	if err != nil {
+		return err
+	}
+	if err := update(store); err != nil {
+		return err
+	}
+	return saveStateFile(statePath, store)
+}
@@ -0,0 +1,271 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	webex "github.com/WebexCommunity/webex-go-sdk/v2"
+	"github.com/WebexCommunity/webex-go-sdk/v2/attachmentactions"
+	"github.com/WebexCommunity/webex-go-sdk/v2/webexsdk"
+)
+
+func TestParsePollOptions(t *testing.T) {
+	options, err := parsePollOptions([]string{" Yes ", "", "No"})
+	if err != nil {
+		t.Fatalf("parsePollOptions() error = %v", err)
+	}
+	if len(options) != 2 || options[0] != "Yes" || options[1] != "No" {
+		t.Errorf("Unexpected options %v", options)
+	}
+	if _, err := parsePollOptions([]string{"Yes", "yes"}); err == nil {
+		t.Error("Expected an error for a repeated option")
+	}
+	if _, err := parsePollOptions([]string{"Yes", " "}); err == nil {
+		t.Error("Expected an error for a single option")
+	}
+}
+
+func TestPollChoicesFromInputs(t *testing.T) {
+	poll := &Poll{Options: []string{"A", "B", "C"}}
+	tests := []struct {
+		name     string
+		multi    bool
+		inputs   map[string]interface{}
+		expected []int
+	}{
+		{"single", false, map[string]interface{}{"choice": "1"}, []int{1}},
+		{"single keeps first", false, map[string]interface{}{"choice": "2,0"}, []int{2}},
+		{"multi", true, map[string]interface{}{"choice": "2,0,2"}, []int{0, 2}},
+		{"out of range", true, map[string]interface{}{"choice": "3,x,-1"}, []int{}},
+		{"missing", false, map[string]interface{}{}, []int{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			poll.Multi = tt.multi
+			choices := poll.choicesFromInputs(tt.inputs)
+			if len(choices) != len(tt.expected) {
+				t.Fatalf("choicesFromInputs() = %v, want %v", choices, tt.expected)
+			}
+			for i := range choices {
+				if choices[i] != tt.expected[i] {
+					t.Errorf("choicesFromInputs() = %v, want %v", choices, tt.expected)
+				}
+			}
+		})
+	}
+}
+
+func TestPollVote(t *testing.T) {
+	poll := &Poll{Options: []string{"A", "B"}}
+	now := time.Now()
+	if poll.vote("p1", "a@example.com", nil, now) {
+		t.Error("Expected a vote without choices to be ignored")
+	}
+	poll.vote("p1", "a@example.com", []int{0}, now)
+	poll.vote("p1", "a@example.com", []int{1}, now.Add(time.Minute))
+	if len(poll.Votes) != 1 || poll.Votes["p1"].Choices[0] != 1 {
+		t.Errorf("Expected the vote to be replaced, got %+v", poll.Votes["p1"])
+	}
+
+	poll.Anonymous = true
+	poll.vote("p2", "b@example.com", []int{0}, now)
+	if _, ok := poll.Votes["p2"]; ok {
+		t.Error("Expected anonymous votes not to be keyed by person ID")
+	}
+	vote, ok := poll.Votes[poll.voterKey("p2")]
+	if !ok || vote.PersonEmail != "" {
+		t.Errorf("Expected an anonymous vote without the email, got %+v", poll.Votes)
+	}
+	poll.vote("p2", "b@example.com", []int{1}, now.Add(time.Minute))
+	if len(poll.Votes) != 2 || poll.Votes[poll.voterKey("p2")].Choices[0] != 1 {
+		t.Errorf("Expected the anonymous vote to be replaced, got %+v", poll.Votes)
+	}
+	other := &Poll{Anonymous: true}
+	if other.voterKey("p2") == poll.voterKey("p2") {
+		t.Error("Expected voter keys to differ between anonymous polls")
+	}
+
+	poll.Closed = true
+	if poll.vote("p3", "", []int{0}, now) {
+		t.Error("Expected votes on a closed poll to be ignored")
+	}
+}
+
+func TestPollResults(t *testing.T) {
+	poll := &Poll{
+		Question: "Lunch?",
+		Options:  []string{"Pizza", "Tacos"},
+		Votes: map[string]*pollVote{
+			"p1": {PersonEmail: "b@example.com", Choices: []int{0}},
+			"p2": {PersonEmail: "a@example.com", Choices: []int{0}},
+			"p3": {PersonEmail: "c@example.com", Choices: []int{0}},
+			"p4": {PersonEmail: "d@example.com", Choices: []int{1}},
+		},
+		Closed: true,
+	}
+	results := poll.results()
+	for _, expected := range []string{
+		"**Poll closed: Lunch?**",
+		"Pizza " + strings.Repeat("█", 15) + strings.Repeat("░", 5) + " 3 (75%)",
+		"Tacos " + strings.Repeat("█", 5) + strings.Repeat("░", 15) + " 1 (25%)",
+		"4 votes",
+		"- Pizza: a@example.com, b@example.com, c@example.com",
+	} {
+		if !strings.Contains(results, expected) {
+			t.Errorf("Expected results to contain %q, got\n%s", expected, results)
+		}
+	}
+
+	poll.Anonymous = true
+	if strings.Contains(poll.results(), "@example.com") {
+		t.Error("Expected anonymous results to leave out voters")
+	}
+}
+
+func TestClosePoll(t *testing.T) {
+	var posted, updated int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Method {
+		case http.MethodPost:
+			posted++
+		case http.MethodPut:
+			updated++
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"id": "results1"})
+	}))
+	defer server.Close()
+	client, err := webex.NewClient("test-token", &webexsdk.Config{BaseURL: server.URL, MaxRetries: 0})
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	app := &Application{Client: client, StateDir: t.TempDir()}
+
+	err = app.updatePolls(func(store *pollStore) error {
+		store.Polls["p1"] = &Poll{ID: "p1", RoomID: "r1", MessageID: "m1", Question: "Lunch?", Options: []string{"Pizza", "Tacos"}}
+		store.Polls["p2"] = &Poll{ID: "p2", RoomID: "r1", MessageID: "m2", ResultsMessageID: "results2", Question: "Dinner?", Options: []string{"Soup", "Salad"}}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("updatePolls() error = %v", err)
+	}
+
+	if err := app.ClosePoll("p1"); err != nil {
+		t.Fatalf("ClosePoll() error = %v", err)
+	}
+	if err := app.ClosePoll("p2"); err != nil {
+		t.Fatalf("ClosePoll() error = %v", err)
+	}
+	if posted != 1 || updated != 1 {
+		t.Errorf("Expected one results message posted and one updated, got %d and %d", posted, updated)
+	}
+
+	store, err := app.loadPolls()
+	if err != nil {
+		t.Fatalf("loadPolls() error = %v", err)
+	}
+	if !store.Polls["p1"].Closed || store.Polls["p1"].ResultsMessageID != "results1" {
+		t.Errorf("Unexpected closed poll %+v", store.Polls["p1"])
+	}
+	if err := app.ClosePoll("p1"); err == nil {
+		t.Error("Expected an error closing a closed poll")
+	}
+	if err := app.ClosePoll("missing"); err == nil {
+		t.Error("Expected an error closing an unknown poll")
+	}
+}
+
+func TestPollCollectorRecord(t *testing.T) {
+	app := &Application{StateDir: t.TempDir()}
+	err := app.updatePolls(func(store *pollStore) error {
+		store.Polls["p1"] = &Poll{ID: "p1", MessageID: "m1", Options: []string{"A", "B"}, Anonymous: true}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("updatePolls() error = %v", err)
+	}
+	collector := &pollCollector{Application: app, PollID: "p1", MessageID: "m1", Anonymous: true}
+
+	actions := []*attachmentactions.AttachmentAction{
+		{MessageID: "m1", PersonID: "person1", Inputs: map[string]interface{}{"choice": "0"}},
+		{MessageID: "other", PersonID: "person2", Inputs: map[string]interface{}{"choice": "0"}},
+		{MessageID: "m1", PersonID: "person1", Inputs: map[string]interface{}{"choice": "1"}},
+	}
+	for _, action := range actions {
+		if err := collector.record(action); err != nil {
+			t.Fatalf("record() error = %v", err)
+		}
+	}
+
+	store, err := app.loadPolls()
+	if err != nil {
+		t.Fatalf("loadPolls() error = %v", err)
+	}
+	poll := store.Polls["p1"]
+	vote, ok := poll.Votes[poll.voterKey("person1")]
+	if len(poll.Votes) != 1 || !ok || vote.Choices[0] != 1 {
+		t.Errorf("Unexpected votes %+v", poll.Votes)
+	}
+
+	data, err := os.ReadFile(filepath.Join(app.StateDir, pollStateFile))
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if strings.Contains(string(data), "person1") {
+		t.Errorf("Expected the state file to leave out person IDs of an anonymous poll, got %s", data)
+	}
+}
+
+func TestPollCloseWhileRecording(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"id": "results1"})
+	}))
+	defer server.Close()
+	client, err := webex.NewClient("test-token", &webexsdk.Config{BaseURL: server.URL, MaxRetries: 0})
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	app := &Application{Client: client, StateDir: t.TempDir()}
+	err = app.updatePolls(func(store *pollStore) error {
+		store.Polls["p1"] = &Poll{ID: "p1", RoomID: "r1", MessageID: "m1", Question: "Lunch?", Options: []string{"Pizza", "Tacos"}}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("updatePolls() error = %v", err)
+	}
+	collector := &pollCollector{Application: app, PollID: "p1", MessageID: "m1", Anonymous: true}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			action := &attachmentactions.AttachmentAction{MessageID: "m1", PersonID: fmt.Sprintf("person%d", i), Inputs: map[string]interface{}{"choice": "0"}}
+			if err := collector.record(action); err != nil {
+				t.Errorf("record() error = %v", err)
+			}
+		}(i)
+	}
+	if err := app.ClosePoll("p1"); err != nil {
+		t.Fatalf("ClosePoll() error = %v", err)
+	}
+	wg.Wait()
+
+	store, err := app.loadPolls()
+	if err != nil {
+		t.Fatalf("loadPolls() error = %v", err)
+	}
+	if !store.Polls["p1"].Closed {
+		t.Error("Expected the poll to stay closed after votes recorded meanwhile")
+	}
+}
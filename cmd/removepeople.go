@@ -24,6 +24,10 @@ type RemovePeopleApplication struct {
 	*Application
 	PeopleCSVPath string
 	Access        string
+	// Limit bounds the rooms read when no rooms are given, 0 reads all
+	Limit int
+	// Journal records the members removed so an interrupted run can resume
+	Journal *jobJournal
 }
 
 // RemovePeopleCMD function
@@ -61,47 +65,21 @@ func (app *Application) RemovePeopleCMD() *cli.Command {
 				Usage:    "Path to a CSV containing a list of RoomID's to which members will be added to.",
 				Required: false,
 			},
+			&cli.StringFlag{
+				Name:     "team",
+				Aliases:  []string{"tm"},
+				Value:    "",
+				Usage:    "ID or name of a team whose rooms members will be removed from",
+				Required: false,
+			},
+			selectFlag(),
+			limitFlag(),
+			resumeFlag(),
 		},
 		Action: func(c *cli.Context) error {
-			roomID := c.String("roomID")
-			var roomIDs []string
-
-			roomsCSV := c.String("roomsidscsv")
-			if roomsCSV != "" {
-				roomsCSVPath := roomsCSV
-				if !strings.HasSuffix(roomsCSVPath, ".csv") {
-					return errors.New("Only CSV files are supported")
-				}
-				if strings.HasPrefix(roomsCSVPath, "~") {
-					home, err := os.UserHomeDir()
-					if err != nil {
-						return err
-					}
-					tmpFilename := roomsCSVPath[len("~"):]
-					roomsCSVPath = path.Join(home, tmpFilename)
-				}
-
-				absFilePath, err := filepath.Abs(roomsCSVPath)
-				if err != nil {
-					return err
-				}
-
-				csvfile, err := os.Open(absFilePath)
-				if err != nil {
-					return err
-				}
-
-				defer csvfile.Close()
-				c := ParseRoomIDsCSV(csvfile)
-				for v := range c {
-					parsedRoomID, err := app.parseRoomID(v.Value.RoomID)
-					if err != nil {
-						return err
-					}
-					roomIDs = append(roomIDs, parsedRoomID)
-				}
-			} else if roomID != "" {
-				roomIDs = append(roomIDs, roomID)
+			roomIDs, err := app.targetRoomIDs(c)
+			if err != nil {
+				return err
 			}
 
 			access := c.String("access")
@@ -112,14 +90,14 @@ func (app *Application) RemovePeopleCMD() *cli.Command {
 			confirm := c.String("confirm")
 			if len(roomIDs) <= 0 {
 				if confirm != "" {
-					if !strings.HasSuffix("y", strings.TrimSpace(strings.ToLower(confirm))) {
+					if !confirmAnswer(confirm) {
 						return nil
 					}
 				} else {
 					reader := bufio.NewReader(os.Stdin)
 					fmt.Print("Continue to remove members from all rooms that you have moderator access to? (y/n) : ")
 					text, _ := reader.ReadString('\n')
-					if !strings.HasSuffix("y", strings.TrimSpace(strings.ToLower(text))) {
+					if !confirmAnswer(text) {
 						return nil
 					}
 				}
@@ -127,8 +105,15 @@ func (app *Application) RemovePeopleCMD() *cli.Command {
 
 			csvPath := c.String("memberscsv")
 			if csvPath != "" {
-				roomUtilsApp := &RemovePeopleApplication{Application: app, PeopleCSVPath: csvPath, Access: access}
-				err := roomUtilsApp.RemovePeopleFromRoom(roomIDs)
+				journal, err := app.openJobJournal(c, "removemembers", c.String("resume"))
+				if err != nil {
+					return err
+				}
+				roomUtilsApp := &RemovePeopleApplication{Application: app, PeopleCSVPath: csvPath, Access: access, Limit: c.Int("limit"), Journal: journal}
+				err = roomUtilsApp.RemovePeopleFromRoom(roomIDs)
+				if finishErr := journal.finish(err); err == nil {
+					err = finishErr
+				}
 				if err != nil {
 					return err
 				}
@@ -170,6 +155,11 @@ func (app *RemovePeopleApplication) RemovePeopleFromRoom(roomIDs []string) error
 		}
 	}()
 
+	if len(roomIDs) == 0 {
+		// An empty room ID stands for every room the user is a member of
+		roomIDs = []string{""}
+	}
+
 	var rwg sync.WaitGroup
 	rwp := workerpool.New(2)
 	for _, roomID := range roomIDs {
@@ -178,37 +168,36 @@ func (app *RemovePeopleApplication) RemovePeopleFromRoom(roomIDs []string) error
 			rwp.Submit(func() {
 				defer rwg.Done()
 				if roomID == "" {
-					membershipQueryParams := &memberships.ListOptions{}
-					mbrPage, err := app.Client.Memberships().List(membershipQueryParams)
+					items, err := app.ListMemberships("", app.Limit)
 					if err != nil {
 						errChan <- err
 						return
 					}
-					if len(mbrPage.Items) > 0 {
-						var wg sync.WaitGroup
-						for _, membership := range mbrPage.Items {
-							wg.Add(1)
-							go func(membership memberships.Membership) {
-								defer wg.Done()
-								room, err := app.Client.Rooms().Get(membership.RoomID)
-								if err != nil {
-									errChan <- err
-									return
-								}
-
-								if room.Title != "" && app.checkAccess(app.Me, room, membership) {
-									err := app.processRemovePeople(room)
-									if err == nil {
-										log.Println("Removed members from: ", room.Title)
-									} else {
+					if len(items) > 0 {
+						mwp := workerpool.New(2)
+						for _, membership := range items {
+							func(membership memberships.Membership) {
+								mwp.Submit(func() {
+									room, err := app.Client.Rooms().Get(membership.RoomID)
+									if err != nil {
 										errChan <- err
 										return
 									}
-								}
-								errChan <- nil
+
+									if room.Title != "" && app.checkAccess(app.Me, room, membership) {
+										err := app.processRemovePeople(room)
+										if err == nil {
+											log.Println("Removed members from: ", room.Title)
+										} else {
+											errChan <- err
+											return
+										}
+									}
+									errChan <- nil
+								})
 							}(membership)
 						}
-						wg.Wait()
+						mwp.StopWait()
 					}
 				} else {
 					room, err := app.Client.Rooms().Get(roomID)
@@ -296,11 +285,18 @@ func (app *RemovePeopleApplication) processRemovePeople(room *rooms.Room) error
 		wp := workerpool.New(10)
 		for v := range c {
 			if v.Err == nil {
+				if app.Journal.completed(room.ID, string(v.Value.Email), jobActionRemove) {
+					continue
+				}
 				wg.Add(1)
 				func(room *rooms.Room, v UserCSVReturn) {
 					wp.Submit(func() {
 						defer wg.Done()
-						app.removeMember(room, v.Value.Email)
+						if err := app.removeMember(room, v.Value.Email); err != nil {
+							log.Println(err)
+							return
+						}
+						app.Journal.record(room.ID, string(v.Value.Email), jobActionRemove)
 					})
 				}(room, v)
 			} else {
@@ -0,0 +1,350 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/WebexCommunity/webex-go-sdk/v2/rooms"
+	"github.com/gammazero/workerpool"
+	log "github.com/sirupsen/logrus"
+	"github.com/urfave/cli/v2"
+)
+
+// roomReport is a row of report rooms
+type roomReport struct {
+	ID           string     `json:"id"`
+	Title        string     `json:"title"`
+	Type         string     `json:"type"`
+	TeamID       string     `json:"teamId,omitempty"`
+	Team         string     `json:"team,omitempty"`
+	CreatorID    string     `json:"creatorId"`
+	Creator      string     `json:"creator,omitempty"`
+	Owner        bool       `json:"owner"`
+	Moderator    bool       `json:"moderator"`
+	Members      int        `json:"members"`
+	External     int        `json:"external"`
+	LastActivity *time.Time `json:"lastActivity,omitempty"`
+	Created      *time.Time `json:"created,omitempty"`
+}
+
+// roomReportFilter selects the rooms of a report
+type roomReportFilter struct {
+	Owned        bool
+	Moderated    bool
+	InactiveDays int
+	Now          time.Time
+	// Limit bounds the rooms read, most recently active first. 0 reads all
+	Limit int
+}
+
+var roomReportHeader = []string{"id", "title", "type", "team", "creator", "owner", "moderator", "members", "external", "lastActivity", "created"}
+
+var roomReportSortKeys = map[string]func(a, b *roomReport) bool{
+	"title":        func(a, b *roomReport) bool { return strings.ToLower(a.Title) < strings.ToLower(b.Title) },
+	"type":         func(a, b *roomReport) bool { return a.Type < b.Type },
+	"team":         func(a, b *roomReport) bool { return strings.ToLower(a.Team) < strings.ToLower(b.Team) },
+	"members":      func(a, b *roomReport) bool { return a.Members < b.Members },
+	"external":     func(a, b *roomReport) bool { return a.External < b.External },
+	"lastActivity": func(a, b *roomReport) bool { return timeBefore(a.LastActivity, b.LastActivity) },
+	"created":      func(a, b *roomReport) bool { return timeBefore(a.Created, b.Created) },
+}
+
+// ReportCMD function
+func (app *Application) ReportCMD() *cli.Command {
+	return &cli.Command{
+		Name:    "report",
+		Aliases: []string{"rp"},
+		Usage:   "Reports on rooms you belong to",
+		Subcommands: []*cli.Command{
+			app.RoomsReportCMD(),
+		},
+		Action: func(c *cli.Context) error {
+			return nil
+		},
+	}
+}
+
+// RoomsReportCMD function
+func (app *Application) RoomsReportCMD() *cli.Command {
+	return &cli.Command{
+		Name:        "rooms",
+		Aliases:     []string{"r"},
+		Description: "List every room you belong to with its team, your access, member counts and activity",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:     "roomType",
+				Aliases:  []string{"rt"},
+				Value:    "",
+				Usage:    "Filter rooms by room type - group / direct. Defaults to all.",
+				Required: false,
+			},
+			&cli.StringFlag{
+				Name:     "team",
+				Aliases:  []string{"tm"},
+				Value:    "",
+				Usage:    "Only rooms of the team with this ID or name",
+				Required: false,
+			},
+			&cli.BoolFlag{
+				Name:     "owned",
+				Value:    false,
+				Usage:    "Only rooms you created",
+				Required: false,
+			},
+			&cli.BoolFlag{
+				Name:     "moderated",
+				Value:    false,
+				Usage:    "Only rooms you moderate",
+				Required: false,
+			},
+			&cli.IntFlag{
+				Name:     "inactive-days",
+				Aliases:  []string{"id"},
+				Value:    0,
+				Usage:    "Only rooms without activity in this many days",
+				Required: false,
+			},
+			&cli.StringFlag{
+				Name:     "sort",
+				Aliases:  []string{"s"},
+				Value:    "title",
+				Usage:    "Sort by title, type, team, members, external, lastActivity or created. Default is title",
+				Required: false,
+			},
+			&cli.BoolFlag{
+				Name:     "desc",
+				Value:    false,
+				Usage:    "Sort in descending order",
+				Required: false,
+			},
+			&cli.StringFlag{
+				Name:     "output",
+				Aliases:  []string{"o"},
+				Value:    outputTable,
+				Usage:    "Output format, one of table, csv or json. Default is table",
+				Required: false,
+			},
+			limitFlag(),
+		},
+		Action: func(c *cli.Context) error {
+			format := c.String("output")
+			if err := validateOutputFormat(format); err != nil {
+				return err
+			}
+			sortKey := c.String("sort")
+			if _, ok := roomReportSortKeys[sortKey]; !ok {
+				return errors.New("Allowed values for sort flag are title, type, team, members, external, lastActivity and created")
+			}
+
+			teamID := ""
+			if team := c.String("team"); team != "" {
+				resolved, err := app.resolveTeam(team)
+				if err != nil {
+					return err
+				}
+				teamID = resolved.ID
+			}
+
+			reports, err := app.RoomsReport(c.String("roomType"), teamID, roomReportFilter{
+				Owned:        c.Bool("owned"),
+				Moderated:    c.Bool("moderated"),
+				InactiveDays: c.Int("inactive-days"),
+				Now:          time.Now(),
+				Limit:        c.Int("limit"),
+			})
+			if err != nil {
+				return err
+			}
+			sortRoomReports(reports, sortKey, c.Bool("desc"))
+			return writeOutput(os.Stdout, format, roomReportHeader, roomReportRows(reports), reports)
+		},
+	}
+}
+
+// RoomsReport builds the report rows of the rooms the user belongs to that
+// match filter. Member counts are only fetched for matching rooms.
+func (app *Application) RoomsReport(roomType string, teamID string, filter roomReportFilter) ([]*roomReport, error) {
+	allRooms, err := app.ListRooms(roomType, teamID, filter.Limit)
+	if err != nil {
+		return nil, err
+	}
+	myMemberships, err := app.ListAllMemberships("")
+	if err != nil {
+		return nil, err
+	}
+	moderated := make(map[string]bool)
+	for _, membership := range myMemberships {
+		moderated[membership.RoomID] = membership.IsModerator
+	}
+	teamNames := make(map[string]string)
+	if teamList, err := app.GetTeams(); err == nil {
+		for _, team := range teamList {
+			teamNames[team.ID] = team.Name
+		}
+	}
+
+	reports := make([]*roomReport, 0)
+	for _, room := range allRooms {
+		report := app.newRoomReport(room, moderated[room.ID], teamNames[room.TeamID])
+		if filter.matches(report) {
+			reports = append(reports, report)
+		}
+	}
+
+	errChan := make(chan error, len(reports))
+	wp := workerpool.New(4)
+	for _, report := range reports {
+		wp.Submit(func() {
+			items, err := app.ListAllMemberships(report.ID)
+			if err != nil {
+				errChan <- err
+				return
+			}
+			report.Members, _, report.External = app.countMembers(items)
+		})
+	}
+	wp.StopWait()
+	close(errChan)
+	if err := <-errChan; err != nil {
+		return nil, err
+	}
+
+	creatorIDs := make([]string, 0, len(reports))
+	for _, report := range reports {
+		creatorIDs = append(creatorIDs, report.CreatorID)
+	}
+	creators := app.personLabels(creatorIDs)
+	for _, report := range reports {
+		report.Creator = creators[report.CreatorID]
+	}
+	return reports, nil
+}
+
+// personLabels looks up each distinct person ID once and returns their
+// primary email, or display name when they have none. People that cannot be
+// looked up, eg. deleted users, are labelled with their ID.
+func (app *Application) personLabels(personIDs []string) map[string]string {
+	labels := make(map[string]string)
+	var lookups []string
+	for _, personID := range personIDs {
+		if _, ok := labels[personID]; ok || personID == "" {
+			continue
+		}
+		labels[personID] = personID
+		if app.Me != nil && personID == app.Me.ID && len(app.Me.Emails) > 0 {
+			labels[personID] = app.Me.Emails[0]
+			continue
+		}
+		lookups = append(lookups, personID)
+	}
+
+	// The SDK creates its clients lazily and without a lock, so the people
+	// client is taken once before the lookups run concurrently
+	people := app.Client.People()
+	var mu sync.Mutex
+	wp := workerpool.New(4)
+	for _, personID := range lookups {
+		wp.Submit(func() {
+			person, err := people.Get(personID)
+			if err != nil {
+				log.Debugf("Error looking up %s: %s", personID, err.Error())
+				return
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if len(person.Emails) > 0 {
+				labels[personID] = person.Emails[0]
+			} else if person.DisplayName != "" {
+				labels[personID] = person.DisplayName
+			}
+		})
+	}
+	wp.StopWait()
+	return labels
+}
+
+func (app *Application) newRoomReport(room rooms.Room, moderator bool, teamName string) *roomReport {
+	return &roomReport{
+		ID:           room.ID,
+		Title:        room.Title,
+		Type:         room.Type,
+		TeamID:       room.TeamID,
+		Team:         teamName,
+		CreatorID:    room.CreatorID,
+		Owner:        app.Me != nil && room.CreatorID == app.Me.ID,
+		Moderator:    moderator,
+		LastActivity: room.LastActivity,
+		Created:      room.Created,
+	}
+}
+
+// matches reports whether a room passes the filter. Owner and moderator use
+// the same notions as checkAccess.
+func (filter roomReportFilter) matches(report *roomReport) bool {
+	if filter.Owned && !report.Owner {
+		return false
+	}
+	if filter.Moderated && !report.Moderator {
+		return false
+	}
+	if filter.InactiveDays > 0 {
+		cutoff := filter.Now.AddDate(0, 0, -filter.InactiveDays)
+		if report.LastActivity != nil && report.LastActivity.After(cutoff) {
+			return false
+		}
+	}
+	return true
+}
+
+func sortRoomReports(reports []*roomReport, key string, desc bool) {
+	less := roomReportSortKeys[key]
+	sort.SliceStable(reports, func(i, j int) bool {
+		if desc {
+			return less(reports[j], reports[i])
+		}
+		return less(reports[i], reports[j])
+	})
+}
+
+func roomReportRows(reports []*roomReport) [][]string {
+	rows := make([][]string, 0, len(reports))
+	for _, report := range reports {
+		rows = append(rows, []string{
+			report.ID,
+			report.Title,
+			report.Type,
+			report.Team,
+			report.Creator,
+			strconv.FormatBool(report.Owner),
+			strconv.FormatBool(report.Moderator),
+			strconv.Itoa(report.Members),
+			strconv.Itoa(report.External),
+			formatReportTime(report.LastActivity),
+			formatReportTime(report.Created),
+		})
+	}
+	return rows
+}
+
+func formatReportTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// timeBefore orders nil times first
+func timeBefore(a, b *time.Time) bool {
+	if a == nil {
+		return b != nil
+	}
+	if b == nil {
+		return false
+	}
+	return a.Before(*b)
+}
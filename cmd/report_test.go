@@ -0,0 +1,161 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	webex "github.com/WebexCommunity/webex-go-sdk/v2"
+	"github.com/WebexCommunity/webex-go-sdk/v2/people"
+	"github.com/WebexCommunity/webex-go-sdk/v2/rooms"
+	"github.com/WebexCommunity/webex-go-sdk/v2/webexsdk"
+)
+
+func testRoomReports(now time.Time) []*roomReport {
+	recent := now.AddDate(0, 0, -2)
+	old := now.AddDate(0, 0, -200)
+	return []*roomReport{
+		{ID: "r1", Title: "beta", Owner: true, Moderator: true, Members: 5, LastActivity: &recent},
+		{ID: "r2", Title: "Alpha", Members: 12, External: 3, LastActivity: &old},
+		{ID: "r3", Title: "gamma", Moderator: true, Members: 1},
+	}
+}
+
+func TestRoomReportFilter(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	reports := testRoomReports(now)
+
+	count := func(filter roomReportFilter) int {
+		n := 0
+		for _, report := range reports {
+			if filter.matches(report) {
+				n++
+			}
+		}
+		return n
+	}
+	if n := count(roomReportFilter{Now: now}); n != 3 {
+		t.Errorf("Expected all 3 rooms without filters, got %d", n)
+	}
+	if n := count(roomReportFilter{Owned: true, Now: now}); n != 1 {
+		t.Errorf("Expected 1 owned room, got %d", n)
+	}
+	if n := count(roomReportFilter{Moderated: true, Now: now}); n != 2 {
+		t.Errorf("Expected 2 moderated rooms, got %d", n)
+	}
+	if n := count(roomReportFilter{InactiveDays: 90, Now: now}); n != 2 {
+		t.Errorf("Expected 2 inactive rooms (including one without activity), got %d", n)
+	}
+}
+
+func TestSortRoomReports(t *testing.T) {
+	reports := testRoomReports(time.Now())
+
+	sortRoomReports(reports, "title", false)
+	if reports[0].ID != "r2" || reports[1].ID != "r1" || reports[2].ID != "r3" {
+		t.Errorf("Unexpected title order %s %s %s", reports[0].ID, reports[1].ID, reports[2].ID)
+	}
+
+	sortRoomReports(reports, "members", true)
+	if reports[0].ID != "r2" || reports[2].ID != "r3" {
+		t.Errorf("Unexpected members order %s %s %s", reports[0].ID, reports[1].ID, reports[2].ID)
+	}
+
+	sortRoomReports(reports, "lastActivity", false)
+	if reports[0].ID != "r3" || reports[2].ID != "r1" {
+		t.Errorf("Unexpected lastActivity order %s %s %s", reports[0].ID, reports[1].ID, reports[2].ID)
+	}
+}
+
+func TestNewRoomReportOwner(t *testing.T) {
+	app := &Application{Me: &people.Person{ID: "me"}}
+	report := app.newRoomReport(rooms.Room{ID: "r1", CreatorID: "me"}, false, "Platform")
+	if !report.Owner || report.Team != "Platform" {
+		t.Errorf("Unexpected report %+v", report)
+	}
+	report = app.newRoomReport(rooms.Room{ID: "r2", CreatorID: "someone"}, true, "")
+	if report.Owner || !report.Moderator {
+		t.Errorf("Unexpected report %+v", report)
+	}
+}
+
+func TestWriteOutput(t *testing.T) {
+	header := []string{"id", "title"}
+	rows := [][]string{{"r1", "Project, X"}}
+
+	var table bytes.Buffer
+	if err := writeOutput(&table, outputTable, header, rows, nil); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(table.String(), "ID") || !strings.Contains(table.String(), "Project, X") {
+		t.Errorf("Unexpected table %q", table.String())
+	}
+
+	var csvOut bytes.Buffer
+	if err := writeOutput(&csvOut, outputCSV, header, rows, nil); err != nil {
+		t.Fatal(err)
+	}
+	if csvOut.String() != "id,title\nr1,\"Project, X\"\n" {
+		t.Errorf("Unexpected CSV %q", csvOut.String())
+	}
+
+	var jsonOut bytes.Buffer
+	if err := writeOutput(&jsonOut, outputJSON, header, rows, map[string]string{"id": "r1"}); err != nil {
+		t.Fatal(err)
+	}
+	if jsonOut.String() != `{"id":"r1"}` {
+		t.Errorf("Unexpected JSON %q", jsonOut.String())
+	}
+
+	if err := validateOutputFormat("xml"); err == nil {
+		t.Error("Expected an error for an unknown output format")
+	}
+}
+
+func TestPersonLabels(t *testing.T) {
+	var lookups atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lookups.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/people/p1":
+			json.NewEncoder(w).Encode(people.Person{ID: "p1", Emails: []string{"one@example.com"}, DisplayName: "One"})
+		case "/people/p2":
+			json.NewEncoder(w).Encode(people.Person{ID: "p2", DisplayName: "Bot Two"})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	client, err := webex.NewClient("test-token", &webexsdk.Config{BaseURL: server.URL, MaxRetries: 0})
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	app := &Application{Client: client, Me: &people.Person{ID: "me", Emails: []string{"me@example.com"}}}
+
+	labels := app.personLabels([]string{"p1", "p2", "p1", "gone", "me", ""})
+	expected := map[string]string{"p1": "one@example.com", "p2": "Bot Two", "gone": "gone", "me": "me@example.com"}
+	if len(labels) != len(expected) {
+		t.Errorf("personLabels() = %v, want %v", labels, expected)
+	}
+	for personID, label := range expected {
+		if labels[personID] != label {
+			t.Errorf("personLabels()[%s] = %q, want %q", personID, labels[personID], label)
+		}
+	}
+	if lookups.Load() != 3 {
+		t.Errorf("Expected each other person to be looked up once, got %d lookups", lookups.Load())
+	}
+}
+
+func TestRoomReportRowsCreator(t *testing.T) {
+	rows := roomReportRows([]*roomReport{{ID: "r1", Title: "Ops", CreatorID: "p1", Creator: "one@example.com"}})
+	if len(rows[0]) != len(roomReportHeader) || rows[0][4] != "one@example.com" || roomReportHeader[4] != "creator" {
+		t.Errorf("Expected a creator column, got %v", rows[0])
+	}
+}
@@ -0,0 +1,319 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/WebexCommunity/webex-go-sdk/v2/memberships"
+	"github.com/WebexCommunity/webex-go-sdk/v2/rooms"
+	log "github.com/sirupsen/logrus"
+	"github.com/urfave/cli/v2"
+)
+
+// roomDetails extends the SDK room with fields it does not map
+type roomDetails struct {
+	rooms.Room
+	Description string `json:"description,omitempty"`
+}
+
+// roomInfo is the output of room info
+type roomInfo struct {
+	roomDetails
+	MemberCount    int `json:"memberCount"`
+	ModeratorCount int `json:"moderatorCount"`
+	ExternalCount  int `json:"externalCount"`
+}
+
+// CreateRoomCMD function
+func (app *Application) CreateRoomCMD() *cli.Command {
+	return &cli.Command{
+		Name:        "create",
+		Aliases:     []string{"cr"},
+		Description: "Create a group room, optionally in a team and with an initial set of members",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:     "title",
+				Aliases:  []string{"t"},
+				Value:    "",
+				Usage:    "Title of the room",
+				Required: true,
+			},
+			&cli.StringFlag{
+				Name:     "description",
+				Aliases:  []string{"d"},
+				Value:    "",
+				Usage:    "Description of the room",
+				Required: false,
+			},
+			&cli.StringFlag{
+				Name:     "team",
+				Aliases:  []string{"tid"},
+				Value:    "",
+				Usage:    "ID of the team to create the room in",
+				Required: false,
+			},
+			&cli.StringFlag{
+				Name:     "members-csv",
+				Aliases:  []string{"csv"},
+				Value:    "",
+				Usage:    "Path to CSV with list of email addresses to add, formatted as : email, moderator",
+				Required: false,
+			},
+		},
+		Action: func(c *cli.Context) error {
+			room, err := app.createRoom(c.String("title"), c.String("description"), c.String("team"))
+			if err != nil {
+				return err
+			}
+			log.Infof("Created room %s", room.Title)
+
+			csvPath := c.String("members-csv")
+			if csvPath != "" {
+				roomUtilsApp := &AddPeopleApplication{Application: app, PeopleCSVPath: csvPath, Access: "a"}
+				if err := roomUtilsApp.processAddPeople(&room.Room); err != nil {
+					return err
+				}
+			}
+
+			m, err := json.Marshal(room)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("%s", string(m))
+			return nil
+		},
+	}
+}
+
+// UpdateRoomCMD function
+func (app *Application) UpdateRoomCMD() *cli.Command {
+	return &cli.Command{
+		Name:        "update",
+		Aliases:     []string{"up"},
+		Description: "Rename a room or change its description",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:     "title",
+				Aliases:  []string{"t"},
+				Value:    "",
+				Usage:    "New title of the room",
+				Required: false,
+			},
+			&cli.StringFlag{
+				Name:     "description",
+				Aliases:  []string{"d"},
+				Value:    "",
+				Usage:    "New description of the room",
+				Required: false,
+			},
+		},
+		Action: func(c *cli.Context) error {
+			roomID, err := app.requireRoomID(c)
+			if err != nil {
+				return err
+			}
+			if !c.IsSet("title") && !c.IsSet("description") {
+				return errors.New("title or description is required")
+			}
+			room, err := app.updateRoom(roomID, func(update map[string]interface{}) {
+				if c.IsSet("title") {
+					update["title"] = c.String("title")
+				}
+				if c.IsSet("description") {
+					update["description"] = c.String("description")
+				}
+			})
+			if err != nil {
+				return err
+			}
+			log.Infof("Updated room %s", room.Title)
+			return nil
+		},
+	}
+}
+
+// LockRoomCMD function
+func (app *Application) LockRoomCMD() *cli.Command {
+	return app.setRoomLockCMD("lock", "Lock a room, making it a moderated space", true)
+}
+
+// UnlockRoomCMD function
+func (app *Application) UnlockRoomCMD() *cli.Command {
+	return app.setRoomLockCMD("unlock", "Unlock a moderated room", false)
+}
+
+func (app *Application) setRoomLockCMD(name string, description string, locked bool) *cli.Command {
+	return &cli.Command{
+		Name:        name,
+		Aliases:     []string{name[:2]},
+		Description: description,
+		Action: func(c *cli.Context) error {
+			roomID, err := app.requireRoomID(c)
+			if err != nil {
+				return err
+			}
+			room, err := app.updateRoom(roomID, func(update map[string]interface{}) {
+				update["isLocked"] = locked
+			})
+			if err != nil {
+				return err
+			}
+			log.Infof("Room %s is now %sed", room.Title, name)
+			return nil
+		},
+	}
+}
+
+// RoomInfoCMD function
+func (app *Application) RoomInfoCMD() *cli.Command {
+	return &cli.Command{
+		Name:        "info",
+		Aliases:     []string{"i"},
+		Description: "Show the details of a room along with member counts",
+		Action: func(c *cli.Context) error {
+			roomID, err := app.requireRoomID(c)
+			if err != nil {
+				return err
+			}
+			info, err := app.getRoomInfo(roomID)
+			if err != nil {
+				return err
+			}
+			m, err := json.Marshal(info)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("%s", string(m))
+			return nil
+		},
+	}
+}
+
+// DeleteRoomCMD function
+func (app *Application) DeleteRoomCMD() *cli.Command {
+	return &cli.Command{
+		Name:        "delete",
+		Aliases:     []string{"del"},
+		Description: "Delete a room and all of its messages",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:     "confirm",
+				Aliases:  []string{"c"},
+				Value:    "",
+				Usage:    "Continue without confirmation? Allowed values are 'y' or 'n' ",
+				Required: false,
+			},
+		},
+		Action: func(c *cli.Context) error {
+			roomID, err := app.requireRoomID(c)
+			if err != nil {
+				return err
+			}
+			room, err := app.Client.Rooms().Get(roomID)
+			if err != nil {
+				return err
+			}
+			if !confirmPrompt(c.String("confirm"), fmt.Sprintf("Delete room %s and all of its messages?", room.Title)) {
+				return nil
+			}
+			if err := app.Client.Rooms().Delete(room.ID); err != nil {
+				return err
+			}
+			log.Infof("Deleted room %s", room.Title)
+			return nil
+		},
+	}
+}
+
+// requireRoomID returns the parsed room ID set on the room command
+func (app *Application) requireRoomID(c *cli.Context) (string, error) {
+	roomID := c.String("roomID")
+	if roomID == "" {
+		return "", errors.New("roomID is required")
+	}
+	return app.parseRoomID(roomID)
+}
+
+func (app *Application) createRoom(title string, description string, teamID string) (*roomDetails, error) {
+	create := map[string]interface{}{
+		"title": title,
+	}
+	if description != "" {
+		create["description"] = description
+	}
+	if teamID != "" {
+		create["teamId"] = teamID
+	}
+	room := &roomDetails{}
+	if err := app.apiRequest(http.MethodPost, "rooms", nil, create, room); err != nil {
+		return nil, err
+	}
+	return room, nil
+}
+
+func (app *Application) getRoomDetails(roomID string) (*roomDetails, error) {
+	room := &roomDetails{}
+	if err := app.apiRequest(http.MethodGet, "rooms/"+roomID, nil, nil, room); err != nil {
+		return nil, err
+	}
+	return room, nil
+}
+
+// updateRoom applies modify on top of the room's current settings. The rooms
+// API replaces settings that are left out, so they are always sent.
+func (app *Application) updateRoom(roomID string, modify func(update map[string]interface{})) (*roomDetails, error) {
+	current, err := app.getRoomDetails(roomID)
+	if err != nil {
+		return nil, err
+	}
+	update := map[string]interface{}{
+		"title":    current.Title,
+		"isLocked": current.IsLocked,
+	}
+	if current.Description != "" {
+		update["description"] = current.Description
+	}
+	modify(update)
+
+	room := &roomDetails{}
+	if err := app.apiRequest(http.MethodPut, "rooms/"+current.ID, nil, update, room); err != nil {
+		return nil, err
+	}
+	return room, nil
+}
+
+func (app *Application) getRoomInfo(roomID string) (*roomInfo, error) {
+	details, err := app.getRoomDetails(roomID)
+	if err != nil {
+		return nil, err
+	}
+	items, err := app.ListAllMemberships(details.ID)
+	if err != nil {
+		return nil, err
+	}
+	info := &roomInfo{roomDetails: *details}
+	info.MemberCount, info.ModeratorCount, info.ExternalCount = app.countMembers(items)
+	return info, nil
+}
+
+// countMembers returns the number of members, moderators and members from
+// outside the authenticated user's organization
+func (app *Application) countMembers(items []memberships.Membership) (int, int, int) {
+	moderators, external := 0, 0
+	for _, membership := range items {
+		if membership.IsModerator {
+			moderators++
+		}
+		if app.isExternal(membership.PersonOrgID) {
+			external++
+		}
+	}
+	return len(items), moderators, external
+}
+
+// isExternal reports whether an organization differs from the authenticated user's
+func (app *Application) isExternal(orgID string) bool {
+	return orgID != "" && app.Me != nil && app.Me.OrgID != "" && orgID != app.Me.OrgID
+}
@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/WebexCommunity/webex-go-sdk/v2/memberships"
+	"github.com/WebexCommunity/webex-go-sdk/v2/people"
+	"github.com/urfave/cli/v2"
+)
+
+func TestCountMembers(t *testing.T) {
+	app := &Application{Me: &people.Person{OrgID: "org-1"}}
+	items := []memberships.Membership{
+		{PersonOrgID: "org-1", IsModerator: true},
+		{PersonOrgID: "org-1"},
+		{PersonOrgID: "org-2"},
+		{PersonOrgID: "org-2", IsModerator: true},
+	}
+	total, moderators, external := app.countMembers(items)
+	if total != 4 || moderators != 2 || external != 2 {
+		t.Errorf("Expected 4 members, 2 moderators, 2 external, got %d, %d, %d", total, moderators, external)
+	}
+}
+
+func TestIsExternal(t *testing.T) {
+	app := &Application{Me: &people.Person{OrgID: "org-1"}}
+	if app.isExternal("org-1") {
+		t.Error("Expected own organization not to be external")
+	}
+	if !app.isExternal("org-2") {
+		t.Error("Expected other organization to be external")
+	}
+	if app.isExternal("") {
+		t.Error("Expected unknown organization not to be external")
+	}
+
+	noMe := &Application{}
+	if noMe.isExternal("org-2") {
+		t.Error("Expected no external members without the authenticated user")
+	}
+}
+
+func TestRoomAdminCMDStructure(t *testing.T) {
+	app := &Application{}
+
+	create := app.CreateRoomCMD()
+	if create.Name != "create" {
+		t.Errorf("Expected command name 'create', got %q", create.Name)
+	}
+	title := getFlagByName(create.Flags, "title")
+	if title == nil || !title.(*cli.StringFlag).Required {
+		t.Error("Expected create to have a required title flag")
+	}
+	for _, flagName := range []string{"description", "team", "members-csv"} {
+		if getFlagByName(create.Flags, flagName) == nil {
+			t.Errorf("Expected create flag %q not found", flagName)
+		}
+	}
+
+	update := app.UpdateRoomCMD()
+	for _, flagName := range []string{"title", "description"} {
+		flag := getFlagByName(update.Flags, flagName)
+		if flag == nil {
+			t.Errorf("Expected update flag %q not found", flagName)
+		} else if flag.(*cli.StringFlag).Required {
+			t.Errorf("Expected update flag %q to be optional", flagName)
+		}
+	}
+
+	if app.LockRoomCMD().Name != "lock" || app.UnlockRoomCMD().Name != "unlock" {
+		t.Error("Expected lock and unlock commands")
+	}
+
+	del := app.DeleteRoomCMD()
+	if getFlagByName(del.Flags, "confirm") == nil {
+		t.Error("Expected delete to have a confirm flag")
+	}
+}
+
+func TestConfirmPromptFlag(t *testing.T) {
+	if !confirmPrompt("y", "Continue?") {
+		t.Error("Expected 'y' to confirm")
+	}
+	if !confirmPrompt(" Y ", "Continue?") {
+		t.Error("Expected ' Y ' to confirm")
+	}
+	if confirmPrompt("n", "Continue?") {
+		t.Error("Expected 'n' not to confirm")
+	}
+}
+
+func TestConfirmAnswer(t *testing.T) {
+	for _, answer := range []string{"y", "Yes\n", " Y "} {
+		if !confirmAnswer(answer) {
+			t.Errorf("Expected %q to confirm", answer)
+		}
+	}
+	for _, answer := range []string{"", "\n", "n", "no", "yy", "maybe"} {
+		if confirmAnswer(answer) {
+			t.Errorf("Expected %q not to confirm", answer)
+		}
+	}
+}
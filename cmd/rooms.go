@@ -40,7 +40,17 @@ func (app *Application) RoomCMD() *cli.Command {
 			app.AddPeopleCMD(),
 			app.ExportPeopleCMD(),
 			app.RemovePeopleCMD(),
+			app.SyncMembersCMD(),
+			app.ModeratorsCMD(),
 			app.BroadcastToRoomsCMD(),
+			app.StatusMessageCMD(),
+			app.CreateRoomCMD(),
+			app.UpdateRoomCMD(),
+			app.LockRoomCMD(),
+			app.UnlockRoomCMD(),
+			app.RoomInfoCMD(),
+			app.DeleteRoomCMD(),
+			app.CloneRoomCMD(),
 		},
 		Action: func(c *cli.Context) error {
 			return nil
@@ -0,0 +1,522 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"text/template"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	log "github.com/sirupsen/logrus"
+	"github.com/urfave/cli/v2"
+)
+
+const scheduleStateFile = "schedules.json"
+
+// Catch-up policies for runs missed while the daemon was not running
+const (
+	catchUpSkip = "skip"
+	catchUpOnce = "once"
+	catchUpAll  = "all"
+)
+
+// scheduleGrace is how late a run may fire and still count as on time
+const scheduleGrace = time.Minute
+
+// maxCatchUpRuns bounds the number of missed runs replayed by the "all" policy
+const maxCatchUpRuns = 100
+
+// A failed run is retried after a backoff doubling from scheduleRetryBackoff
+// up to scheduleMaxRetryBackoff, and skipped after scheduleMaxAttempts
+const (
+	scheduleRetryBackoff    = time.Minute
+	scheduleMaxRetryBackoff = time.Hour
+	scheduleMaxAttempts     = 8
+)
+
+// ScheduledJob is a message sent to a room whenever its cron expression fires
+type ScheduledJob struct {
+	ID          string     `json:"id"`
+	Cron        string     `json:"cron"`
+	Timezone    string     `json:"timezone"`
+	CatchUp     string     `json:"catchUp"`
+	RoomID      string     `json:"roomId,omitempty"`
+	PersonEmail string     `json:"personEmail,omitempty"`
+	Text        string     `json:"text,omitempty"`
+	File        string     `json:"file,omitempty"`
+	Created     time.Time  `json:"created"`
+	LastRun     *time.Time `json:"lastRun,omitempty"`
+	RunCount    int        `json:"runCount"`
+	// Failures counts the failed attempts of the next run, retried at RetryAt
+	Failures int        `json:"failures,omitempty"`
+	RetryAt  *time.Time `json:"retryAt,omitempty"`
+}
+
+// scheduleStore is the persisted list of scheduled jobs
+type scheduleStore struct {
+	Jobs []*ScheduledJob `json:"jobs"`
+}
+
+// scheduleTemplateData is available to message templates at send time
+type scheduleTemplateData struct {
+	Now         time.Time
+	ScheduledAt time.Time
+	RunCount    int
+}
+
+var scheduleTemplateFuncs = template.FuncMap{
+	"addDays": func(t time.Time, days int) time.Time {
+		return t.AddDate(0, 0, days)
+	},
+	"date": func(layout string, t time.Time) string {
+		return t.Format(layout)
+	},
+}
+
+// ScheduleCMD function
+func (app *Application) ScheduleCMD() *cli.Command {
+	return &cli.Command{
+		Name:    "schedule",
+		Aliases: []string{"sch"},
+		Usage:   "Scheduled and recurring messages",
+		Subcommands: []*cli.Command{
+			app.scheduleAddCMD(),
+			app.scheduleListCMD(),
+			app.scheduleRemoveCMD(),
+			app.scheduleDaemonCMD(),
+		},
+		Action: func(c *cli.Context) error {
+			return nil
+		},
+	}
+}
+
+func (app *Application) scheduleAddCMD() *cli.Command {
+	return &cli.Command{
+		Name:        "add",
+		Aliases:     []string{"a"},
+		Description: "Schedule a recurring message",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:     "cron",
+				Value:    "",
+				Usage:    "Standard 5 field cron expression eg. \"0 9 * * 1-5\"",
+				Required: true,
+			},
+			&cli.StringFlag{
+				Name:     "roomID",
+				Aliases:  []string{"room", "rid"},
+				Value:    "",
+				Usage:    "Webex room ID to send the message to",
+				Required: false,
+			},
+			&cli.StringFlag{
+				Name:     "toPersonEmail",
+				Aliases:  []string{"pe"},
+				Value:    "",
+				Usage:    "Webex person Email to send the message to",
+				Required: false,
+			},
+			&cli.StringFlag{
+				Name:     "text",
+				Aliases:  []string{"t"},
+				Value:    "",
+				Usage:    "Text to be sent. Rendered as a Go template at send time eg. {{ .Now.Format \"Mon Jan 2\" }}",
+				Required: false,
+			},
+			&cli.StringFlag{
+				Name:     "file",
+				Aliases:  []string{"f"},
+				Value:    "",
+				Usage:    "Local file path or Remote URI to be sent",
+				Required: false,
+			},
+			&cli.StringFlag{
+				Name:     "timezone",
+				Aliases:  []string{"tz"},
+				Value:    "Local",
+				Usage:    "IANA timezone the cron expression is evaluated in eg. Europe/Berlin. Defaults to the local timezone",
+				Required: false,
+			},
+			&cli.StringFlag{
+				Name:     "catchup",
+				Aliases:  []string{"cu"},
+				Value:    catchUpOnce,
+				Usage:    "What to do with runs missed while the daemon was down: 'skip', 'once' (send the latest missed run) or 'all'. Default is once",
+				Required: false,
+			},
+		},
+		Action: func(c *cli.Context) error {
+			job := &ScheduledJob{
+				Cron:        c.String("cron"),
+				Timezone:    c.String("timezone"),
+				CatchUp:     c.String("catchup"),
+				PersonEmail: c.String("toPersonEmail"),
+				Text:        c.String("text"),
+				File:        c.String("file"),
+				Created:     time.Now(),
+			}
+			if roomID := c.String("roomID"); roomID != "" {
+				parsedRoomID, err := app.parseRoomID(roomID)
+				if err != nil {
+					return err
+				}
+				job.RoomID = parsedRoomID
+			}
+			if err := job.validate(); err != nil {
+				return err
+			}
+			id, err := newStateID()
+			if err != nil {
+				return err
+			}
+			job.ID = id
+
+			err = app.updateSchedules(func(store *scheduleStore) error {
+				store.Jobs = append(store.Jobs, job)
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+
+			next, err := job.next(time.Now())
+			if err != nil {
+				return err
+			}
+			log.Infof("Scheduled job %s, next run at %s", job.ID, next.Format(time.RFC3339))
+			return nil
+		},
+	}
+}
+
+func (app *Application) scheduleListCMD() *cli.Command {
+	return &cli.Command{
+		Name:        "list",
+		Aliases:     []string{"ls"},
+		Description: "List scheduled messages",
+		Action: func(c *cli.Context) error {
+			store, err := app.loadSchedules()
+			if err != nil {
+				return err
+			}
+			if store.Jobs == nil {
+				store.Jobs = make([]*ScheduledJob, 0)
+			}
+			m, err := json.Marshal(store.Jobs)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("%s", string(m))
+			return nil
+		},
+	}
+}
+
+func (app *Application) scheduleRemoveCMD() *cli.Command {
+	return &cli.Command{
+		Name:        "rm",
+		Aliases:     []string{"remove"},
+		Description: "Remove a scheduled message by its ID",
+		ArgsUsage:   "<job-id>",
+		Action: func(c *cli.Context) error {
+			id := c.Args().First()
+			if id == "" {
+				return errors.New("Job ID is required")
+			}
+			return app.updateSchedules(func(store *scheduleStore) error {
+				for i, job := range store.Jobs {
+					if job.ID == id {
+						store.Jobs = append(store.Jobs[:i], store.Jobs[i+1:]...)
+						log.Infof("Removed job %s", id)
+						return nil
+					}
+				}
+				return fmt.Errorf("Job %s not found", id)
+			})
+		},
+	}
+}
+
+func (app *Application) scheduleDaemonCMD() *cli.Command {
+	return &cli.Command{
+		Name:        "daemon",
+		Aliases:     []string{"d"},
+		Description: "Run scheduled messages as they become due",
+		Action: func(c *cli.Context) error {
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			return app.RunScheduleDaemon(ctx)
+		},
+	}
+}
+
+func (app *Application) loadSchedules() (*scheduleStore, error) {
+	statePath, err := app.statePath(scheduleStateFile)
+	if err != nil {
+		return nil, err
+	}
+	store := &scheduleStore{}
+	if err := loadStateFile(statePath, store); err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+// updateSchedules loads the store, applies update and saves it back. The
+// state lock is held throughout, so the daemon and add or rm do not revert
+// each other's changes.
+func (app *Application) updateSchedules(update func(store *scheduleStore) error) error {
+	statePath, err := app.statePath(scheduleStateFile)
+	if err != nil {
+		return err
+	}
+	unlock, err := lockStateFile(statePath)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	store := &scheduleStore{}
+	if err := loadStateFile(statePath, store); err != nil {
+		return err
+	}
+	if err := update(store); err != nil {
+		return err
+	}
+	return saveStateFile(statePath, store)
+}
+
+func (job *ScheduledJob) validate() error {
+	if job.RoomID == "" && job.PersonEmail == "" {
+		return errors.New("roomID or toPersonEmail is required")
+	}
+	if job.Text == "" && job.File == "" {
+		return errors.New("text or file is required")
+	}
+	if job.CatchUp != catchUpSkip && job.CatchUp != catchUpOnce && job.CatchUp != catchUpAll {
+		return errors.New("Allowed values for catchup flag are skip, once and all")
+	}
+	if _, err := job.schedule(); err != nil {
+		return err
+	}
+	if _, err := template.New(job.ID).Funcs(scheduleTemplateFuncs).Parse(job.Text); err != nil {
+		return fmt.Errorf("invalid message template: %w", err)
+	}
+	return nil
+}
+
+func (job *ScheduledJob) location() (*time.Location, error) {
+	if job.Timezone == "" || job.Timezone == "Local" {
+		return time.Local, nil
+	}
+	return time.LoadLocation(job.Timezone)
+}
+
+func (job *ScheduledJob) schedule() (cron.Schedule, error) {
+	loc, err := job.location()
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone %s: %w", job.Timezone, err)
+	}
+	schedule, err := cron.ParseStandard(job.Cron)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cron expression %q: %w", job.Cron, err)
+	}
+	if spec, ok := schedule.(*cron.SpecSchedule); ok {
+		spec.Location = loc
+	}
+	return schedule, nil
+}
+
+// next returns the first run after t
+func (job *ScheduledJob) next(t time.Time) (time.Time, error) {
+	schedule, err := job.schedule()
+	if err != nil {
+		return time.Time{}, err
+	}
+	return schedule.Next(t), nil
+}
+
+// dueRuns returns the runs to execute at now according to the catch-up
+// policy, and the scheduled time the job has been processed up to. A zero
+// time means nothing has become due since the last run.
+func (job *ScheduledJob) dueRuns(now time.Time) ([]time.Time, time.Time, error) {
+	schedule, err := job.schedule()
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	since := job.Created
+	if job.LastRun != nil {
+		since = *job.LastRun
+	}
+
+	var missed []time.Time
+	for t := schedule.Next(since); !t.After(now); t = schedule.Next(t) {
+		missed = append(missed, t)
+		if len(missed) > maxCatchUpRuns {
+			missed = missed[1:]
+		}
+	}
+	if len(missed) == 0 {
+		return nil, time.Time{}, nil
+	}
+
+	latest := missed[len(missed)-1]
+	switch job.CatchUp {
+	case catchUpAll:
+		return missed, latest, nil
+	case catchUpSkip:
+		if now.Sub(latest) <= scheduleGrace {
+			return []time.Time{latest}, latest, nil
+		}
+		return nil, latest, nil
+	default:
+		return []time.Time{latest}, latest, nil
+	}
+}
+
+// retryBackoff returns how long to wait before retrying a run that failed
+// job.Failures times
+func (job *ScheduledJob) retryBackoff() time.Duration {
+	backoff := scheduleRetryBackoff
+	for i := 1; i < job.Failures && backoff < scheduleMaxRetryBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > scheduleMaxRetryBackoff {
+		backoff = scheduleMaxRetryBackoff
+	}
+	return backoff
+}
+
+// renderText renders the job's message template for a run
+func (job *ScheduledJob) renderText(scheduledAt time.Time, now time.Time) (string, error) {
+	loc, err := job.location()
+	if err != nil {
+		return "", err
+	}
+	tmpl, err := template.New(job.ID).Funcs(scheduleTemplateFuncs).Parse(job.Text)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	err = tmpl.Execute(&buf, scheduleTemplateData{
+		Now:         now.In(loc),
+		ScheduledAt: scheduledAt.In(loc),
+		RunCount:    job.RunCount + 1,
+	})
+	if err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// RunScheduleDaemon executes due jobs until ctx is cancelled. The store is
+// re-read on every tick so jobs added or removed while running are honoured.
+func (app *Application) RunScheduleDaemon(ctx context.Context) error {
+	log.Info("Started schedule daemon")
+	for {
+		app.runDueJobs(time.Now())
+
+		wait := time.Until(time.Now().Truncate(time.Minute).Add(time.Minute))
+		select {
+		case <-ctx.Done():
+			log.Info("Stopped schedule daemon")
+			return nil
+		case <-time.After(wait):
+		}
+	}
+}
+
+func (app *Application) runDueJobs(now time.Time) {
+	store, err := app.loadSchedules()
+	if err != nil {
+		log.Error(err.Error())
+		return
+	}
+
+	for _, job := range store.Jobs {
+		if job.RetryAt != nil && now.Before(*job.RetryAt) {
+			continue
+		}
+		runs, processedUntil, err := job.dueRuns(now)
+		if err != nil {
+			log.Errorf("Job %s: %s", job.ID, err.Error())
+			continue
+		}
+		if processedUntil.IsZero() {
+			continue
+		}
+
+		// A failed run stops the job here, LastRun only moves past runs that
+		// were sent so the failed one is due again after a backoff, until it
+		// is given up
+		var failed bool
+		for _, scheduledAt := range runs {
+			if err := app.runScheduledJob(job, scheduledAt, now); err != nil {
+				log.Errorf("Job %s: %s", job.ID, err.Error())
+				failed = true
+				job.Failures++
+				if job.Failures >= scheduleMaxAttempts {
+					log.Errorf("Job %s: skipping the run of %s after %d failed attempts", job.ID, scheduledAt.Format(time.RFC3339), job.Failures)
+					processed := scheduledAt
+					job.LastRun = &processed
+					job.Failures = 0
+					job.RetryAt = nil
+				} else {
+					retryAt := now.Add(job.retryBackoff())
+					job.RetryAt = &retryAt
+				}
+				break
+			}
+			job.RunCount++
+			processed := scheduledAt
+			job.LastRun = &processed
+			job.Failures = 0
+			job.RetryAt = nil
+		}
+		if !failed {
+			job.LastRun = &processedUntil
+		}
+
+		err = app.updateSchedules(func(store *scheduleStore) error {
+			for _, stored := range store.Jobs {
+				if stored.ID == job.ID {
+					stored.LastRun = job.LastRun
+					stored.RunCount = job.RunCount
+					stored.Failures = job.Failures
+					stored.RetryAt = job.RetryAt
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			log.Error(err.Error())
+		}
+	}
+}
+
+func (app *Application) runScheduledJob(job *ScheduledJob, scheduledAt time.Time, now time.Time) error {
+	text, err := job.renderText(scheduledAt, now)
+	if err != nil {
+		return err
+	}
+	params := &SendMessageParams{
+		RoomID:                   job.RoomID,
+		PersonEmail:              job.PersonEmail,
+		Text:                     text,
+		Filename:                 job.File,
+		RemoteFileRequestTimeout: time.Duration(10),
+	}
+	sentMessage, err := app.SendMessage2Room(params)
+	if err != nil {
+		return err
+	}
+	log.Infof("Job %s sent message: %s", job.ID, sentMessage.ID)
+	return nil
+}
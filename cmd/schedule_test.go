@@ -0,0 +1,256 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	webex "github.com/WebexCommunity/webex-go-sdk/v2"
+	"github.com/WebexCommunity/webex-go-sdk/v2/webexsdk"
+)
+
+func newTestJob(cronExpr, catchUp string, created time.Time) *ScheduledJob {
+	return &ScheduledJob{
+		ID:       "job1",
+		Cron:     cronExpr,
+		Timezone: "UTC",
+		CatchUp:  catchUp,
+		RoomID:   "room1",
+		Text:     "Stand-up",
+		Created:  created,
+	}
+}
+
+func TestScheduledJobValidate(t *testing.T) {
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		modify  func(job *ScheduledJob)
+		wantErr bool
+	}{
+		{"valid", func(job *ScheduledJob) {}, false},
+		{"person target", func(job *ScheduledJob) { job.RoomID = ""; job.PersonEmail = "a@example.com" }, false},
+		{"no target", func(job *ScheduledJob) { job.RoomID = "" }, true},
+		{"no content", func(job *ScheduledJob) { job.Text = "" }, true},
+		{"invalid cron", func(job *ScheduledJob) { job.Cron = "not a cron" }, true},
+		{"invalid timezone", func(job *ScheduledJob) { job.Timezone = "Mars/Olympus" }, true},
+		{"invalid catchup", func(job *ScheduledJob) { job.CatchUp = "sometimes" }, true},
+		{"invalid template", func(job *ScheduledJob) { job.Text = "{{ .Now" }, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			job := newTestJob("0 9 * * 1-5", catchUpOnce, created)
+			tt.modify(job)
+			if err := job.validate(); (err != nil) != tt.wantErr {
+				t.Errorf("validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestScheduledJobTimezone(t *testing.T) {
+	job := newTestJob("0 9 * * *", catchUpOnce, time.Time{})
+	job.Timezone = "America/New_York"
+
+	next, err := job.next(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("next() error = %v", err)
+	}
+	expected := time.Date(2024, 1, 1, 14, 0, 0, 0, time.UTC)
+	if !next.Equal(expected) {
+		t.Errorf("next() = %s, want %s", next.UTC(), expected)
+	}
+}
+
+func TestScheduledJobDueRuns(t *testing.T) {
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		catchUp       string
+		now           time.Time
+		wantRuns      int
+		wantProcessed time.Time
+	}{
+		{"nothing due", catchUpOnce, created.Add(8 * time.Hour), 0, time.Time{}},
+		{"on time", catchUpSkip, created.Add(9*time.Hour + 30*time.Second), 1, created.Add(9 * time.Hour)},
+		{"skip missed", catchUpSkip, created.AddDate(0, 0, 2).Add(10 * time.Hour), 0, created.AddDate(0, 0, 2).Add(9 * time.Hour)},
+		{"once missed", catchUpOnce, created.AddDate(0, 0, 2).Add(10 * time.Hour), 1, created.AddDate(0, 0, 2).Add(9 * time.Hour)},
+		{"all missed", catchUpAll, created.AddDate(0, 0, 2).Add(10 * time.Hour), 3, created.AddDate(0, 0, 2).Add(9 * time.Hour)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			job := newTestJob("0 9 * * *", tt.catchUp, created)
+			runs, processed, err := job.dueRuns(tt.now)
+			if err != nil {
+				t.Fatalf("dueRuns() error = %v", err)
+			}
+			if len(runs) != tt.wantRuns {
+				t.Errorf("dueRuns() returned %d runs, want %d", len(runs), tt.wantRuns)
+			}
+			if !processed.Equal(tt.wantProcessed) {
+				t.Errorf("dueRuns() processed until %s, want %s", processed, tt.wantProcessed)
+			}
+		})
+	}
+}
+
+func TestScheduledJobDueRunsAfterLastRun(t *testing.T) {
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	job := newTestJob("0 9 * * *", catchUpAll, created)
+	lastRun := created.Add(9 * time.Hour)
+	job.LastRun = &lastRun
+
+	runs, processed, err := job.dueRuns(created.Add(9*time.Hour + 30*time.Second))
+	if err != nil {
+		t.Fatalf("dueRuns() error = %v", err)
+	}
+	if len(runs) != 0 || !processed.IsZero() {
+		t.Errorf("Expected no runs after the last run, got %d", len(runs))
+	}
+}
+
+func TestScheduledJobRenderText(t *testing.T) {
+	job := newTestJob("0 9 * * *", catchUpOnce, time.Time{})
+	job.Timezone = "Asia/Kolkata"
+	job.Text = `Stand-up {{ .ScheduledAt.Format "2006-01-02 15:04" }}, tomorrow is {{ date "Monday" (addDays .ScheduledAt 1) }} (run {{ .RunCount }})`
+	job.RunCount = 4
+
+	scheduledAt := time.Date(2024, 1, 1, 3, 30, 0, 0, time.UTC)
+	text, err := job.renderText(scheduledAt, scheduledAt)
+	if err != nil {
+		t.Fatalf("renderText() error = %v", err)
+	}
+	expected := "Stand-up 2024-01-01 09:00, tomorrow is Tuesday (run 5)"
+	if text != expected {
+		t.Errorf("renderText() = %q, want %q", text, expected)
+	}
+}
+
+func TestRunDueJobsRetriesFailedRuns(t *testing.T) {
+	var sent int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sent++
+		if sent == 2 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"id": "message1"})
+	}))
+	defer server.Close()
+	client, err := webex.NewClient("test-token", &webexsdk.Config{BaseURL: server.URL, MaxRetries: 0})
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	app := &Application{Client: client, StateDir: t.TempDir()}
+
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	job := newTestJob("0 9 * * *", catchUpAll, created)
+	job.RoomID, job.PersonEmail = "", "a@example.com"
+	err = app.updateSchedules(func(store *scheduleStore) error {
+		store.Jobs = append(store.Jobs, job)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("updateSchedules() error = %v", err)
+	}
+
+	now := created.AddDate(0, 0, 2).Add(10 * time.Hour)
+	app.runDueJobs(now)
+	store, err := app.loadSchedules()
+	if err != nil {
+		t.Fatalf("loadSchedules() error = %v", err)
+	}
+	stored := store.Jobs[0]
+	if stored.RunCount != 1 || stored.LastRun == nil || !stored.LastRun.Equal(created.Add(9*time.Hour)) {
+		t.Errorf("Expected the job to stop before the failed run, got %d runs, last %v", stored.RunCount, stored.LastRun)
+	}
+
+	if stored.Failures != 1 || stored.RetryAt == nil || !stored.RetryAt.Equal(now.Add(scheduleRetryBackoff)) {
+		t.Errorf("Expected a retry after the backoff, got %d failures, retry at %v", stored.Failures, stored.RetryAt)
+	}
+
+	// Nothing is sent before the backoff is over
+	app.runDueJobs(now.Add(time.Second))
+	if sent != 2 {
+		t.Errorf("Expected no send attempt during the backoff, got %d", sent)
+	}
+
+	app.runDueJobs(now.Add(scheduleRetryBackoff))
+	store, err = app.loadSchedules()
+	if err != nil {
+		t.Fatalf("loadSchedules() error = %v", err)
+	}
+	stored = store.Jobs[0]
+	if stored.Failures != 0 || stored.RetryAt != nil {
+		t.Errorf("Expected the failures to be reset, got %d, retry at %v", stored.Failures, stored.RetryAt)
+	}
+	if stored.RunCount != 3 || !stored.LastRun.Equal(created.AddDate(0, 0, 2).Add(9*time.Hour)) {
+		t.Errorf("Expected the failed run to be retried, got %d runs, last %v", stored.RunCount, stored.LastRun)
+	}
+	if sent != 4 {
+		t.Errorf("Expected 4 send attempts, got %d", sent)
+	}
+}
+
+func TestRunDueJobsSkipsRunAfterMaxAttempts(t *testing.T) {
+	var sent int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sent++
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+	client, err := webex.NewClient("test-token", &webexsdk.Config{BaseURL: server.URL, MaxRetries: 0})
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	app := &Application{Client: client, StateDir: t.TempDir()}
+
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	job := newTestJob("0 9 * * *", catchUpOnce, created)
+	job.RoomID, job.PersonEmail = "", "a@example.com"
+	err = app.updateSchedules(func(store *scheduleStore) error {
+		store.Jobs = append(store.Jobs, job)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("updateSchedules() error = %v", err)
+	}
+
+	now := created.Add(10 * time.Hour)
+	for i := 0; i < scheduleMaxAttempts; i++ {
+		app.runDueJobs(now)
+		now = now.Add(scheduleMaxRetryBackoff)
+	}
+	store, err := app.loadSchedules()
+	if err != nil {
+		t.Fatalf("loadSchedules() error = %v", err)
+	}
+	stored := store.Jobs[0]
+	if sent != scheduleMaxAttempts || stored.LastRun == nil || !stored.LastRun.Equal(created.Add(9*time.Hour)) || stored.Failures != 0 {
+		t.Errorf("Expected the run to be skipped after %d attempts, got %d attempts, last %v, %d failures", scheduleMaxAttempts, sent, stored.LastRun, stored.Failures)
+	}
+}
+
+func TestScheduledJobRetryBackoff(t *testing.T) {
+	tests := []struct {
+		failures int
+		expected time.Duration
+	}{
+		{1, time.Minute},
+		{2, 2 * time.Minute},
+		{4, 8 * time.Minute},
+		{7, time.Hour},
+	}
+	for _, tt := range tests {
+		job := &ScheduledJob{Failures: tt.failures}
+		if got := job.retryBackoff(); got != tt.expected {
+			t.Errorf("retryBackoff() with %d failures = %s, want %s", tt.failures, got, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,488 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/WebexCommunity/webex-go-sdk/v2/rooms"
+	"github.com/urfave/cli/v2"
+)
+
+// Costs order the terms of a selector so that the ones needing extra API
+// calls are evaluated last
+const (
+	selectorCostRoom = iota
+	selectorCostMembership
+	selectorCostMembers
+)
+
+var selectorTermPattern = regexp.MustCompile(`^(!?)([A-Za-z]+)(?:(!=|!~|<=|>=|=|~|<|>)(.*))?$`)
+
+// selectorFields maps each field to the operators it accepts. Fields
+// without operators are predicates.
+var selectorFields = map[string][]string{
+	"id":           {"=", "!="},
+	"title":        {"=", "!=", "~", "!~"},
+	"type":         {"=", "!="},
+	"team":         {"=", "!="},
+	"created":      {"<", "<=", ">", ">="},
+	"lastactivity": {"<", "<=", ">", ">="},
+	"members":      {"=", "!=", "<", "<=", ">", ">="},
+	"owner":        nil,
+	"moderator":    nil,
+	"locked":       nil,
+}
+
+var selectedRoomHeader = []string{"id", "title", "type", "teamId", "locked", "lastActivity", "created"}
+
+// selectorTerm is one condition of a room selector
+type selectorTerm struct {
+	Field  string
+	Op     string
+	Value  string
+	Negate bool
+
+	regex  *regexp.Regexp
+	time   time.Time
+	number int
+}
+
+// roomSelector matches rooms against all of its terms
+type roomSelector struct {
+	*Application
+	Terms []*selectorTerm
+
+	moderated    map[string]bool
+	memberCounts map[string]int
+}
+
+// RoomSelectCMD function
+func (app *Application) RoomSelectCMD() *cli.Command {
+	return &cli.Command{
+		Name:      "select",
+		Aliases:   []string{"sel"},
+		Usage:     "Preview the rooms a selector expression matches",
+		ArgsUsage: "<expression>",
+		Description: "Terms are separated by spaces or 'and', and a room must match all of them. " +
+			"Fields are title (= != ~ !~), type, team and id (= !=), created and lastActivity (< <= > >=) and members (= != < <= > >=). " +
+			"Predicates are owner, moderator and locked, negated with a leading '!'. " +
+			"Times are dates such as 2024-06-01, now-30d, today, this-week, this-month or this-year. " +
+			"eg. 'locked team=Platform lastActivity>=this-month' or 'title~\"(?i)^test\" members<3'",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:     "output",
+				Aliases:  []string{"o"},
+				Value:    outputTable,
+				Usage:    "Output format, one of table, csv or json. Default is table",
+				Required: false,
+			},
+		},
+		Action: func(c *cli.Context) error {
+			format := c.String("output")
+			if err := validateOutputFormat(format); err != nil {
+				return err
+			}
+			if c.Args().Len() == 0 {
+				return errors.New("a selector expression is required")
+			}
+			selected, err := app.SelectRooms(strings.Join(c.Args().Slice(), " "), time.Now())
+			if err != nil {
+				return err
+			}
+			rows := make([][]string, 0, len(selected))
+			for _, room := range selected {
+				rows = append(rows, []string{room.ID, room.Title, room.Type, room.TeamID, strconv.FormatBool(room.IsLocked), formatReportTime(room.LastActivity), formatReportTime(room.Created)})
+			}
+			return writeOutput(os.Stdout, format, selectedRoomHeader, rows, selected)
+		},
+	}
+}
+
+// selectFlag is the --select flag of bulk room commands
+func selectFlag() *cli.StringFlag {
+	return &cli.StringFlag{
+		Name:     "select",
+		Aliases:  []string{"sel"},
+		Value:    "",
+		Usage:    "Selector expression choosing the group rooms, eg. 'locked team=Platform lastActivity>=this-month'. Preview it with rooms select. Combined with --roomsidscsv or --team only rooms selected by all of them are kept",
+		Required: false,
+	}
+}
+
+// SelectRooms returns the rooms of the authenticated user matching the
+// selector expression
+func (app *Application) SelectRooms(expr string, now time.Time) ([]*rooms.Room, error) {
+	selector, err := app.compileSelector(expr, now)
+	if err != nil {
+		return nil, err
+	}
+	roomType, teamID := selector.listFilters()
+	allRooms, err := app.ListRooms(roomType, teamID, 0)
+	if err != nil {
+		return nil, err
+	}
+	selected := make([]*rooms.Room, 0)
+	for i := range allRooms {
+		matched, err := selector.match(&allRooms[i])
+		if err != nil {
+			return nil, err
+		}
+		if matched {
+			selected = append(selected, &allRooms[i])
+		}
+	}
+	return selected, nil
+}
+
+// selectRoomIDs returns the IDs of the group rooms matching the selector
+// expression. Matching no room is an error, since no IDs means all rooms to
+// the bulk commands.
+func (app *Application) selectRoomIDs(expr string) ([]string, error) {
+	selected, err := app.SelectRooms(expr, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	roomIDs := make([]string, 0, len(selected))
+	for _, room := range selected {
+		if room.Type != "direct" {
+			roomIDs = append(roomIDs, room.ID)
+		}
+	}
+	if len(roomIDs) == 0 {
+		return nil, fmt.Errorf("No group rooms match %s", expr)
+	}
+	return roomIDs, nil
+}
+
+// compileSelector parses a selector expression and resolves its team
+// names and times
+func (app *Application) compileSelector(expr string, now time.Time) (*roomSelector, error) {
+	tokens, err := tokenizeSelector(expr)
+	if err != nil {
+		return nil, err
+	}
+	selector := &roomSelector{Application: app, memberCounts: make(map[string]int)}
+	for _, token := range tokens {
+		if strings.EqualFold(token, "and") {
+			continue
+		}
+		term, err := parseSelectorTerm(token, now)
+		if err != nil {
+			return nil, err
+		}
+		if term.Field == "team" {
+			team, err := app.resolveTeam(term.Value)
+			if err != nil {
+				return nil, err
+			}
+			term.Value = team.ID
+		}
+		selector.Terms = append(selector.Terms, term)
+	}
+	if len(selector.Terms) == 0 {
+		return nil, errors.New("a selector expression is required")
+	}
+	sort.SliceStable(selector.Terms, func(i, j int) bool {
+		return selector.Terms[i].cost() < selector.Terms[j].cost()
+	})
+	return selector, nil
+}
+
+// tokenizeSelector splits an expression on spaces outside of double quotes
+// and of /regex/ values following a ~ operator
+func tokenizeSelector(expr string) ([]string, error) {
+	var tokens []string
+	var current strings.Builder
+	var quote rune
+	var prev rune
+	for _, r := range expr {
+		switch {
+		case quote != 0:
+			current.WriteRune(r)
+			if r == quote && prev != '\\' {
+				quote = 0
+			}
+		case r == '"' || (r == '/' && prev == '~'):
+			quote = r
+			current.WriteRune(r)
+		case r == ' ' || r == '\t' || r == '\n':
+			if current.Len() > 0 {
+				tokens = append(tokens, current.String())
+				current.Reset()
+			}
+		default:
+			current.WriteRune(r)
+		}
+		prev = r
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c in selector %s", quote, expr)
+	}
+	if current.Len() > 0 {
+		tokens = append(tokens, current.String())
+	}
+	return tokens, nil
+}
+
+// parseSelectorTerm parses a term such as title~/^ops/, members>=10 or
+// !owner
+func parseSelectorTerm(token string, now time.Time) (*selectorTerm, error) {
+	m := selectorTermPattern.FindStringSubmatch(token)
+	if m == nil {
+		return nil, fmt.Errorf("invalid selector term %s", token)
+	}
+	term := &selectorTerm{Negate: m[1] == "!", Field: strings.ToLower(m[2]), Op: m[3], Value: unquoteSelectorValue(m[4])}
+	ops, ok := selectorFields[term.Field]
+	if !ok {
+		return nil, fmt.Errorf("unknown selector field %s in %s", m[2], token)
+	}
+	if ops == nil {
+		if term.Op != "" {
+			return nil, fmt.Errorf("%s is a predicate and takes no value, use %s or !%s", term.Field, term.Field, term.Field)
+		}
+		return term, nil
+	}
+	if term.Negate {
+		return nil, fmt.Errorf("only predicates can be negated with '!', use the != operator in %s", token)
+	}
+	if term.Op == "" || term.Value == "" {
+		return nil, fmt.Errorf("%s needs an operator and a value, eg. %s%s<value>", term.Field, term.Field, ops[0])
+	}
+	if !containsString(ops, term.Op) {
+		return nil, fmt.Errorf("%s supports the operators %s", term.Field, strings.Join(ops, " "))
+	}
+
+	var err error
+	switch term.Field {
+	case "title":
+		if term.Op == "~" || term.Op == "!~" {
+			if term.regex, err = regexp.Compile(term.Value); err != nil {
+				return nil, fmt.Errorf("invalid title regex: %v", err)
+			}
+		}
+	case "type":
+		term.Value = strings.ToLower(term.Value)
+		if term.Value != "group" && term.Value != "direct" {
+			return nil, errors.New("type is either group or direct")
+		}
+	case "created", "lastactivity":
+		if term.time, err = parseSelectorTime(term.Value, now); err != nil {
+			return nil, err
+		}
+	case "members":
+		if term.number, err = strconv.Atoi(term.Value); err != nil || term.number < 0 {
+			return nil, fmt.Errorf("members needs a number, got %s", term.Value)
+		}
+	}
+	return term, nil
+}
+
+func unquoteSelectorValue(value string) string {
+	if len(value) >= 2 {
+		first, last := value[0], value[len(value)-1]
+		if (first == '"' && last == '"') || (first == '/' && last == '/') {
+			return strings.ReplaceAll(value[1:len(value)-1], `\`+string(first), string(first))
+		}
+	}
+	return value
+}
+
+// parseSelectorTime parses a date, an RFC 3339 time, now-<age> or one of
+// today, this-week, this-month and this-year
+func parseSelectorTime(value string, now time.Time) (time.Time, error) {
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	switch strings.ToLower(value) {
+	case "now":
+		return now, nil
+	case "today":
+		return day, nil
+	case "this-week":
+		// Weeks start on Monday
+		return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7)), nil
+	case "this-month":
+		return time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location()), nil
+	case "this-year":
+		return time.Date(now.Year(), 1, 1, 0, 0, 0, 0, now.Location()), nil
+	}
+	if age, ok := strings.CutPrefix(strings.ToLower(value), "now-"); ok {
+		d, err := parseAge(age)
+		if err != nil {
+			return time.Time{}, err
+		}
+		return now.Add(-d), nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", value, now.Location()); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("%s is not a valid time, use eg. 2024-06-01, now-30d or this-month", value)
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func (term *selectorTerm) cost() int {
+	switch term.Field {
+	case "moderator":
+		return selectorCostMembership
+	case "members":
+		return selectorCostMembers
+	}
+	return selectorCostRoom
+}
+
+// listFilters returns the room type and team the listing can be narrowed to
+// on the server
+func (selector *roomSelector) listFilters() (string, string) {
+	roomType, teamID := "", ""
+	for _, term := range selector.Terms {
+		if term.Op != "=" {
+			continue
+		}
+		switch term.Field {
+		case "type":
+			roomType = term.Value
+		case "team":
+			teamID = term.Value
+		}
+	}
+	return roomType, teamID
+}
+
+// match reports whether room matches every term. Moderator and member
+// lookups are only made for rooms that match the other terms.
+func (selector *roomSelector) match(room *rooms.Room) (bool, error) {
+	for _, term := range selector.Terms {
+		matched, err := selector.matchTerm(term, room)
+		if err != nil || !matched {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+func (selector *roomSelector) matchTerm(term *selectorTerm, room *rooms.Room) (bool, error) {
+	var matched bool
+	switch term.Field {
+	case "id":
+		matched = compareStrings(term.Op, room.ID, term.Value)
+	case "title":
+		switch term.Op {
+		case "~":
+			matched = term.regex.MatchString(room.Title)
+		case "!~":
+			matched = !term.regex.MatchString(room.Title)
+		default:
+			matched = compareStrings(term.Op, strings.ToLower(room.Title), strings.ToLower(term.Value))
+		}
+	case "type":
+		matched = compareStrings(term.Op, room.Type, term.Value)
+	case "team":
+		matched = compareStrings(term.Op, room.TeamID, term.Value)
+	case "created":
+		matched = room.Created != nil && compareTimes(term.Op, *room.Created, term.time)
+	case "lastactivity":
+		last := room.LastActivity
+		if last == nil {
+			last = room.Created
+		}
+		matched = last != nil && compareTimes(term.Op, *last, term.time)
+	case "owner":
+		matched = selector.isOwner(room)
+	case "locked":
+		matched = room.IsLocked
+	case "moderator":
+		moderated, err := selector.isModerator(room.ID)
+		if err != nil {
+			return false, err
+		}
+		matched = moderated
+	case "members":
+		count, err := selector.memberCount(room.ID)
+		if err != nil {
+			return false, err
+		}
+		matched = compareInts(term.Op, count, term.number)
+	}
+	if term.Negate {
+		return !matched, nil
+	}
+	return matched, nil
+}
+
+// isModerator reports whether the authenticated user moderates the room.
+// The user's memberships are listed once.
+func (selector *roomSelector) isModerator(roomID string) (bool, error) {
+	if selector.moderated == nil {
+		items, err := selector.ListAllMemberships("")
+		if err != nil {
+			return false, err
+		}
+		selector.moderated = make(map[string]bool, len(items))
+		for _, membership := range items {
+			selector.moderated[membership.RoomID] = membership.IsModerator
+		}
+	}
+	return selector.moderated[roomID], nil
+}
+
+func (selector *roomSelector) memberCount(roomID string) (int, error) {
+	if count, ok := selector.memberCounts[roomID]; ok {
+		return count, nil
+	}
+	items, err := selector.ListAllMemberships(roomID)
+	if err != nil {
+		return 0, err
+	}
+	selector.memberCounts[roomID] = len(items)
+	return len(items), nil
+}
+
+func compareStrings(op string, a, b string) bool {
+	if op == "!=" {
+		return a != b
+	}
+	return a == b
+}
+
+func compareTimes(op string, a, b time.Time) bool {
+	switch op {
+	case "<":
+		return a.Before(b)
+	case "<=":
+		return !a.After(b)
+	case ">":
+		return a.After(b)
+	}
+	return !a.Before(b)
+}
+
+func compareInts(op string, a, b int) bool {
+	switch op {
+	case "!=":
+		return a != b
+	case "<":
+		return a < b
+	case "<=":
+		return a <= b
+	case ">":
+		return a > b
+	case ">=":
+		return a >= b
+	}
+	return a == b
+}
@@ -0,0 +1,267 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	webex "github.com/WebexCommunity/webex-go-sdk/v2"
+	"github.com/WebexCommunity/webex-go-sdk/v2/memberships"
+	"github.com/WebexCommunity/webex-go-sdk/v2/people"
+	"github.com/WebexCommunity/webex-go-sdk/v2/rooms"
+	"github.com/WebexCommunity/webex-go-sdk/v2/teams"
+	"github.com/WebexCommunity/webex-go-sdk/v2/webexsdk"
+	"github.com/urfave/cli/v2"
+)
+
+func TestTokenizeSelector(t *testing.T) {
+	tokens, err := tokenizeSelector(`locked  team="Platform Team" and title~/^ops room/ members>=3`)
+	if err != nil {
+		t.Fatalf("tokenizeSelector() error = %v", err)
+	}
+	expected := []string{"locked", `team="Platform Team"`, "and", "title~/^ops room/", "members>=3"}
+	if strings.Join(tokens, "|") != strings.Join(expected, "|") {
+		t.Errorf("tokenizeSelector() = %q, want %q", tokens, expected)
+	}
+
+	if _, err := tokenizeSelector(`title~"open`); err == nil {
+		t.Error("Expected an error for an unterminated quote")
+	}
+}
+
+func TestParseSelectorTerm(t *testing.T) {
+	now := time.Date(2024, 6, 19, 15, 0, 0, 0, time.UTC)
+	tests := []struct {
+		token string
+		field string
+		op    string
+		value string
+	}{
+		{"title~/^ops/", "title", "~", "^ops"},
+		{`title="Ops Room"`, "title", "=", "Ops Room"},
+		{"lastActivity>=this-month", "lastactivity", ">=", "this-month"},
+		{"members!=0", "members", "!=", "0"},
+		{"!owner", "owner", "", ""},
+		{"type=GROUP", "type", "=", "group"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.token, func(t *testing.T) {
+			term, err := parseSelectorTerm(tt.token, now)
+			if err != nil {
+				t.Fatalf("parseSelectorTerm() error = %v", err)
+			}
+			if term.Field != tt.field || term.Op != tt.op || term.Value != tt.value {
+				t.Errorf("parseSelectorTerm() = %+v", term)
+			}
+		})
+	}
+
+	for _, token := range []string{"color=red", "owner=true", "!title=x", "members>many", "created=2024-01-01", "type=public", "title~/(/", "members"} {
+		if _, err := parseSelectorTerm(token, now); err == nil {
+			t.Errorf("Expected an error for %s", token)
+		}
+	}
+}
+
+func TestParseSelectorTime(t *testing.T) {
+	// A Wednesday
+	now := time.Date(2024, 6, 19, 15, 30, 0, 0, time.UTC)
+	tests := map[string]time.Time{
+		"today":      time.Date(2024, 6, 19, 0, 0, 0, 0, time.UTC),
+		"this-week":  time.Date(2024, 6, 17, 0, 0, 0, 0, time.UTC),
+		"this-month": time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC),
+		"this-year":  time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		"now-2d":     now.Add(-48 * time.Hour),
+		"2024-05-01": time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC),
+	}
+	for value, expected := range tests {
+		got, err := parseSelectorTime(value, now)
+		if err != nil {
+			t.Fatalf("parseSelectorTime(%s) error = %v", value, err)
+		}
+		if !got.Equal(expected) {
+			t.Errorf("parseSelectorTime(%s) = %s, want %s", value, got, expected)
+		}
+	}
+	if _, err := parseSelectorTime("30d", now); err == nil {
+		t.Error("Expected an error for a bare age")
+	}
+}
+
+func TestSelectRooms(t *testing.T) {
+	now := time.Date(2024, 6, 19, 15, 0, 0, 0, time.UTC)
+	recent := now.Add(-24 * time.Hour)
+	old := now.AddDate(0, -3, 0)
+	allRooms := []rooms.Room{
+		{ID: "r1", Title: "Ops Alerts", Type: "group", TeamID: "team1", IsLocked: true, CreatorID: "me", LastActivity: &recent},
+		{ID: "r2", Title: "Ops Chat", Type: "group", TeamID: "team1", LastActivity: &recent},
+		{ID: "r3", Title: "Old Ops", Type: "group", TeamID: "team1", IsLocked: true, LastActivity: &old},
+		{ID: "r4", Title: "Someone", Type: "direct", LastActivity: &recent},
+	}
+	roomMembers := map[string]int{"r1": 5, "r2": 2, "r3": 1, "r4": 2}
+
+	var memberListings int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		var items interface{}
+		switch r.URL.Path {
+		case "/teams":
+			items = []teams.Team{{ID: "team1", Name: "Platform"}}
+		case "/rooms":
+			filtered := []rooms.Room{}
+			for _, room := range allRooms {
+				if teamID := r.URL.Query().Get("teamId"); teamID != "" && room.TeamID != teamID {
+					continue
+				}
+				filtered = append(filtered, room)
+			}
+			items = filtered
+		case "/memberships":
+			roomID := r.URL.Query().Get("roomId")
+			if roomID == "" {
+				items = []memberships.Membership{{RoomID: "r1", IsModerator: true}, {RoomID: "r3", IsModerator: false}}
+				break
+			}
+			memberListings++
+			members := []memberships.Membership{}
+			for i := 0; i < roomMembers[roomID]; i++ {
+				members = append(members, memberships.Membership{RoomID: roomID})
+			}
+			items = members
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"items": items})
+	}))
+	defer server.Close()
+	client, err := webex.NewClient("test-token", &webexsdk.Config{BaseURL: server.URL, MaxRetries: 0})
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	app := &Application{Client: client, Me: &people.Person{ID: "me"}}
+
+	tests := []struct {
+		expr     string
+		expected []string
+	}{
+		{"locked team=Platform lastActivity>=this-month", []string{"r1"}},
+		{"title~/(?i)^ops/", []string{"r1", "r2"}},
+		{`title="old ops"`, []string{"r3"}},
+		{"type=direct", []string{"r4"}},
+		{"!locked and type!=direct", []string{"r2"}},
+		{"owner", []string{"r1"}},
+		{"moderator", []string{"r1"}},
+		{"lastActivity<now-30d", []string{"r3"}},
+		{"members>=2 type=group", []string{"r1", "r2"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			selected, err := app.SelectRooms(tt.expr, now)
+			if err != nil {
+				t.Fatalf("SelectRooms() error = %v", err)
+			}
+			ids := make([]string, 0, len(selected))
+			for _, room := range selected {
+				ids = append(ids, room.ID)
+			}
+			if strings.Join(ids, ",") != strings.Join(tt.expected, ",") {
+				t.Errorf("SelectRooms(%s) = %v, want %v", tt.expr, ids, tt.expected)
+			}
+		})
+	}
+
+	memberListings = 0
+	if _, err := app.SelectRooms("members>=2 locked", now); err != nil {
+		t.Fatalf("SelectRooms() error = %v", err)
+	}
+	if memberListings != 2 {
+		t.Errorf("Expected members to be counted only for the 2 locked rooms, got %d listings", memberListings)
+	}
+
+	roomIDs, err := app.selectRoomIDs("!locked")
+	if err != nil {
+		t.Fatalf("selectRoomIDs() error = %v", err)
+	}
+	if strings.Join(roomIDs, ",") != "r2" {
+		t.Errorf("Expected direct rooms to be left out, got %v", roomIDs)
+	}
+	if _, err := app.selectRoomIDs("type=direct"); err == nil {
+		t.Error("Expected an error when no group room matches")
+	}
+}
+
+func TestTargetRoomIDsCombinesSelectors(t *testing.T) {
+	allRooms := []rooms.Room{
+		{ID: "r1", Title: "Ops Alerts", Type: "group", TeamID: "team1", IsLocked: true},
+		{ID: "r2", Title: "Ops Chat", Type: "group", TeamID: "team1"},
+		{ID: "r5", Title: "Other", Type: "group", IsLocked: true},
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		var items interface{}
+		switch r.URL.Path {
+		case "/teams":
+			items = []teams.Team{{ID: "team1", Name: "Platform"}}
+		case "/rooms":
+			filtered := []rooms.Room{}
+			for _, room := range allRooms {
+				if teamID := r.URL.Query().Get("teamId"); teamID != "" && room.TeamID != teamID {
+					continue
+				}
+				filtered = append(filtered, room)
+			}
+			items = filtered
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"items": items})
+	}))
+	defer server.Close()
+	client, err := webex.NewClient("test-token", &webexsdk.Config{BaseURL: server.URL, MaxRetries: 0})
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	app := &Application{Client: client, Me: &people.Person{ID: "me"}}
+
+	targets := func(args ...string) ([]string, error) {
+		var roomIDs []string
+		cliApp := &cli.App{
+			Flags: []cli.Flag{
+				&cli.StringFlag{Name: "roomsidscsv"},
+				&cli.StringFlag{Name: "team"},
+				selectFlag(),
+			},
+			Action: func(c *cli.Context) error {
+				var err error
+				roomIDs, err = app.targetRoomIDs(c)
+				return err
+			},
+		}
+		err := cliApp.Run(append([]string{"webex-teams-cli"}, args...))
+		return roomIDs, err
+	}
+
+	roomIDs, err := targets("--team", "Platform", "--select", "locked")
+	if err != nil {
+		t.Fatalf("targetRoomIDs() error = %v", err)
+	}
+	if strings.Join(roomIDs, ",") != "r1" {
+		t.Errorf("Expected only the locked team room, got %v", roomIDs)
+	}
+	if _, err := targets("--team", "Platform", "--select", "title=Other"); err == nil {
+		t.Error("Expected an error when no room is selected by both --team and --select")
+	}
+	roomIDs, err = targets("--select", "locked")
+	if err != nil {
+		t.Fatalf("targetRoomIDs() error = %v", err)
+	}
+	if strings.Join(roomIDs, ",") != "r1,r5" {
+		t.Errorf("Expected the locked rooms, got %v", roomIDs)
+	}
+}
+
+func TestIntersectRoomIDs(t *testing.T) {
+	roomIDs := intersectRoomIDs([][]string{{"a", "b", "c", "b"}, {"c", "b", "d"}, {"b", "c"}})
+	if strings.Join(roomIDs, ",") != "b,c" {
+		t.Errorf("intersectRoomIDs() = %v", roomIDs)
+	}
+}
@@ -1,12 +1,14 @@
 package cmd
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
 
 	"github.com/WebexCommunity/webex-go-sdk/v2/people"
+	"github.com/go-chi/chi"
 	"github.com/urfave/cli/v2"
 )
 
@@ -214,8 +216,11 @@ func TestMessageRelaySendMessageEmptyBody(t *testing.T) {
 	}
 
 	// Authenticated but empty body → 400
-	req := httptest.NewRequest("POST", "/room123", strings.NewReader(""))
+	req := httptest.NewRequest("POST", "/550e8400-e29b-41d4-a716-446655440000", strings.NewReader(""))
 	req.Header.Set("X-Message-Key", "test-key")
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("webexroom", "550e8400-e29b-41d4-a716-446655440000")
+	req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
 	w := httptest.NewRecorder()
 
 	app.sendMessagePOST(w, req)
@@ -0,0 +1,496 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/WebexCommunity/webex-go-sdk/v2/memberships"
+	"github.com/WebexCommunity/webex-go-sdk/v2/rooms"
+	log "github.com/sirupsen/logrus"
+	"github.com/urfave/cli/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// SpacesConfig is the declared state of a set of rooms
+type SpacesConfig struct {
+	Rooms []SpaceConfig `yaml:"rooms"`
+}
+
+// SpaceConfig declares a room and its members. Rooms are matched to live
+// rooms by title, within the team when one is given.
+type SpaceConfig struct {
+	Title       string   `yaml:"title"`
+	Team        string   `yaml:"team,omitempty"`
+	Description string   `yaml:"description,omitempty"`
+	Locked      bool     `yaml:"locked,omitempty"`
+	Members     []string `yaml:"members,omitempty"`
+	Moderators  []string `yaml:"moderators,omitempty"`
+}
+
+// Actions of a spaceChange
+const (
+	spaceCreate       = "create"
+	spaceUpdate       = "update"
+	spaceAddMember    = "add"
+	spaceUpdateMember = "set"
+	spaceRemoveMember = "remove"
+)
+
+// spaceChange is a single step needed to bring a room to its declared state
+type spaceChange struct {
+	Action       string
+	Field        string
+	Email        string
+	Moderator    bool
+	MembershipID string
+}
+
+// spacePlan is the diff of a declared room against its live state
+type spacePlan struct {
+	Config  SpaceConfig
+	RoomID  string
+	TeamID  string
+	Skipped string
+	Changes []spaceChange
+}
+
+// liveSpace is the live state of a room, as far as the config covers it
+type liveSpace struct {
+	Room        *roomDetails
+	Memberships map[string]memberships.Membership
+}
+
+// SpacesCMD function
+func (app *Application) SpacesCMD() *cli.Command {
+	return &cli.Command{
+		Name:    "spaces",
+		Aliases: []string{"sp"},
+		Usage:   "Manage rooms and their members declaratively from a YAML file",
+		Subcommands: []*cli.Command{
+			app.spacesPlanCMD(),
+			app.spacesApplyCMD(),
+		},
+		Action: func(c *cli.Context) error {
+			return nil
+		},
+	}
+}
+
+func spacesFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:     "file",
+			Aliases:  []string{"f"},
+			Value:    "",
+			Usage:    "Path to the spaces YAML file",
+			Required: true,
+		},
+		&cli.StringFlag{
+			Name:     "access",
+			Aliases:  []string{"a"},
+			Value:    "om",
+			Usage:    "Only existing rooms for which you have specified permissions of either 'a' (include all), 'o' (owner), 'm' (moderator) or 'om' (owner and moderator) are changed. Default is owner and moderator.",
+			Required: false,
+		},
+	}
+}
+
+func (app *Application) spacesPlanCMD() *cli.Command {
+	return &cli.Command{
+		Name:        "plan",
+		Aliases:     []string{"p"},
+		Description: "Show the changes needed to bring live rooms in line with the spaces file",
+		Flags:       spacesFlags(),
+		Action: func(c *cli.Context) error {
+			plans, err := app.planSpacesFromFlags(c)
+			if err != nil {
+				return err
+			}
+			printSpacePlans(plans, false)
+			return nil
+		},
+	}
+}
+
+func (app *Application) spacesApplyCMD() *cli.Command {
+	return &cli.Command{
+		Name:        "apply",
+		Aliases:     []string{"a"},
+		Description: "Apply the changes needed to bring live rooms in line with the spaces file",
+		Flags: append(spacesFlags(),
+			&cli.BoolFlag{
+				Name:     "prune",
+				Value:    false,
+				Usage:    "Remove members that are not declared in the spaces file",
+				Required: false,
+			},
+		),
+		Action: func(c *cli.Context) error {
+			plans, err := app.planSpacesFromFlags(c)
+			if err != nil {
+				return err
+			}
+			prune := c.Bool("prune")
+			printSpacePlans(plans, prune)
+			failed := 0
+			for _, plan := range plans {
+				if err := app.applySpacePlan(plan, prune); err != nil {
+					log.Errorf("Failed to apply %s: %s", plan.Config.Title, err.Error())
+					failed++
+				}
+			}
+			if failed > 0 {
+				return fmt.Errorf("Failed to apply %d room(s)", failed)
+			}
+			return nil
+		},
+	}
+}
+
+func (app *Application) planSpacesFromFlags(c *cli.Context) ([]*spacePlan, error) {
+	access := c.String("access")
+	if access != "a" && access != "o" && access != "m" && access != "om" {
+		return nil, errors.New("Allowed valued for access flag are a, o, m and om")
+	}
+	config, err := loadSpacesConfig(c.String("file"))
+	if err != nil {
+		return nil, err
+	}
+	return app.PlanSpaces(config, access)
+}
+
+// loadSpacesConfig reads and validates a spaces YAML file
+func loadSpacesConfig(filePath string) (*SpacesConfig, error) {
+	if strings.HasPrefix(filePath, "~") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, err
+		}
+		filePath = filepath.Join(home, filePath[len("~"):])
+	}
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	return parseSpacesConfig(data)
+}
+
+func parseSpacesConfig(data []byte) (*SpacesConfig, error) {
+	config := &SpacesConfig{}
+	if err := yaml.Unmarshal(data, config); err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool)
+	for i, space := range config.Rooms {
+		if strings.TrimSpace(space.Title) == "" {
+			return nil, fmt.Errorf("Room %d has no title", i+1)
+		}
+		key := strings.ToLower(space.Team) + "/" + space.Title
+		if seen[key] {
+			return nil, fmt.Errorf("Room %s is declared more than once", space.Title)
+		}
+		seen[key] = true
+	}
+	return config, nil
+}
+
+// desiredMembers maps the declared members' lower cased emails to whether
+// they should be moderators. Moderators listed under members too stay
+// moderators.
+func (space SpaceConfig) desiredMembers() map[string]bool {
+	desired := make(map[string]bool)
+	for _, member := range space.Members {
+		desired[strings.ToLower(strings.TrimSpace(member))] = false
+	}
+	for _, moderator := range space.Moderators {
+		desired[strings.ToLower(strings.TrimSpace(moderator))] = true
+	}
+	return desired
+}
+
+// diffSpace lists the changes that turn live into the declared space. A nil
+// live creates the room. self is never removed.
+func diffSpace(space SpaceConfig, live *liveSpace, self string) []spaceChange {
+	var changes []spaceChange
+	current := make(map[string]memberships.Membership)
+	if live == nil {
+		changes = append(changes, spaceChange{Action: spaceCreate})
+	} else {
+		if live.Room.Description != space.Description {
+			changes = append(changes, spaceChange{Action: spaceUpdate, Field: "description"})
+		}
+		if live.Room.IsLocked != space.Locked {
+			changes = append(changes, spaceChange{Action: spaceUpdate, Field: "locked"})
+		}
+		current = live.Memberships
+	}
+
+	return append(changes, diffMembers(space.desiredMembers(), current, self, live == nil)...)
+}
+
+// diffMembers lists the member changes that turn current into desired, which
+// maps lower cased emails to whether they should be moderators. Members not
+// in desired are listed as removals; self is never removed. When created is
+// set the room is new and self is already its member.
+func diffMembers(desired map[string]bool, current map[string]memberships.Membership, self string, created bool) []spaceChange {
+	var changes []spaceChange
+	emails := make([]string, 0, len(desired))
+	for emailAddress := range desired {
+		emails = append(emails, emailAddress)
+	}
+	sort.Strings(emails)
+	for _, emailAddress := range emails {
+		moderator := desired[emailAddress]
+		membership, ok := current[emailAddress]
+		if !ok {
+			if created && emailAddress == strings.ToLower(self) {
+				// The creator is added to new rooms by Webex
+				if moderator {
+					changes = append(changes, spaceChange{Action: spaceUpdateMember, Email: emailAddress, Moderator: true})
+				}
+				continue
+			}
+			changes = append(changes, spaceChange{Action: spaceAddMember, Email: emailAddress, Moderator: moderator})
+			continue
+		}
+		if membership.IsModerator != moderator {
+			changes = append(changes, spaceChange{Action: spaceUpdateMember, Email: emailAddress, Moderator: moderator, MembershipID: membership.ID})
+		}
+	}
+
+	var unmanaged []string
+	for emailAddress := range current {
+		if _, ok := desired[emailAddress]; !ok && emailAddress != strings.ToLower(self) {
+			unmanaged = append(unmanaged, emailAddress)
+		}
+	}
+	sort.Strings(unmanaged)
+	for _, emailAddress := range unmanaged {
+		changes = append(changes, spaceChange{Action: spaceRemoveMember, Email: emailAddress, MembershipID: current[emailAddress].ID})
+	}
+	return changes
+}
+
+// PlanSpaces diffs every declared room against its live state. Existing
+// rooms that fail the access check are skipped.
+func (app *Application) PlanSpaces(config *SpacesConfig, access string) ([]*spacePlan, error) {
+	accessApp := &AddPeopleApplication{Application: app, Access: access}
+	teamIDs := make(map[string]string)
+	roomsByTeam := make(map[string][]rooms.Room)
+
+	var plans []*spacePlan
+	for _, space := range config.Rooms {
+		plan := &spacePlan{Config: space}
+		plans = append(plans, plan)
+
+		if space.Team != "" {
+			teamID, ok := teamIDs[space.Team]
+			if !ok {
+				team, err := app.resolveTeam(space.Team)
+				if err != nil {
+					return nil, err
+				}
+				teamID = team.ID
+				teamIDs[space.Team] = teamID
+			}
+			plan.TeamID = teamID
+		}
+
+		candidates, ok := roomsByTeam[plan.TeamID]
+		if !ok {
+			var err error
+			if plan.TeamID != "" {
+				candidates, err = app.GetTeamRooms(plan.TeamID)
+			} else {
+				candidates, err = app.ListAllRooms("group", "")
+			}
+			if err != nil {
+				return nil, err
+			}
+			roomsByTeam[plan.TeamID] = candidates
+		}
+
+		var matches []rooms.Room
+		for _, room := range candidates {
+			if room.Title == space.Title {
+				matches = append(matches, room)
+			}
+		}
+		if len(matches) > 1 {
+			plan.Skipped = fmt.Sprintf("%d rooms are titled %s", len(matches), space.Title)
+			continue
+		}
+		if len(matches) == 0 {
+			plan.Changes = diffSpace(space, nil, app.Email)
+			continue
+		}
+
+		live, err := app.getLiveSpace(matches[0].ID)
+		if err != nil {
+			return nil, err
+		}
+		plan.RoomID = live.Room.ID
+		myMembership, ok := live.Memberships[strings.ToLower(app.Email)]
+		if !ok {
+			plan.Skipped = "you are not a member of this room"
+			continue
+		}
+		if !accessApp.checkAccess(app.Me, &live.Room.Room, myMembership) {
+			plan.Skipped = "you do not have the required access"
+			continue
+		}
+		plan.Changes = diffSpace(space, live, app.Email)
+	}
+	return plans, nil
+}
+
+func (app *Application) getLiveSpace(roomID string) (*liveSpace, error) {
+	room, err := app.getRoomDetails(roomID)
+	if err != nil {
+		return nil, err
+	}
+	items, err := app.ListAllMemberships(room.ID)
+	if err != nil {
+		return nil, err
+	}
+	live := &liveSpace{Room: room, Memberships: make(map[string]memberships.Membership)}
+	for _, membership := range items {
+		live.Memberships[strings.ToLower(membership.PersonEmail)] = membership
+	}
+	return live, nil
+}
+
+func printSpacePlans(plans []*spacePlan, prune bool) {
+	counts := make(map[string]int)
+	for _, plan := range plans {
+		if plan.Skipped != "" {
+			fmt.Printf("! %s: skipped, %s\n", plan.Config.Title, plan.Skipped)
+			continue
+		}
+		for _, change := range plan.Changes {
+			counts[change.Action]++
+			fmt.Println(describeSpaceChange(plan.Config, change, prune))
+		}
+	}
+	fmt.Printf("Plan: %d to create, %d to update, %d to add, %d to change, %d to remove\n",
+		counts[spaceCreate], counts[spaceUpdate], counts[spaceAddMember], counts[spaceUpdateMember], counts[spaceRemoveMember])
+}
+
+func describeSpaceChange(space SpaceConfig, change spaceChange, prune bool) string {
+	switch change.Action {
+	case spaceCreate:
+		if space.Team != "" {
+			return fmt.Sprintf("+ create room %s in team %s", space.Title, space.Team)
+		}
+		return fmt.Sprintf("+ create room %s", space.Title)
+	case spaceUpdate:
+		if change.Field == "locked" {
+			return fmt.Sprintf("~ update %s: locked = %t", space.Title, space.Locked)
+		}
+		return fmt.Sprintf("~ update %s: description = %q", space.Title, space.Description)
+	case spaceAddMember:
+		if change.Moderator {
+			return fmt.Sprintf("+ add %s to %s as moderator", change.Email, space.Title)
+		}
+		return fmt.Sprintf("+ add %s to %s", change.Email, space.Title)
+	case spaceUpdateMember:
+		return fmt.Sprintf("~ set %s in %s: moderator = %t", change.Email, space.Title, change.Moderator)
+	case spaceRemoveMember:
+		if !prune {
+			return fmt.Sprintf("- remove %s from %s (unmanaged, requires --prune)", change.Email, space.Title)
+		}
+		return fmt.Sprintf("- remove %s from %s", change.Email, space.Title)
+	}
+	return ""
+}
+
+// applySpacePlan executes a plan's changes in order. Removals only run when
+// prune is set.
+func (app *Application) applySpacePlan(plan *spacePlan, prune bool) error {
+	if plan.Skipped != "" {
+		return nil
+	}
+	space := plan.Config
+	var settingsUpdated bool
+	for _, change := range plan.Changes {
+		switch change.Action {
+		case spaceCreate:
+			room, err := app.createRoom(space.Title, space.Description, plan.TeamID)
+			if err != nil {
+				return err
+			}
+			plan.RoomID = room.ID
+			log.Infof("Created room %s", room.Title)
+			if space.Locked {
+				if _, err := app.updateRoom(room.ID, func(update map[string]interface{}) {
+					update["isLocked"] = true
+				}); err != nil {
+					return err
+				}
+			}
+		case spaceUpdate:
+			if settingsUpdated {
+				continue
+			}
+			settingsUpdated = true
+			if _, err := app.updateRoom(plan.RoomID, func(update map[string]interface{}) {
+				update["description"] = space.Description
+				update["isLocked"] = space.Locked
+			}); err != nil {
+				return err
+			}
+			log.Infof("Updated room %s", space.Title)
+		default:
+			if err := app.applyMemberChange(plan.RoomID, space.Title, change, prune); err != nil {
+				log.Error(err.Error())
+			}
+		}
+	}
+	return nil
+}
+
+// applyMemberChange adds, updates or removes a single room member. Removals
+// only run when prune is set.
+func (app *Application) applyMemberChange(roomID string, title string, change spaceChange, prune bool) error {
+	switch change.Action {
+	case spaceAddMember:
+		_, err := app.Client.Memberships().Create(&memberships.Membership{
+			RoomID:      roomID,
+			PersonEmail: change.Email,
+			IsModerator: change.Moderator,
+		})
+		if err != nil {
+			return fmt.Errorf("error adding %s: %w", change.Email, err)
+		}
+		log.Infof("Added %s to %s", change.Email, title)
+	case spaceUpdateMember:
+		membershipID := change.MembershipID
+		if membershipID == "" {
+			mbrPage, err := app.Client.Memberships().List(&memberships.ListOptions{RoomID: roomID, PersonEmail: change.Email, Max: 1})
+			if err != nil {
+				return err
+			}
+			if len(mbrPage.Items) == 0 {
+				return fmt.Errorf("%s is not a member of %s", change.Email, title)
+			}
+			membershipID = mbrPage.Items[0].ID
+		}
+		if err := app.setModerator(membershipID, change.Moderator); err != nil {
+			return fmt.Errorf("error updating %s: %w", change.Email, err)
+		}
+		log.Infof("Set %s in %s, moderator: %t", change.Email, title, change.Moderator)
+	case spaceRemoveMember:
+		if !prune {
+			return nil
+		}
+		if err := app.Client.Memberships().Delete(change.MembershipID); err != nil {
+			return fmt.Errorf("error removing %s: %w", change.Email, err)
+		}
+		log.Infof("Removed %s from %s", change.Email, title)
+	}
+	return nil
+}
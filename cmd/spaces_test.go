@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/WebexCommunity/webex-go-sdk/v2/memberships"
+	"github.com/WebexCommunity/webex-go-sdk/v2/rooms"
+)
+
+func TestParseSpacesConfig(t *testing.T) {
+	data := []byte(`
+rooms:
+  - title: Project X
+    team: Platform
+    description: Project X coordination
+    locked: true
+    members:
+      - a@example.com
+    moderators:
+      - b@example.com
+  - title: Project Y
+`)
+	config, err := parseSpacesConfig(data)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(config.Rooms) != 2 {
+		t.Fatalf("Expected 2 rooms, got %d", len(config.Rooms))
+	}
+	space := config.Rooms[0]
+	if space.Title != "Project X" || space.Team != "Platform" || !space.Locked {
+		t.Errorf("Unexpected room: %+v", space)
+	}
+	if len(space.Members) != 1 || len(space.Moderators) != 1 {
+		t.Errorf("Expected 1 member and 1 moderator, got %v, %v", space.Members, space.Moderators)
+	}
+}
+
+func TestParseSpacesConfigInvalid(t *testing.T) {
+	if _, err := parseSpacesConfig([]byte("rooms:\n  - description: no title\n")); err == nil {
+		t.Error("Expected an error for a room without title")
+	}
+	if _, err := parseSpacesConfig([]byte("rooms:\n  - title: A\n  - title: A\n")); err == nil {
+		t.Error("Expected an error for a duplicate room")
+	}
+}
+
+func TestDiffSpaceCreate(t *testing.T) {
+	space := SpaceConfig{
+		Title:      "Project X",
+		Members:    []string{"a@example.com"},
+		Moderators: []string{"Me@example.com"},
+	}
+	changes := diffSpace(space, nil, "me@example.com")
+	if len(changes) != 3 {
+		t.Fatalf("Expected 3 changes, got %+v", changes)
+	}
+	if changes[0].Action != spaceCreate {
+		t.Errorf("Expected create first, got %s", changes[0].Action)
+	}
+	if changes[1].Action != spaceAddMember || changes[1].Email != "a@example.com" {
+		t.Errorf("Expected a@example.com to be added, got %+v", changes[1])
+	}
+	if changes[2].Action != spaceUpdateMember || changes[2].Email != "me@example.com" || !changes[2].Moderator {
+		t.Errorf("Expected creator to be made moderator, got %+v", changes[2])
+	}
+}
+
+func TestDiffSpaceExisting(t *testing.T) {
+	space := SpaceConfig{
+		Title:       "Project X",
+		Description: "new",
+		Members:     []string{"a@example.com", "b@example.com"},
+		Moderators:  []string{"c@example.com"},
+	}
+	live := &liveSpace{
+		Room: &roomDetails{Room: rooms.Room{ID: "room-1", Title: "Project X"}, Description: "old"},
+		Memberships: map[string]memberships.Membership{
+			"me@example.com": {ID: "m-me", IsModerator: true},
+			"a@example.com":  {ID: "m-a"},
+			"c@example.com":  {ID: "m-c"},
+			"d@example.com":  {ID: "m-d"},
+		},
+	}
+	changes := diffSpace(space, live, "me@example.com")
+
+	expected := []spaceChange{
+		{Action: spaceUpdate, Field: "description"},
+		{Action: spaceAddMember, Email: "b@example.com"},
+		{Action: spaceUpdateMember, Email: "c@example.com", Moderator: true, MembershipID: "m-c"},
+		{Action: spaceRemoveMember, Email: "d@example.com", MembershipID: "m-d"},
+	}
+	if len(changes) != len(expected) {
+		t.Fatalf("Expected %d changes, got %+v", len(expected), changes)
+	}
+	for i := range expected {
+		if changes[i] != expected[i] {
+			t.Errorf("Expected change %+v, got %+v", expected[i], changes[i])
+		}
+	}
+}
+
+func TestDiffSpaceInSync(t *testing.T) {
+	space := SpaceConfig{Title: "Project X", Locked: true, Moderators: []string{"me@example.com"}}
+	live := &liveSpace{
+		Room: &roomDetails{Room: rooms.Room{ID: "room-1", Title: "Project X", IsLocked: true}},
+		Memberships: map[string]memberships.Membership{
+			"me@example.com": {ID: "m-me", IsModerator: true},
+		},
+	}
+	if changes := diffSpace(space, live, "me@example.com"); len(changes) != 0 {
+		t.Errorf("Expected no changes, got %+v", changes)
+	}
+}
@@ -0,0 +1,344 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/WebexCommunity/webex-go-sdk/v2/messages"
+	"github.com/WebexCommunity/webex-go-sdk/v2/rooms"
+	log "github.com/sirupsen/logrus"
+	"github.com/urfave/cli/v2"
+)
+
+const staleStateFile = "stale.json"
+
+// Actions of rooms stale
+const (
+	staleReport = "report"
+	staleNotify = "notify"
+	staleLeave  = "leave"
+	staleDelete = "delete"
+)
+
+// staleWarningText is the default warning, formatted with the last activity
+// date, the action and the date it is taken on
+const staleWarningText = "This space has had no activity since %s. It will be %s after %s unless someone replies here."
+
+// staleRoomState is a stale room that was warned, persisted between runs
+type staleRoomState struct {
+	Title     string    `json:"title"`
+	WarnedAt  time.Time `json:"warnedAt"`
+	WarningID string    `json:"warningId"`
+}
+
+// StaleRoomsCMD function
+func (app *Application) StaleRoomsCMD() *cli.Command {
+	return &cli.Command{
+		Name:        "stale",
+		Aliases:     []string{"st"},
+		Description: "Find group rooms without recent activity and report, warn, leave or delete them. Rooms are warned first and only left or deleted when nobody replied within the grace period. Progress is kept in the state directory so repeated runs continue the workflow",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:     "inactive",
+				Aliases:  []string{"i"},
+				Value:    "180d",
+				Usage:    "Rooms without activity for this long are stale, eg. 180d, 8w or 36h. Default is 180d",
+				Required: false,
+			},
+			&cli.StringFlag{
+				Name:     "action",
+				Aliases:  []string{"a"},
+				Value:    staleReport,
+				Usage:    "Action to apply to stale rooms: report, notify (post a warning), leave or delete (owned rooms only). Default is report",
+				Required: false,
+			},
+			&cli.StringFlag{
+				Name:     "grace",
+				Aliases:  []string{"g"},
+				Value:    "14d",
+				Usage:    "Time to wait for replies to the warning before leaving or deleting a room. 0d acts without a warning. Default is 14d",
+				Required: false,
+			},
+			&cli.StringFlag{
+				Name:     "message",
+				Aliases:  []string{"m"},
+				Value:    "",
+				Usage:    "Warning text, supports markdown formatting. Defaults to a message naming the action and its date",
+				Required: false,
+			},
+			&cli.StringFlag{
+				Name:     "team",
+				Aliases:  []string{"tm"},
+				Value:    "",
+				Usage:    "Only rooms of the team with this ID or name",
+				Required: false,
+			},
+			&cli.StringFlag{
+				Name:     "confirm",
+				Aliases:  []string{"c"},
+				Value:    "",
+				Usage:    "Continue without confirmation? Allowed values are 'y' or 'n' ",
+				Required: false,
+			},
+			&cli.StringFlag{
+				Name:     "output",
+				Aliases:  []string{"o"},
+				Value:    outputTable,
+				Usage:    "Output format, one of table, csv or json. Default is table",
+				Required: false,
+			},
+		},
+		Action: func(c *cli.Context) error {
+			format := c.String("output")
+			if err := validateOutputFormat(format); err != nil {
+				return err
+			}
+			action := c.String("action")
+			if action != staleReport && action != staleNotify && action != staleLeave && action != staleDelete {
+				return errors.New("Allowed values for action flag are report, notify, leave and delete")
+			}
+			inactive, err := parseAge(c.String("inactive"))
+			if err != nil {
+				return err
+			}
+			grace, err := parseAge(c.String("grace"))
+			if err != nil {
+				return err
+			}
+			teamID := ""
+			if team := c.String("team"); team != "" {
+				resolved, err := app.resolveTeam(team)
+				if err != nil {
+					return err
+				}
+				teamID = resolved.ID
+			}
+
+			workflow := &staleWorkflow{
+				Application: app,
+				Action:      action,
+				Inactive:    inactive,
+				Grace:       grace,
+				Message:     c.String("message"),
+				Confirm:     c.String("confirm"),
+				Now:         time.Now(),
+			}
+			results, err := workflow.Run(teamID)
+			if results != nil {
+				if werr := writeOutput(os.Stdout, format, roomResultHeader, roomResultRows(results), results); werr != nil {
+					return werr
+				}
+			}
+			return err
+		},
+	}
+}
+
+// staleWorkflow runs one pass of the stale room workflow
+type staleWorkflow struct {
+	*Application
+	Action   string
+	Inactive time.Duration
+	Grace    time.Duration
+	Message  string
+	Confirm  string
+	Now      time.Time
+}
+
+// Run checks warned rooms for replies, warns newly stale rooms and leaves or
+// deletes rooms whose grace period passed without replies
+func (w *staleWorkflow) Run(teamID string) ([]roomResult, error) {
+	statePath, err := w.statePath(staleStateFile)
+	if err != nil {
+		return nil, err
+	}
+	state := make(map[string]*staleRoomState)
+	if err := loadStateFile(statePath, &state); err != nil {
+		return nil, err
+	}
+
+	allRooms, err := w.ListAllRooms("group", teamID)
+	if err != nil {
+		return nil, err
+	}
+	roomsByID := make(map[string]*rooms.Room)
+	for i := range allRooms {
+		roomsByID[allRooms[i].ID] = &allRooms[i]
+	}
+
+	results := make([]roomResult, 0)
+	var due []*rooms.Room
+
+	// Rooms warned on earlier runs. Our own warning counts as activity, so
+	// they are followed up from the state rather than by last activity.
+	warnedIDs := make([]string, 0, len(state))
+	for roomID := range state {
+		warnedIDs = append(warnedIDs, roomID)
+	}
+	sort.Strings(warnedIDs)
+	for _, roomID := range warnedIDs {
+		warned := state[roomID]
+		room, ok := roomsByID[roomID]
+		if !ok {
+			if teamID == "" {
+				delete(state, roomID)
+				results = append(results, roomResult{RoomID: roomID, Title: warned.Title, Status: "gone", Detail: "no longer a member"})
+			}
+			continue
+		}
+		if w.Action == staleReport {
+			results = append(results, roomResult{RoomID: roomID, Title: room.Title, LastActivity: room.LastActivity, Status: "warned", Detail: "warned " + warned.WarnedAt.Format("2006-01-02")})
+			continue
+		}
+		replied, err := w.hasReplySince(roomID, warned.WarnedAt)
+		if err != nil {
+			results = append(results, roomResult{RoomID: roomID, Title: room.Title, Status: "failed", Detail: err.Error()})
+			continue
+		}
+		if replied {
+			delete(state, roomID)
+			results = append(results, roomResult{RoomID: roomID, Title: room.Title, LastActivity: room.LastActivity, Status: "reprieved", Detail: "someone replied to the warning"})
+			continue
+		}
+		actOn := warned.WarnedAt.Add(w.Grace)
+		if w.Action == staleNotify || w.Now.Before(actOn) {
+			results = append(results, roomResult{RoomID: roomID, Title: room.Title, LastActivity: room.LastActivity, Status: "waiting", Detail: "no replies, grace period ends " + actOn.Format("2006-01-02")})
+			continue
+		}
+		due = append(due, room)
+	}
+
+	cutoff := w.Now.Add(-w.Inactive)
+	for i := range allRooms {
+		room := &allRooms[i]
+		if _, warned := state[room.ID]; warned || !isStale(room, cutoff) {
+			continue
+		}
+		result := roomResult{RoomID: room.ID, Title: room.Title, LastActivity: room.LastActivity}
+		switch {
+		case w.Action == staleReport:
+			result.Status = "stale"
+		case w.Action == staleDelete && !w.isOwner(room):
+			result.Status = "skipped"
+			result.Detail = "only owned rooms are deleted"
+		case w.Action != staleNotify && w.Grace == 0:
+			due = append(due, room)
+			continue
+		default:
+			warning, err := w.warn(room)
+			if err != nil {
+				result.Status = "failed"
+				result.Detail = err.Error()
+				break
+			}
+			state[room.ID] = &staleRoomState{Title: room.Title, WarnedAt: w.Now, WarningID: warning.ID}
+			result.Status = "warned"
+			if w.Action != staleNotify {
+				result.Detail = fmt.Sprintf("%s after %s", w.pastTense(), w.Now.Add(w.Grace).Format("2006-01-02"))
+			}
+		}
+		results = append(results, result)
+	}
+
+	if len(due) > 0 && w.confirmDue(due) {
+		for _, room := range due {
+			result := roomResult{RoomID: room.ID, Title: room.Title, LastActivity: room.LastActivity}
+			if err := w.act(room); err != nil {
+				result.Status = "failed"
+				result.Detail = err.Error()
+			} else {
+				result.Status = w.pastTense()
+				delete(state, room.ID)
+			}
+			results = append(results, result)
+		}
+	}
+
+	if err := saveStateFile(statePath, state); err != nil {
+		return results, err
+	}
+	return results, nil
+}
+
+// isStale reports whether room had no activity since cutoff. Rooms without
+// a last activity fall back to their creation date.
+func isStale(room *rooms.Room, cutoff time.Time) bool {
+	last := room.LastActivity
+	if last == nil {
+		last = room.Created
+	}
+	return last != nil && last.Before(cutoff)
+}
+
+func (w *staleWorkflow) pastTense() string {
+	if w.Action == staleDelete {
+		return "deleted"
+	}
+	return "left"
+}
+
+func (w *staleWorkflow) warn(room *rooms.Room) (*messages.Message, error) {
+	text := w.Message
+	if text == "" {
+		lastActivity := "its creation"
+		if room.LastActivity != nil {
+			lastActivity = room.LastActivity.Format("2006-01-02")
+		}
+		action := w.pastTense()
+		if w.Action == staleNotify {
+			action = "reviewed for cleanup"
+		}
+		text = fmt.Sprintf(staleWarningText, lastActivity, action, w.Now.Add(w.Grace).Format("2006-01-02"))
+	}
+	return w.Client.Messages().Create(&messages.Message{RoomID: room.ID, Markdown: text})
+}
+
+// hasReplySince reports whether anyone but the authenticated user posted in
+// the room after since
+func (w *staleWorkflow) hasReplySince(roomID string, since time.Time) (bool, error) {
+	replied := false
+	err := w.eachMessage(roomID, false, 0, func(message messages.Message) error {
+		if message.Created == nil {
+			return nil
+		}
+		if !message.Created.After(since) {
+			// Messages are listed newest first
+			return errStopPaging
+		}
+		if w.Me != nil && message.PersonID == w.Me.ID {
+			return nil
+		}
+		replied = true
+		return errStopPaging
+	})
+	return replied, err
+}
+
+func (w *staleWorkflow) confirmDue(due []*rooms.Room) bool {
+	fmt.Fprintf(os.Stderr, "Rooms to be %s:\n", w.pastTense())
+	for _, room := range due {
+		fmt.Fprintf(os.Stderr, "  %s\n", room.Title)
+	}
+	return confirmPrompt(w.Confirm, fmt.Sprintf("Continue with %d room(s)?", len(due)))
+}
+
+func (w *staleWorkflow) act(room *rooms.Room) error {
+	if w.Action == staleDelete {
+		if !w.isOwner(room) {
+			return errors.New("only owned rooms are deleted")
+		}
+		if err := w.Client.Rooms().Delete(room.ID); err != nil {
+			return err
+		}
+		log.Infof("Deleted room %s", room.Title)
+		return nil
+	}
+	if err := w.leaveRoom(room, false); err != nil {
+		return err
+	}
+	log.Infof("Left room %s", room.Title)
+	return nil
+}
@@ -0,0 +1,191 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	webex "github.com/WebexCommunity/webex-go-sdk/v2"
+	"github.com/WebexCommunity/webex-go-sdk/v2/memberships"
+	"github.com/WebexCommunity/webex-go-sdk/v2/messages"
+	"github.com/WebexCommunity/webex-go-sdk/v2/people"
+	"github.com/WebexCommunity/webex-go-sdk/v2/rooms"
+	"github.com/WebexCommunity/webex-go-sdk/v2/webexsdk"
+)
+
+func TestIsStale(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	cutoff := now.AddDate(0, 0, -180)
+	old := now.AddDate(0, 0, -200)
+	recent := now.AddDate(0, 0, -10)
+
+	if !isStale(&rooms.Room{LastActivity: &old}, cutoff) {
+		t.Error("Expected a room inactive for 200 days to be stale")
+	}
+	if isStale(&rooms.Room{LastActivity: &recent}, cutoff) {
+		t.Error("Expected a room active 10 days ago not to be stale")
+	}
+	if !isStale(&rooms.Room{Created: &old}, cutoff) {
+		t.Error("Expected a room without activity to fall back to its creation date")
+	}
+	if isStale(&rooms.Room{}, cutoff) {
+		t.Error("Expected a room without dates not to be stale")
+	}
+}
+
+// fakeStaleAPI serves the rooms, messages and memberships of a stale room
+// workflow and records the changes made
+type fakeStaleAPI struct {
+	rooms    []rooms.Room
+	messages map[string][]messages.Message
+	warned   []string
+	left     []string
+}
+
+func (api *fakeStaleAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/rooms":
+		json.NewEncoder(w).Encode(map[string]interface{}{"items": api.rooms})
+	case r.Method == http.MethodPost && r.URL.Path == "/messages":
+		var message messages.Message
+		json.NewDecoder(r.Body).Decode(&message)
+		api.warned = append(api.warned, message.RoomID)
+		message.ID = "warning-" + message.RoomID
+		json.NewEncoder(w).Encode(message)
+	case r.Method == http.MethodGet && r.URL.Path == "/messages":
+		json.NewEncoder(w).Encode(map[string]interface{}{"items": api.messages[r.URL.Query().Get("roomId")]})
+	case r.Method == http.MethodGet && r.URL.Path == "/memberships":
+		roomID := r.URL.Query().Get("roomId")
+		json.NewEncoder(w).Encode(map[string]interface{}{"items": []memberships.Membership{{ID: "membership-" + roomID, RoomID: roomID}}})
+	case r.Method == http.MethodDelete && strings.HasPrefix(r.URL.Path, "/memberships/"):
+		api.left = append(api.left, strings.TrimPrefix(r.URL.Path, "/memberships/membership-"))
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+// reply posts a message to a room, newest first as the API lists them
+func (api *fakeStaleAPI) reply(roomID string, personID string, at time.Time) {
+	api.messages[roomID] = append([]messages.Message{{ID: "reply", RoomID: roomID, PersonID: personID, Created: &at}}, api.messages[roomID]...)
+	for i := range api.rooms {
+		if api.rooms[i].ID == roomID {
+			api.rooms[i].LastActivity = &at
+		}
+	}
+}
+
+func staleResultStatuses(results []roomResult) map[string]string {
+	statuses := make(map[string]string)
+	for _, result := range results {
+		statuses[result.RoomID] = result.Status
+	}
+	return statuses
+}
+
+func TestStaleWorkflowRun(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	old := now.AddDate(0, 0, -200)
+	recent := now.AddDate(0, 0, -10)
+	api := &fakeStaleAPI{
+		rooms: []rooms.Room{
+			{ID: "quiet", Title: "Quiet", Type: "group", LastActivity: &old},
+			{ID: "revived", Title: "Revived", Type: "group", LastActivity: &old},
+			{ID: "busy", Title: "Busy", Type: "group", LastActivity: &recent},
+		},
+		messages: make(map[string][]messages.Message),
+	}
+	server := httptest.NewServer(api)
+	defer server.Close()
+	client, err := webex.NewClient("test-token", &webexsdk.Config{BaseURL: server.URL, MaxRetries: 0})
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	app := &Application{Client: client, StateDir: t.TempDir(), Me: &people.Person{ID: "me"}, Email: "me@example.com"}
+	run := func(at time.Time) map[string]string {
+		workflow := &staleWorkflow{Application: app, Action: staleLeave, Inactive: 180 * 24 * time.Hour, Grace: 14 * 24 * time.Hour, Confirm: "y", Now: at}
+		results, err := workflow.Run("")
+		if err != nil {
+			t.Fatalf("Run() error = %v", err)
+		}
+		return staleResultStatuses(results)
+	}
+	loadState := func() map[string]*staleRoomState {
+		state := make(map[string]*staleRoomState)
+		if err := loadStateFile(filepath.Join(app.StateDir, staleStateFile), &state); err != nil {
+			t.Fatalf("loadStateFile() error = %v", err)
+		}
+		return state
+	}
+
+	// The first run warns the stale rooms and remembers when
+	statuses := run(now)
+	sort.Strings(api.warned)
+	if statuses["quiet"] != "warned" || statuses["revived"] != "warned" || statuses["busy"] != "" || strings.Join(api.warned, ",") != "quiet,revived" {
+		t.Fatalf("Expected both stale rooms to be warned, got %v, warned %v", statuses, api.warned)
+	}
+	state := loadState()
+	if len(state) != 2 || state["quiet"].WarningID != "warning-quiet" || !state["quiet"].WarnedAt.Equal(now) {
+		t.Fatalf("Unexpected state after warning %+v", state)
+	}
+
+	// Our own messages are no reply, someone else's is
+	api.reply("quiet", "me", now.Add(time.Hour))
+	api.reply("revived", "someone", now.AddDate(0, 0, 1))
+
+	// Within the grace period nothing is left
+	statuses = run(now.AddDate(0, 0, 7))
+	if statuses["quiet"] != "waiting" || statuses["revived"] != "reprieved" || len(api.left) != 0 {
+		t.Fatalf("Expected a waiting and a reprieved room, got %v, left %v", statuses, api.left)
+	}
+	state = loadState()
+	if _, ok := state["revived"]; ok || len(state) != 1 {
+		t.Fatalf("Expected the reprieved room to leave the state, got %+v", state)
+	}
+
+	// After the grace period the room without replies is left
+	statuses = run(now.AddDate(0, 0, 15))
+	if statuses["quiet"] != "left" || strings.Join(api.left, ",") != "quiet" {
+		t.Fatalf("Expected the quiet room to be left, got %v, left %v", statuses, api.left)
+	}
+	if statuses["revived"] != "" || len(api.warned) != 2 {
+		t.Errorf("Expected the reprieved room not to be warned again, got %v, warned %v", statuses, api.warned)
+	}
+	if state = loadState(); len(state) != 0 {
+		t.Errorf("Expected an empty state once the room is left, got %+v", state)
+	}
+}
+
+func TestStaleWorkflowHasReplySince(t *testing.T) {
+	warnedAt := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	api := &fakeStaleAPI{messages: make(map[string][]messages.Message)}
+	server := httptest.NewServer(api)
+	defer server.Close()
+	client, err := webex.NewClient("test-token", &webexsdk.Config{BaseURL: server.URL, MaxRetries: 0})
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	workflow := &staleWorkflow{Application: &Application{Client: client, Me: &people.Person{ID: "me"}}}
+
+	api.reply("r1", "someone", warnedAt.Add(-time.Hour))
+	api.reply("r1", "me", warnedAt)
+	api.reply("r1", "me", warnedAt.Add(time.Hour))
+	replied, err := workflow.hasReplySince("r1", warnedAt)
+	if err != nil {
+		t.Fatalf("hasReplySince() error = %v", err)
+	}
+	if replied {
+		t.Error("Expected older messages and our own not to count as replies")
+	}
+
+	api.reply("r1", "someone", warnedAt.Add(2*time.Hour))
+	if replied, _ := workflow.hasReplySince("r1", warnedAt); !replied {
+		t.Error("Expected a later message from someone else to count as a reply")
+	}
+}
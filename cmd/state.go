@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// stateLockTimeout is how long lockStateFile waits for another process, and
+// stateLockStale how old a lock file is before it is taken as left behind
+// by a crashed process
+const (
+	stateLockTimeout = 10 * time.Second
+	stateLockStale   = time.Minute
+)
+
+// statePath returns the path of a named file inside the state directory,
+// creating the directory if it does not exist yet
+func (app *Application) statePath(name string) (string, error) {
+	if app.StateDir == "" {
+		return "", errors.New("State directory is not configured")
+	}
+	if err := os.MkdirAll(app.StateDir, 0700); err != nil {
+		return "", err
+	}
+	return filepath.Join(app.StateDir, name), nil
+}
+
+// loadStateFile decodes the JSON file at filePath into v. A missing file
+// leaves v untouched so callers can pre-populate defaults.
+func loadStateFile(filePath string, v interface{}) error {
+	data, err := os.ReadFile(filePath)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if len(data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(data, v)
+}
+
+// saveStateFile writes v as JSON to filePath. The file is written to a
+// temporary sibling first and renamed, so a crash never leaves a torn file.
+func saveStateFile(filePath string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(filePath), 0700); err != nil {
+		return err
+	}
+	tmpFile, err := os.CreateTemp(filepath.Dir(filePath), filepath.Base(filePath)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	tmpName := tmpFile.Name()
+	if _, err := tmpFile.Write(data); err != nil {
+		tmpFile.Close()
+		os.Remove(tmpName)
+		return err
+	}
+	if err := tmpFile.Close(); err != nil {
+		os.Remove(tmpName)
+		return err
+	}
+	return os.Rename(tmpName, filePath)
+}
+
+// newStateID returns a short random ID for records kept in the state directory
+func newStateID() (string, error) {
+	b := make([]byte, 4)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// lockStateFile takes a lock on filePath shared by every process using the
+// state directory, by creating a lock file next to it. It returns a function
+// releasing the lock.
+func lockStateFile(filePath string) (func(), error) {
+	if err := os.MkdirAll(filepath.Dir(filePath), 0700); err != nil {
+		return nil, err
+	}
+	lockPath := filePath + ".lock"
+	deadline := time.Now().Add(stateLockTimeout)
+	for {
+		lockFile, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+		if err == nil {
+			lockFile.Close()
+			return func() { os.Remove(lockPath) }, nil
+		}
+		if !os.IsExist(err) {
+			return nil, err
+		}
+		if info, statErr := os.Stat(lockPath); statErr == nil && time.Since(info.ModTime()) > stateLockStale {
+			os.Remove(lockPath)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("Timed out waiting for the lock %s, remove it if no other process is running", lockPath)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
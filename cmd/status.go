@@ -0,0 +1,182 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/WebexCommunity/webex-go-sdk/v2/messages"
+	log "github.com/sirupsen/logrus"
+	"github.com/tejzpr/webex-teams-cli/cmd/webexid"
+	"github.com/urfave/cli/v2"
+)
+
+const statusStateFile = "status.json"
+
+// statusEntry remembers the message backing a status key and the target it
+// was posted to
+type statusEntry struct {
+	MessageID   string    `json:"messageId"`
+	RoomID      string    `json:"roomId"`
+	PersonID    string    `json:"personId,omitempty"`
+	PersonEmail string    `json:"personEmail,omitempty"`
+	Created     time.Time `json:"created"`
+	Updated     time.Time `json:"updated"`
+}
+
+// targets reports whether the entry's message was posted to the room or
+// person params address
+func (entry *statusEntry) targets(params *SendMessageParams) bool {
+	switch {
+	case params.RoomID != "":
+		return sameWebexID(entry.RoomID, params.RoomID)
+	case params.PersonID != "":
+		return sameWebexID(entry.PersonID, params.PersonID)
+	default:
+		return entry.PersonEmail != "" && normalizeEmail(entry.PersonEmail) == normalizeEmail(params.PersonEmail)
+	}
+}
+
+// sameWebexID compares two IDs by the UUID they encode
+func sameWebexID(a, b string) bool {
+	if a == b {
+		return true
+	}
+	idA, errA := webexid.Decode(a)
+	idB, errB := webexid.Decode(b)
+	return errA == nil && errB == nil && strings.EqualFold(idA.UUID, idB.UUID)
+}
+
+// StatusMessageCMD function
+func (app *Application) StatusMessageCMD() *cli.Command {
+	return &cli.Command{
+		Name:        "status",
+		Aliases:     []string{"st"},
+		Description: "Post a status message once and edit it in place on subsequent calls with the same key",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:     "key",
+				Aliases:  []string{"k"},
+				Value:    "",
+				Usage:    "Name identifying the status message eg. deploy-42",
+				Required: true,
+			},
+			&cli.StringFlag{
+				Name:     "text",
+				Aliases:  []string{"t"},
+				Value:    "",
+				Usage:    "Status text, supports markdown formatting",
+				Required: true,
+			},
+			&cli.BoolFlag{
+				Name:     "final",
+				Value:    false,
+				Usage:    "Ends the key's lifecycle. The next call with the same key posts a new message",
+				Required: false,
+			},
+			&cli.BoolFlag{
+				Name:     "thread-log",
+				Aliases:  []string{"tl"},
+				Value:    false,
+				Usage:    "Also append each update as a reply in the status message's thread",
+				Required: false,
+			},
+		},
+		Action: func(c *cli.Context) error {
+			roomID := c.String("roomID")
+			if roomID != "" {
+				parsedRoomID, err := app.parseRoomID(roomID)
+				if err != nil {
+					return err
+				}
+				roomID = parsedRoomID
+			}
+			params := &SendMessageParams{
+				RoomID:      roomID,
+				PersonID:    c.String("toPersonID"),
+				PersonEmail: c.String("toPersonEmail"),
+				Text:        c.String("text"),
+			}
+			statusMessage, err := app.UpdateStatusMessage(c.String("key"), params, c.Bool("final"), c.Bool("thread-log"))
+			if err != nil {
+				return err
+			}
+			log.Infof("Status message: %s", statusMessage.ID)
+			return nil
+		},
+	}
+}
+
+// UpdateStatusMessage creates the status message for key on first use and
+// edits it in place afterwards. Final removes the key once the message is
+// updated. A key in use by a message posted to another room or person is an
+// error.
+func (app *Application) UpdateStatusMessage(key string, params *SendMessageParams, final bool, threadLog bool) (*messages.Message, error) {
+	if key == "" {
+		return nil, errors.New("Status key is required")
+	}
+	statePath, err := app.statePath(statusStateFile)
+	if err != nil {
+		return nil, err
+	}
+	// The lock is held until the message is posted and saved, so concurrent
+	// steps neither drop each other's keys nor post the same key twice
+	unlock, err := lockStateFile(statePath)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	entries := make(map[string]*statusEntry)
+	if err := loadStateFile(statePath, &entries); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	var statusMessage *messages.Message
+	entry, ok := entries[key]
+	if !ok {
+		statusMessage, err = app.SendMessage2Room(params)
+		if err != nil {
+			return nil, err
+		}
+		entry = &statusEntry{
+			MessageID:   statusMessage.ID,
+			RoomID:      statusMessage.RoomID,
+			PersonID:    params.PersonID,
+			PersonEmail: params.PersonEmail,
+			Created:     now,
+		}
+		entries[key] = entry
+	} else {
+		if !entry.targets(params) {
+			return nil, fmt.Errorf("Status key %s belongs to a message in another room, use a different key or end it with --final first", key)
+		}
+		statusMessage, err = app.Client.Messages().Update(entry.MessageID, &messages.Message{
+			RoomID:   entry.RoomID,
+			Markdown: params.Text,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if threadLog {
+			_, err := app.Client.Messages().Create(&messages.Message{
+				RoomID:   entry.RoomID,
+				ParentID: entry.MessageID,
+				Markdown: params.Text,
+			})
+			if err != nil {
+				log.Errorf("Failed to append status to thread: %s", err.Error())
+			}
+		}
+	}
+	entry.Updated = now
+
+	if final {
+		delete(entries, key)
+	}
+	if err := saveStateFile(statePath, entries); err != nil {
+		return nil, err
+	}
+	return statusMessage, nil
+}
@@ -0,0 +1,155 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	webex "github.com/WebexCommunity/webex-go-sdk/v2"
+	"github.com/WebexCommunity/webex-go-sdk/v2/messages"
+	"github.com/WebexCommunity/webex-go-sdk/v2/rooms"
+	"github.com/WebexCommunity/webex-go-sdk/v2/webexsdk"
+	"github.com/tejzpr/webex-teams-cli/cmd/webexid"
+)
+
+// newTestStatusApp returns an application whose client talks to a fake
+// messages API recording every request as "METHOD path parentId"
+func newTestStatusApp(t *testing.T, requests *[]string) *Application {
+	var created int
+	var mu sync.Mutex
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/rooms/") {
+			json.NewEncoder(w).Encode(rooms.Room{ID: strings.TrimPrefix(r.URL.Path, "/rooms/")})
+			return
+		}
+		var message messages.Message
+		json.NewDecoder(r.Body).Decode(&message)
+		*requests = append(*requests, strings.TrimSpace(fmt.Sprintf("%s %s %s", r.Method, r.URL.Path, message.ParentID)))
+		if r.Method == http.MethodPost {
+			created++
+			message.ID = fmt.Sprintf("msg%d", created)
+		} else {
+			message.ID = strings.TrimPrefix(r.URL.Path, "/messages/")
+		}
+		json.NewEncoder(w).Encode(message)
+	}))
+	t.Cleanup(server.Close)
+
+	client, err := webex.NewClient("test-token", &webexsdk.Config{BaseURL: server.URL, MaxRetries: 0})
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	return &Application{Client: client, StateDir: t.TempDir()}
+}
+
+func TestUpdateStatusMessage(t *testing.T) {
+	var requests []string
+	app := newTestStatusApp(t, &requests)
+	roomA, _ := webexid.Encode(webexid.Room, "0b5c6d2e-0000-4000-8000-00000000000a")
+	roomB, _ := webexid.Encode(webexid.Room, "0b5c6d2e-0000-4000-8000-00000000000b")
+
+	steps := []struct {
+		name      string
+		roomID    string
+		final     bool
+		threadLog bool
+		messageID string
+		requests  []string
+	}{
+		{"create", roomA, false, false, "msg1", []string{"POST /messages"}},
+		{"update", roomA, false, false, "msg1", []string{"PUT /messages/msg1"}},
+		{"thread log", roomA, false, true, "msg1", []string{"PUT /messages/msg1", "POST /messages msg1"}},
+		{"final", roomA, true, false, "msg1", []string{"PUT /messages/msg1"}},
+		{"create after final", roomA, false, false, "msg3", []string{"POST /messages"}},
+	}
+	for _, step := range steps {
+		requests = nil
+		params := &SendMessageParams{RoomID: step.roomID, Text: step.name}
+		statusMessage, err := app.UpdateStatusMessage("deploy-42", params, step.final, step.threadLog)
+		if err != nil {
+			t.Fatalf("%s: UpdateStatusMessage() error = %v", step.name, err)
+		}
+		if statusMessage.ID != step.messageID {
+			t.Errorf("%s: expected message %s, got %s", step.name, step.messageID, statusMessage.ID)
+		}
+		if strings.Join(requests, "|") != strings.Join(step.requests, "|") {
+			t.Errorf("%s: expected requests %v, got %v", step.name, step.requests, requests)
+		}
+	}
+
+	requests = nil
+	if _, err := app.UpdateStatusMessage("deploy-42", &SendMessageParams{RoomID: roomB, Text: "elsewhere"}, false, false); err == nil {
+		t.Error("Expected an error using a key of another room")
+	}
+	if len(requests) != 0 {
+		t.Errorf("Expected no requests for a key of another room, got %v", requests)
+	}
+
+	if _, err := app.UpdateStatusMessage("", &SendMessageParams{RoomID: roomA, Text: "x"}, false, false); err == nil {
+		t.Error("Expected an error without a key")
+	}
+}
+
+func TestUpdateStatusMessageConcurrent(t *testing.T) {
+	var requests []string
+	app := newTestStatusApp(t, &requests)
+	roomID, _ := webexid.Encode(webexid.Room, "0b5c6d2e-0000-4000-8000-00000000000a")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			// Half the steps share a key, the others each have their own
+			key := "shared"
+			if i%2 == 1 {
+				key = fmt.Sprintf("step-%d", i)
+			}
+			params := &SendMessageParams{RoomID: roomID, Text: fmt.Sprintf("step %d", i)}
+			if _, err := app.UpdateStatusMessage(key, params, false, false); err != nil {
+				t.Errorf("UpdateStatusMessage() error = %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	posts := 0
+	for _, request := range requests {
+		if request == "POST /messages" {
+			posts++
+		}
+	}
+	if posts != 4 {
+		t.Errorf("Expected one message per key, got requests %v", requests)
+	}
+	entries := make(map[string]*statusEntry)
+	if err := loadStateFile(filepath.Join(app.StateDir, statusStateFile), &entries); err != nil {
+		t.Fatalf("loadStateFile() error = %v", err)
+	}
+	if len(entries) != 4 {
+		t.Errorf("Expected 4 status keys, got %d", len(entries))
+	}
+}
+
+func TestStatusEntryTargets(t *testing.T) {
+	roomID, _ := webexid.Encode(webexid.Room, "0b5c6d2e-0000-4000-8000-00000000000a")
+	entry := &statusEntry{RoomID: roomID, PersonEmail: "A@example.com"}
+	otherCluster := webexid.ID{Cluster: "eu", Type: webexid.Room, UUID: "0b5c6d2e-0000-4000-8000-00000000000a"}.String()
+	if !entry.targets(&SendMessageParams{RoomID: otherCluster}) {
+		t.Error("Expected the same room encoded for another cluster to match")
+	}
+	if !entry.targets(&SendMessageParams{PersonEmail: "a@example.com"}) {
+		t.Error("Expected the same person to match")
+	}
+	if entry.targets(&SendMessageParams{PersonEmail: "b@example.com"}) {
+		t.Error("Expected another person not to match")
+	}
+}
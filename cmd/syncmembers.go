@@ -0,0 +1,151 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/urfave/cli/v2"
+)
+
+// memberSyncSummary counts the changes made to one room by syncmembers
+type memberSyncSummary struct {
+	Room    string
+	Added   int
+	Updated int
+	Removed int
+	Extras  int
+	Failed  int
+}
+
+// SyncMembersCMD function
+func (app *Application) SyncMembersCMD() *cli.Command {
+	return &cli.Command{
+		Name:        "syncmembers",
+		Aliases:     []string{"sm"},
+		Description: "Make the members of room(s) match a CSV: add missing people, update moderator flags and optionally remove extras",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:     "memberscsv",
+				Aliases:  []string{"csv"},
+				Value:    "",
+				Usage:    "Path to CSV with list of email addresses formatted as : email, moderator",
+				Required: true,
+			},
+			&cli.BoolFlag{
+				Name:     "remove-extras",
+				Aliases:  []string{"rx"},
+				Value:    false,
+				Usage:    "Remove members that are not in the CSV. You are never removed",
+				Required: false,
+			},
+			&cli.StringFlag{
+				Name:     "confirm",
+				Aliases:  []string{"c"},
+				Value:    "",
+				Usage:    "Continue without confirmation? Allowed values are 'y' or 'n' ",
+				Required: false,
+			},
+			&cli.StringFlag{
+				Name:     "access",
+				Aliases:  []string{"a"},
+				Value:    "om",
+				Usage:    "Members will be synced in rooms for which you have specified permissions of either 'a' (include all), 'o' (owner), 'm' (moderator) or 'om' (owner and moderator). Default is owner and moderator.",
+				Required: false,
+			},
+			&cli.StringFlag{
+				Name:     "roomsidscsv",
+				Aliases:  []string{"rcsv"},
+				Value:    "",
+				Usage:    "Path to a CSV containing a list of RoomID's whose members will be synced.",
+				Required: false,
+			},
+			&cli.StringFlag{
+				Name:     "team",
+				Aliases:  []string{"tm"},
+				Value:    "",
+				Usage:    "ID or name of a team whose rooms members will be synced in",
+				Required: false,
+			},
+		},
+		Action: func(c *cli.Context) error {
+			roomIDs, err := app.targetRoomIDs(c)
+			if err != nil {
+				return err
+			}
+
+			access := c.String("access")
+			if access != "a" && access != "o" && access != "m" && access != "om" {
+				return errors.New("Allowed valued for access flag are a, o, m and om")
+			}
+
+			if len(roomIDs) == 0 && !confirmPrompt(c.String("confirm"), "Continue to sync members of all rooms that you have access to?") {
+				return nil
+			}
+
+			members, err := readMembersCSV(c.String("memberscsv"))
+			if err != nil {
+				return err
+			}
+			summaries, err := app.SyncMembers(roomIDs, access, members, c.Bool("remove-extras"))
+			writeSyncSummary(os.Stdout, summaries)
+			return err
+		},
+	}
+}
+
+// SyncMembers reconciles the members of each eligible room with members
+func (app *Application) SyncMembers(roomIDs []string, access string, members []userCSV, removeExtras bool) ([]memberSyncSummary, error) {
+	desired := make(map[string]bool)
+	for _, member := range members {
+		desired[strings.ToLower(string(member.Email))] = member.IsModerator
+	}
+
+	targets, err := app.eligibleRooms(roomIDs, access, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	var summaries []memberSyncSummary
+	for _, target := range targets {
+		summary := memberSyncSummary{Room: target.Room.Title}
+		current, err := app.ListAllMemberships(target.Room.ID)
+		if err != nil {
+			return summaries, err
+		}
+		for _, change := range diffMembers(desired, membershipsByEmail(current), app.Email, false) {
+			if change.Action == spaceRemoveMember && !removeExtras {
+				summary.Extras++
+				continue
+			}
+			if err := app.applyMemberChange(target.Room.ID, target.Room.Title, change, removeExtras); err != nil {
+				log.Error(err.Error())
+				summary.Failed++
+				continue
+			}
+			switch change.Action {
+			case spaceAddMember:
+				summary.Added++
+			case spaceUpdateMember:
+				summary.Updated++
+			case spaceRemoveMember:
+				summary.Removed++
+			}
+		}
+		summaries = append(summaries, summary)
+	}
+	return summaries, nil
+}
+
+func writeSyncSummary(w io.Writer, summaries []memberSyncSummary) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ROOM\tADDED\tUPDATED\tREMOVED\tEXTRAS\tFAILED")
+	for _, summary := range summaries {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%d\t%d\n", summary.Room, summary.Added, summary.Updated, summary.Removed, summary.Extras, summary.Failed)
+	}
+	tw.Flush()
+}
@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	webex "github.com/WebexCommunity/webex-go-sdk/v2"
+	"github.com/WebexCommunity/webex-go-sdk/v2/memberships"
+	"github.com/WebexCommunity/webex-go-sdk/v2/people"
+	"github.com/WebexCommunity/webex-go-sdk/v2/rooms"
+	"github.com/WebexCommunity/webex-go-sdk/v2/webexsdk"
+)
+
+func TestDiffMembersKeepsSelf(t *testing.T) {
+	desired := map[string]bool{"a@example.com": true}
+	current := membershipsByEmail([]memberships.Membership{
+		{ID: "m-me", PersonEmail: "Me@Example.com", IsModerator: true},
+		{ID: "m-a", PersonEmail: "A@example.com"},
+		{ID: "m-b", PersonEmail: "b@example.com"},
+	})
+	changes := diffMembers(desired, current, "me@example.com", false)
+
+	expected := []spaceChange{
+		{Action: spaceUpdateMember, Email: "a@example.com", Moderator: true, MembershipID: "m-a"},
+		{Action: spaceRemoveMember, Email: "b@example.com", MembershipID: "m-b"},
+	}
+	if len(changes) != len(expected) {
+		t.Fatalf("Expected %d changes, got %+v", len(expected), changes)
+	}
+	for i := range expected {
+		if changes[i] != expected[i] {
+			t.Errorf("Expected change %+v, got %+v", expected[i], changes[i])
+		}
+	}
+}
+
+func TestWriteSyncSummary(t *testing.T) {
+	var buf bytes.Buffer
+	writeSyncSummary(&buf, []memberSyncSummary{{Room: "Project X", Added: 2, Updated: 1, Extras: 3}})
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected header and 1 row, got %q", buf.String())
+	}
+	if fields := strings.Fields(lines[0]); len(fields) != 6 || fields[0] != "ROOM" {
+		t.Errorf("Unexpected header %q", lines[0])
+	}
+	if fields := strings.Fields(lines[1]); strings.Join(fields, " ") != "Project X 2 1 0 3 0" {
+		t.Errorf("Unexpected row %q", lines[1])
+	}
+}
+
+func TestSyncMembersCMDStructure(t *testing.T) {
+	app := &Application{}
+	cmd := app.SyncMembersCMD()
+	if cmd.Name != "syncmembers" {
+		t.Errorf("Expected command name 'syncmembers', got %q", cmd.Name)
+	}
+	for _, flagName := range []string{"memberscsv", "remove-extras", "confirm", "access", "roomsidscsv", "team"} {
+		if getFlagByName(cmd.Flags, flagName) == nil {
+			t.Errorf("Expected flag %q not found", flagName)
+		}
+	}
+}
+
+func TestSyncMembersKeepsModerators(t *testing.T) {
+	csvPath := filepath.Join(t.TempDir(), "members.csv")
+	if err := os.WriteFile(csvPath, []byte("email,moderator\nboss@example.com,true\nnew@example.com,false\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	members, err := readMembersCSV(csvPath)
+	if err != nil {
+		t.Fatalf("readMembersCSV() error = %v", err)
+	}
+
+	var updates, creates []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/rooms/r1":
+			json.NewEncoder(w).Encode(rooms.Room{ID: "r1", Title: "Project X", Type: "group", CreatorID: "me"})
+		case r.Method == http.MethodGet && r.URL.Path == "/memberships":
+			items := []memberships.Membership{
+				{ID: "m-me", RoomID: "r1", PersonEmail: "me@example.com", IsModerator: true},
+				{ID: "m-boss", RoomID: "r1", PersonEmail: "boss@example.com", IsModerator: true},
+			}
+			if r.URL.Query().Get("personEmail") != "" {
+				items = items[:1]
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"items": items})
+		case r.Method == http.MethodPut:
+			updates = append(updates, r.URL.Path)
+			json.NewEncoder(w).Encode(memberships.Membership{})
+		case r.Method == http.MethodPost:
+			var membership memberships.Membership
+			json.NewDecoder(r.Body).Decode(&membership)
+			creates = append(creates, membership.PersonEmail)
+			json.NewEncoder(w).Encode(membership)
+		}
+	}))
+	defer server.Close()
+	client, err := webex.NewClient("test-token", &webexsdk.Config{BaseURL: server.URL, MaxRetries: 0})
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	app := &Application{Client: client, Me: &people.Person{ID: "me"}, Email: "me@example.com"}
+
+	summaries, err := app.SyncMembers([]string{"r1"}, "om", members, false)
+	if err != nil {
+		t.Fatalf("SyncMembers() error = %v", err)
+	}
+	if len(updates) != 0 {
+		t.Errorf("Expected the existing moderator to be kept, got updates %v", updates)
+	}
+	if strings.Join(creates, ",") != "new@example.com" {
+		t.Errorf("Expected only new@example.com to be added, got %v", creates)
+	}
+	if len(summaries) != 1 || summaries[0].Added != 1 || summaries[0].Updated != 0 {
+		t.Errorf("Unexpected summaries %+v", summaries)
+	}
+}
@@ -0,0 +1,156 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/WebexCommunity/webex-go-sdk/v2/memberships"
+	"github.com/WebexCommunity/webex-go-sdk/v2/rooms"
+	log "github.com/sirupsen/logrus"
+	"github.com/urfave/cli/v2"
+)
+
+// targetRoomIDs resolves the rooms a bulk room command acts on from its
+// --roomsidscsv, --team and --select flags, or the room command's --roomID.
+// When several of the flags are set only rooms selected by all of them are
+// kept. No IDs means all rooms the user has access to.
+func (app *Application) targetRoomIDs(c *cli.Context) ([]string, error) {
+	var sources []string
+	var selections [][]string
+	if roomsCSV := c.String("roomsidscsv"); roomsCSV != "" {
+		csvfile, err := openCSVFile(roomsCSV)
+		if err != nil {
+			return nil, err
+		}
+		defer csvfile.Close()
+		var roomIDs []string
+		for v := range ParseRoomIDsCSV(csvfile) {
+			parsedRoomID, err := app.parseRoomID(v.Value.RoomID)
+			if err != nil {
+				return nil, err
+			}
+			roomIDs = append(roomIDs, parsedRoomID)
+		}
+		sources, selections = append(sources, "--roomsidscsv"), append(selections, roomIDs)
+	}
+	if team := c.String("team"); team != "" {
+		roomIDs, err := app.teamRoomIDs(team)
+		if err != nil {
+			return nil, err
+		}
+		sources, selections = append(sources, "--team"), append(selections, roomIDs)
+	}
+	if expr := c.String("select"); expr != "" {
+		roomIDs, err := app.selectRoomIDs(expr)
+		if err != nil {
+			return nil, err
+		}
+		sources, selections = append(sources, "--select"), append(selections, roomIDs)
+	}
+
+	switch len(selections) {
+	case 0:
+		if roomID := c.String("roomID"); roomID != "" {
+			parsedRoomID, err := app.parseRoomID(roomID)
+			if err != nil {
+				return nil, err
+			}
+			return []string{parsedRoomID}, nil
+		}
+		return nil, nil
+	case 1:
+		return selections[0], nil
+	}
+	roomIDs := intersectRoomIDs(selections)
+	if len(roomIDs) == 0 {
+		// An empty result would otherwise stand for all rooms
+		return nil, fmt.Errorf("No rooms are selected by all of %s", strings.Join(sources, ", "))
+	}
+	return roomIDs, nil
+}
+
+// intersectRoomIDs returns the IDs of the first selection that are in every
+// other selection, in order
+func intersectRoomIDs(selections [][]string) []string {
+	counts := make(map[string]int)
+	for _, selection := range selections {
+		seen := make(map[string]bool)
+		for _, roomID := range selection {
+			if !seen[roomID] {
+				seen[roomID] = true
+				counts[roomID]++
+			}
+		}
+	}
+	var roomIDs []string
+	for _, roomID := range selections[0] {
+		if counts[roomID] == len(selections) {
+			roomIDs = append(roomIDs, roomID)
+			counts[roomID] = 0
+		}
+	}
+	return roomIDs
+}
+
+// eligibleRoom is a group room together with the user's membership of it
+type eligibleRoom struct {
+	Room       *rooms.Room
+	Membership memberships.Membership
+}
+
+// eligibleRooms returns the group rooms among roomIDs, or among the user's
+// rooms when roomIDs is empty, that pass the access check. Only the first
+// limit memberships of the user are read when limit is greater than 0.
+func (app *Application) eligibleRooms(roomIDs []string, access string, limit int) ([]eligibleRoom, error) {
+	accessApp := &AddPeopleApplication{Application: app, Access: access}
+	var eligible []eligibleRoom
+	if len(roomIDs) == 0 {
+		myMemberships, err := app.ListMemberships("", limit)
+		if err != nil {
+			return nil, err
+		}
+		for _, membership := range myMemberships {
+			if membership.RoomType == "direct" {
+				continue
+			}
+			room, err := app.Client.Rooms().Get(membership.RoomID)
+			if err != nil {
+				return nil, err
+			}
+			if room.Title != "" && room.Type != "direct" && accessApp.checkAccess(app.Me, room, membership) {
+				eligible = append(eligible, eligibleRoom{Room: room, Membership: membership})
+			}
+		}
+		return eligible, nil
+	}
+
+	for _, roomID := range roomIDs {
+		room, err := app.Client.Rooms().Get(roomID)
+		if err != nil {
+			return nil, err
+		}
+		mbrPage, err := app.Client.Memberships().List(&memberships.ListOptions{RoomID: room.ID, PersonEmail: app.Email})
+		if err != nil {
+			return nil, err
+		}
+		if len(mbrPage.Items) == 0 {
+			log.Warnf("Skipping %s, you are not a member of this room", room.Title)
+			continue
+		}
+		if room.Type == "direct" || !accessApp.checkAccess(app.Me, room, mbrPage.Items[0]) {
+			log.Warnf("Skipping %s, you do not have the required access", room.Title)
+			continue
+		}
+		eligible = append(eligible, eligibleRoom{Room: room, Membership: mbrPage.Items[0]})
+	}
+	return eligible, nil
+}
+
+// membershipsByEmail indexes memberships by lower cased email
+func membershipsByEmail(items []memberships.Membership) map[string]memberships.Membership {
+	byEmail := make(map[string]memberships.Membership)
+	for _, membership := range items {
+		byEmail[strings.ToLower(membership.PersonEmail)] = membership
+	}
+	return byEmail
+}
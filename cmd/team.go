@@ -0,0 +1,492 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/WebexCommunity/webex-go-sdk/v2/rooms"
+	"github.com/WebexCommunity/webex-go-sdk/v2/teammemberships"
+	"github.com/WebexCommunity/webex-go-sdk/v2/teams"
+	log "github.com/sirupsen/logrus"
+	"github.com/urfave/cli/v2"
+)
+
+// TeamCMD function
+func (app *Application) TeamCMD() *cli.Command {
+	return &cli.Command{
+		Name:    "team",
+		Aliases: []string{"tm"},
+		Usage:   "Manage Webex teams, their rooms and members. Teams are selected by ID or name",
+		Subcommands: []*cli.Command{
+			app.ListTeamsCMD(),
+			app.CreateTeamCMD(),
+			app.RenameTeamCMD(),
+			app.DeleteTeamCMD(),
+			app.TeamRoomsCMD(),
+			app.TeamMembersCMD(),
+		},
+		Action: func(c *cli.Context) error {
+			return nil
+		},
+	}
+}
+
+// ListTeamsCMD function
+func (app *Application) ListTeamsCMD() *cli.Command {
+	return &cli.Command{
+		Name:        "list",
+		Aliases:     []string{"ls"},
+		Description: "List the teams you are a member of",
+		Flags:       []cli.Flag{limitFlag()},
+		Action: func(c *cli.Context) error {
+			teamList, err := app.ListTeams(c.Int("limit"))
+			if err != nil {
+				return err
+			}
+			m, err := json.Marshal(teamList)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("%s", string(m))
+			return nil
+		},
+	}
+}
+
+// CreateTeamCMD function
+func (app *Application) CreateTeamCMD() *cli.Command {
+	return &cli.Command{
+		Name:        "create",
+		Aliases:     []string{"cr"},
+		Description: "Create a team",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:     "name",
+				Aliases:  []string{"n"},
+				Value:    "",
+				Usage:    "Name of the team",
+				Required: true,
+			},
+			&cli.StringFlag{
+				Name:     "description",
+				Aliases:  []string{"d"},
+				Value:    "",
+				Usage:    "Description of the team",
+				Required: false,
+			},
+		},
+		Action: func(c *cli.Context) error {
+			team, err := app.Client.Teams().Create(&teams.Team{
+				Name:        c.String("name"),
+				Description: c.String("description"),
+			})
+			if err != nil {
+				return err
+			}
+			log.Infof("Created team %s", team.Name)
+			m, err := json.Marshal(team)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("%s", string(m))
+			return nil
+		},
+	}
+}
+
+// RenameTeamCMD function
+func (app *Application) RenameTeamCMD() *cli.Command {
+	return &cli.Command{
+		Name:        "rename",
+		Aliases:     []string{"rn"},
+		Description: "Rename a team",
+		ArgsUsage:   "<team>",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:     "name",
+				Aliases:  []string{"n"},
+				Value:    "",
+				Usage:    "New name of the team",
+				Required: true,
+			},
+		},
+		Action: func(c *cli.Context) error {
+			team, err := app.resolveTeam(c.Args().First())
+			if err != nil {
+				return err
+			}
+			renamed, err := app.Client.Teams().Update(team.ID, &teams.Team{
+				Name:        c.String("name"),
+				Description: team.Description,
+			})
+			if err != nil {
+				return err
+			}
+			log.Infof("Renamed team %s to %s", team.Name, renamed.Name)
+			return nil
+		},
+	}
+}
+
+// DeleteTeamCMD function
+func (app *Application) DeleteTeamCMD() *cli.Command {
+	return &cli.Command{
+		Name:        "delete",
+		Aliases:     []string{"del"},
+		Description: "Delete a team",
+		ArgsUsage:   "<team>",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:     "confirm",
+				Aliases:  []string{"c"},
+				Value:    "",
+				Usage:    "Continue without confirmation? Allowed values are 'y' or 'n' ",
+				Required: false,
+			},
+		},
+		Action: func(c *cli.Context) error {
+			team, err := app.resolveTeam(c.Args().First())
+			if err != nil {
+				return err
+			}
+			if !confirmPrompt(c.String("confirm"), fmt.Sprintf("Delete team %s?", team.Name)) {
+				return nil
+			}
+			if err := app.Client.Teams().Delete(team.ID); err != nil {
+				return err
+			}
+			log.Infof("Deleted team %s", team.Name)
+			return nil
+		},
+	}
+}
+
+// TeamRoomsCMD function
+func (app *Application) TeamRoomsCMD() *cli.Command {
+	return &cli.Command{
+		Name:        "rooms",
+		Aliases:     []string{"r"},
+		Description: "List the rooms of a team",
+		ArgsUsage:   "<team>",
+		Flags:       []cli.Flag{limitFlag()},
+		Action: func(c *cli.Context) error {
+			team, err := app.resolveTeam(c.Args().First())
+			if err != nil {
+				return err
+			}
+			teamRooms, err := app.ListRooms("", team.ID, c.Int("limit"))
+			if err != nil {
+				return err
+			}
+			m, err := json.Marshal(teamRooms)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("%s", string(m))
+			return nil
+		},
+	}
+}
+
+// TeamMembersCMD function
+func (app *Application) TeamMembersCMD() *cli.Command {
+	return &cli.Command{
+		Name:        "members",
+		Aliases:     []string{"m"},
+		Description: "Manage the members of a team",
+		Subcommands: []*cli.Command{
+			app.AddTeamMembersCMD(),
+			app.RemoveTeamMembersCMD(),
+			app.ExportTeamMembersCMD(),
+		},
+		Action: func(c *cli.Context) error {
+			return nil
+		},
+	}
+}
+
+// AddTeamMembersCMD function
+func (app *Application) AddTeamMembersCMD() *cli.Command {
+	return &cli.Command{
+		Name:        "add",
+		Aliases:     []string{"a"},
+		Description: "Add members to a team, or change whether existing members are moderators",
+		ArgsUsage:   "<team>",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:     "email",
+				Aliases:  []string{"e"},
+				Value:    "",
+				Usage:    "Email address of the member to add",
+				Required: false,
+			},
+			&cli.BoolFlag{
+				Name:     "moderator",
+				Aliases:  []string{"mod"},
+				Value:    false,
+				Usage:    "Add the member given by --email as a moderator",
+				Required: false,
+			},
+			&cli.StringFlag{
+				Name:     "memberscsv",
+				Aliases:  []string{"csv"},
+				Value:    "",
+				Usage:    "Path to CSV with list of email addresses formatted as : email, moderator",
+				Required: false,
+			},
+		},
+		Action: func(c *cli.Context) error {
+			members, err := teamMembersFromFlags(c)
+			if err != nil {
+				return err
+			}
+			team, err := app.resolveTeam(c.Args().First())
+			if err != nil {
+				return err
+			}
+			return app.AddTeamMembers(team, members)
+		},
+	}
+}
+
+// RemoveTeamMembersCMD function
+func (app *Application) RemoveTeamMembersCMD() *cli.Command {
+	return &cli.Command{
+		Name:        "remove",
+		Aliases:     []string{"rm"},
+		Description: "Remove members from a team",
+		ArgsUsage:   "<team>",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:     "email",
+				Aliases:  []string{"e"},
+				Value:    "",
+				Usage:    "Email address of the member to remove",
+				Required: false,
+			},
+			&cli.StringFlag{
+				Name:     "memberscsv",
+				Aliases:  []string{"csv"},
+				Value:    "",
+				Usage:    "Path to CSV with list of email addresses formatted as : email",
+				Required: false,
+			},
+		},
+		Action: func(c *cli.Context) error {
+			members, err := teamMembersFromFlags(c)
+			if err != nil {
+				return err
+			}
+			team, err := app.resolveTeam(c.Args().First())
+			if err != nil {
+				return err
+			}
+			return app.RemoveTeamMembers(team, members)
+		},
+	}
+}
+
+// ExportTeamMembersCMD function
+func (app *Application) ExportTeamMembersCMD() *cli.Command {
+	return &cli.Command{
+		Name:        "export",
+		Aliases:     []string{"ex"},
+		Description: "Export members of a team to a CSV file",
+		ArgsUsage:   "<team>",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:     "memberscsv",
+				Aliases:  []string{"csv"},
+				Value:    "",
+				Usage:    "Path to CSV to export to",
+				Required: true,
+			},
+		},
+		Action: func(c *cli.Context) error {
+			team, err := app.resolveTeam(c.Args().First())
+			if err != nil {
+				return err
+			}
+			return app.ExportTeamMembers(team, c.String("memberscsv"))
+		},
+	}
+}
+
+// teamMembersFromFlags reads the members given by --email / --moderator or
+// by the --memberscsv file
+func teamMembersFromFlags(c *cli.Context) ([]userCSV, error) {
+	emailAddress := c.String("email")
+	csvPath := c.String("memberscsv")
+	if emailAddress == "" && csvPath == "" {
+		return nil, errors.New("email or memberscsv is required")
+	}
+	if emailAddress != "" {
+		return []userCSV{{Email: email(emailAddress), IsModerator: c.Bool("moderator")}}, nil
+	}
+
+	return readMembersCSV(csvPath)
+}
+
+// GetTeams retrieves the teams the authenticated user is a member of
+func (app *Application) GetTeams() ([]teams.Team, error) {
+	return app.ListTeams(0)
+}
+
+// GetTeamRooms retrieves the rooms of a team
+func (app *Application) GetTeamRooms(teamID string) ([]rooms.Room, error) {
+	return app.ListRooms("", teamID, 0)
+}
+
+// resolveTeam finds a team by ID or by its name, ignoring case
+func (app *Application) resolveTeam(ref string) (*teams.Team, error) {
+	if ref == "" {
+		return nil, errors.New("team is required")
+	}
+	teamList, err := app.GetTeams()
+	if err != nil {
+		return nil, err
+	}
+	return matchTeam(teamList, ref)
+}
+
+func matchTeam(teamList []teams.Team, ref string) (*teams.Team, error) {
+	var matches []teams.Team
+	for _, team := range teamList {
+		if team.ID == ref {
+			return &team, nil
+		}
+		if strings.EqualFold(strings.TrimSpace(team.Name), strings.TrimSpace(ref)) {
+			matches = append(matches, team)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("Team %s not found", ref)
+	case 1:
+		return &matches[0], nil
+	default:
+		return nil, fmt.Errorf("%d teams are named %s, use the team ID instead", len(matches), ref)
+	}
+}
+
+// teamRoomIDs returns the IDs of the rooms in a team, for use as the target
+// set of bulk room operations
+func (app *Application) teamRoomIDs(ref string) ([]string, error) {
+	team, err := app.resolveTeam(ref)
+	if err != nil {
+		return nil, err
+	}
+	teamRooms, err := app.GetTeamRooms(team.ID)
+	if err != nil {
+		return nil, err
+	}
+	var roomIDs []string
+	for _, room := range teamRooms {
+		roomIDs = append(roomIDs, room.ID)
+	}
+	if len(roomIDs) == 0 {
+		return nil, fmt.Errorf("Team %s has no rooms", team.Name)
+	}
+	return roomIDs, nil
+}
+
+// teamMembershipsByEmail indexes a team's memberships by lower cased email
+func (app *Application) teamMembershipsByEmail(teamID string) (map[string]teammemberships.TeamMembership, error) {
+	items, err := app.ListTeamMemberships(teamID, 0)
+	if err != nil {
+		return nil, err
+	}
+	byEmail := make(map[string]teammemberships.TeamMembership)
+	for _, membership := range items {
+		byEmail[strings.ToLower(membership.PersonEmail)] = membership
+	}
+	return byEmail, nil
+}
+
+// AddTeamMembers adds members to a team. Existing members are updated when
+// their moderator flag differs.
+func (app *Application) AddTeamMembers(team *teams.Team, members []userCSV) error {
+	existing, err := app.teamMembershipsByEmail(team.ID)
+	if err != nil {
+		return err
+	}
+	for _, member := range members {
+		membership, ok := existing[strings.ToLower(string(member.Email))]
+		if ok {
+			if membership.IsModerator == member.IsModerator {
+				continue
+			}
+			if _, err := app.Client.TeamMemberships().Update(membership.ID, member.IsModerator); err != nil {
+				log.Errorf("error updating %s: %s", member.Email, err.Error())
+				continue
+			}
+			log.Infof("Updated %s in %s, moderator: %t", member.Email, team.Name, member.IsModerator)
+			continue
+		}
+		// Sleep to avoid rate limiting
+		time.Sleep(2 * time.Second)
+		_, err := app.Client.TeamMemberships().Create(&teammemberships.TeamMembership{
+			TeamID:      team.ID,
+			PersonEmail: string(member.Email),
+			IsModerator: member.IsModerator,
+		})
+		if err != nil {
+			log.Errorf("error adding %s: %s", member.Email, err.Error())
+			continue
+		}
+		log.Infof("Added %s to %s", member.Email, team.Name)
+	}
+	return nil
+}
+
+// RemoveTeamMembers removes members from a team
+func (app *Application) RemoveTeamMembers(team *teams.Team, members []userCSV) error {
+	existing, err := app.teamMembershipsByEmail(team.ID)
+	if err != nil {
+		return err
+	}
+	for _, member := range members {
+		membership, ok := existing[strings.ToLower(string(member.Email))]
+		if !ok {
+			continue
+		}
+		if err := app.Client.TeamMemberships().Delete(membership.ID); err != nil {
+			log.Errorf("error removing %s: %s", member.Email, err.Error())
+			continue
+		}
+		log.Infof("Removed %s from %s", member.Email, team.Name)
+	}
+	return nil
+}
+
+// ExportTeamMembers writes a team's members to a CSV file in the format
+// accepted by team members add
+func (app *Application) ExportTeamMembers(team *teams.Team, csvPath string) error {
+	items, err := app.ListTeamMemberships(team.ID, 0)
+	if err != nil {
+		return err
+	}
+	csvFile, err := os.Create(csvPath)
+	if err != nil {
+		return err
+	}
+	defer csvFile.Close()
+
+	csvWriter := csv.NewWriter(csvFile)
+	defer csvWriter.Flush()
+	csvWriter.Write([]string{"email", "moderator"})
+	for _, membership := range items {
+		moderator := "false"
+		if membership.IsModerator {
+			moderator = "true"
+		}
+		csvWriter.Write([]string{membership.PersonEmail, moderator})
+	}
+	log.Infof("Exported %d members of %s", len(items), team.Name)
+	return nil
+}
@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/WebexCommunity/webex-go-sdk/v2/teams"
+)
+
+func TestMatchTeam(t *testing.T) {
+	teamList := []teams.Team{
+		{ID: "team-1", Name: "Platform"},
+		{ID: "team-2", Name: "Mobile"},
+		{ID: "team-3", Name: "mobile"},
+	}
+
+	team, err := matchTeam(teamList, "team-2")
+	if err != nil || team.ID != "team-2" {
+		t.Errorf("Expected team-2 by ID, got %v, %v", team, err)
+	}
+
+	team, err = matchTeam(teamList, "platform")
+	if err != nil || team.ID != "team-1" {
+		t.Errorf("Expected team-1 by name, got %v, %v", team, err)
+	}
+
+	if _, err := matchTeam(teamList, "Mobile"); err == nil {
+		t.Error("Expected an error for an ambiguous team name")
+	}
+
+	if _, err := matchTeam(teamList, "Design"); err == nil {
+		t.Error("Expected an error for an unknown team")
+	}
+}
+
+func TestTeamCMDStructure(t *testing.T) {
+	app := &Application{}
+	cmd := app.TeamCMD()
+
+	if cmd.Name != "team" {
+		t.Errorf("Expected command name 'team', got %q", cmd.Name)
+	}
+
+	expectedSubcommands := []string{"list", "create", "rename", "delete", "rooms", "members"}
+	if len(cmd.Subcommands) != len(expectedSubcommands) {
+		t.Fatalf("Expected %d subcommands, got %d", len(expectedSubcommands), len(cmd.Subcommands))
+	}
+	for i, expected := range expectedSubcommands {
+		if cmd.Subcommands[i].Name != expected {
+			t.Errorf("Expected subcommand %q, got %q", expected, cmd.Subcommands[i].Name)
+		}
+	}
+
+	members := cmd.Subcommands[5]
+	expectedMemberSubcommands := []string{"add", "remove", "export"}
+	for i, expected := range expectedMemberSubcommands {
+		if members.Subcommands[i].Name != expected {
+			t.Errorf("Expected members subcommand %q, got %q", expected, members.Subcommands[i].Name)
+		}
+	}
+	if getFlagByName(members.Subcommands[0].Flags, "moderator") == nil {
+		t.Error("Expected members add to have a moderator flag")
+	}
+}
+
+func TestBulkRoomCMDsHaveTeamFlag(t *testing.T) {
+	app := &Application{}
+	for _, cmd := range []string{"addmembers", "removemembers", "syncmembers", "broadcast"} {
+		var found bool
+		for _, sub := range app.RoomCMD().Subcommands {
+			if sub.Name != cmd {
+				continue
+			}
+			found = true
+			if getFlagByName(sub.Flags, "team") == nil {
+				t.Errorf("Expected %s to have a team flag", cmd)
+			}
+		}
+		if !found {
+			t.Errorf("Expected room subcommand %q", cmd)
+		}
+	}
+}
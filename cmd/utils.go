@@ -1,14 +1,18 @@
 package cmd
 
 import (
+	"bufio"
 	"crypto/md5"
 	"encoding/csv"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"hash/adler32"
 	"io"
 	"net/url"
+	"os"
 	"path"
+	"path/filepath"
 	"reflect"
 	"strconv"
 	"strings"
@@ -16,6 +20,7 @@ import (
 
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 	"github.com/go-ozzo/ozzo-validation/v4/is"
+	"github.com/tejzpr/webex-teams-cli/cmd/webexid"
 )
 
 func (app *Application) isValidUrl(toTest string) bool {
@@ -43,11 +48,7 @@ func (app *Application) getFilenameWithoutExtension(filePath string) (string, st
 }
 
 func (app *Application) validateUUID(str string) error {
-	err := validation.Validate(str, validation.Required, is.UUID)
-	if err != nil {
-		return err
-	}
-	return nil
+	return webexid.ValidateUUID(str)
 }
 
 func (app *Application) getAdlerHash(str string) string {
@@ -56,27 +57,48 @@ func (app *Application) getAdlerHash(str string) string {
 	return fmt.Sprint(adlerHash.Sum32())
 }
 
-func (app *Application) parseRoomID(str string) (string, error) {
-	return str, nil
-	/*
-		// Commenting out this code because parsing of roomid to uuid will no longer be supported
-		err := app.validateUUID(str)
-		if err == nil {
-			return str, nil
-		}
+// confirmPrompt returns whether to continue. A non empty confirm flag value
+// answers the prompt, otherwise the user is asked on stdin. Anything but an
+// explicit yes, including an empty answer or a closed stdin, is a no.
+func confirmPrompt(confirm string, prompt string) bool {
+	if confirm != "" {
+		return confirmAnswer(confirm)
+	}
+	reader := bufio.NewReader(os.Stdin)
+	fmt.Printf("%s (y/n) : ", prompt)
+	text, _ := reader.ReadString('\n')
+	return confirmAnswer(text)
+}
 
-		sDec, err := b64.StdEncoding.DecodeString(str)
-		if err != nil {
-			return "", err
-		}
-		urn := string(sDec)
-		parts := strings.Split(urn, "/ROOM/")
-		err = app.validateUUID(parts[1])
+// confirmAnswer reports whether answer is y or yes
+func confirmAnswer(answer string) bool {
+	answer = strings.TrimSpace(strings.ToLower(answer))
+	return answer == "y" || answer == "yes"
+}
+
+// openCSVFile opens a .csv file, expanding a leading ~ to the home directory
+func openCSVFile(csvPath string) (*os.File, error) {
+	if !strings.HasSuffix(csvPath, ".csv") {
+		return nil, errors.New("Only CSV files are supported")
+	}
+	if strings.HasPrefix(csvPath, "~") {
+		home, err := os.UserHomeDir()
 		if err != nil {
-			return "", err
+			return nil, err
 		}
-		return parts[1], nil
-	*/
+		csvPath = path.Join(home, csvPath[len("~"):])
+	}
+	absFilePath, err := filepath.Abs(csvPath)
+	if err != nil {
+		return nil, err
+	}
+	return os.Open(absFilePath)
+}
+
+// parseRoomID returns the API ID of a room given as an API ID, a UUID, a web
+// client space URL or a webexteams:// link
+func (app *Application) parseRoomID(str string) (string, error) {
+	return webexid.Parse(str, webexid.Room)
 }
 
 // userCSV struct
@@ -114,7 +136,7 @@ func ParseUsersCSV(r io.Reader) chan UserCSVReturn {
 					}
 				}
 				return -1
-			}(et.Field(i).Tag.Get("csv"), header)
+			}(strings.Split(et.Field(i).Tag.Get("csv"), ",")[0], header)
 		}
 		for {
 			var e = userCSV{}
@@ -163,6 +185,23 @@ func ParseUsersCSV(r io.Reader) chan UserCSVReturn {
 	return c
 }
 
+// readMembersCSV reads a members CSV formatted as : email, moderator
+func readMembersCSV(csvPath string) ([]userCSV, error) {
+	csvFile, err := openCSVFile(csvPath)
+	if err != nil {
+		return nil, err
+	}
+	defer csvFile.Close()
+	var members []userCSV
+	for v := range ParseUsersCSV(csvFile) {
+		if v.Err != nil {
+			return nil, v.Err
+		}
+		members = append(members, v.Value)
+	}
+	return members, nil
+}
+
 // roomsCSV struct
 type roomsCSV struct {
 	RoomID string `csv:"roomids"`
@@ -197,7 +236,7 @@ func ParseRoomIDsCSV(r io.Reader) chan RoomsIDsCSVReturn {
 					}
 				}
 				return -1
-			}(et.Field(i).Tag.Get("csv"), header)
+			}(strings.Split(et.Field(i).Tag.Get("csv"), ",")[0], header)
 		}
 		for {
 			var e = roomsCSV{}
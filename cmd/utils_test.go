@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"encoding/base64"
 	"strings"
 	"testing"
 )
@@ -148,22 +149,30 @@ func TestGetAdlerHash(t *testing.T) {
 
 func TestParseRoomID(t *testing.T) {
 	app := &Application{}
+	const uuid = "550e8400-e29b-41d4-a716-446655440000"
+	roomID := base64.RawURLEncoding.EncodeToString([]byte("ciscospark://us/ROOM/" + uuid))
+	peopleID := base64.RawURLEncoding.EncodeToString([]byte("ciscospark://us/PEOPLE/" + uuid))
 
 	tests := []struct {
+		name     string
 		input    string
 		expected string
+		wantErr  bool
 	}{
-		{"simple-id", "simple-id"},
-		{"", ""},
-		{"550e8400-e29b-41d4-a716-446655440000", "550e8400-e29b-41d4-a716-446655440000"},
-		{"any-string", "any-string"},
+		{"api id", roomID, roomID, false},
+		{"uuid", uuid, roomID, false},
+		{"space url", "https://web.webex.com/spaces/" + uuid, roomID, false},
+		{"webexteams link", "webexteams://im?space=" + uuid, roomID, false},
+		{"people id", peopleID, "", true},
+		{"empty", "", "", true},
+		{"any string", "any-string", "", true},
 	}
 
 	for _, tt := range tests {
-		t.Run(tt.input, func(t *testing.T) {
+		t.Run(tt.name, func(t *testing.T) {
 			got, err := app.parseRoomID(tt.input)
-			if err != nil {
-				t.Errorf("parseRoomID(%q) returned error: %v", tt.input, err)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseRoomID(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
 			}
 			if got != tt.expected {
 				t.Errorf("parseRoomID(%q) = %q, want %q", tt.input, got, tt.expected)
@@ -282,3 +291,21 @@ func TestParseRoomIDsCSVInvalidCSV(t *testing.T) {
 		t.Error("Expected error for CSV with mismatched columns")
 	}
 }
+
+func TestParseUsersCSVModerator(t *testing.T) {
+	ch := ParseUsersCSV(strings.NewReader("email,moderator\nuser1@example.com,true\nuser2@example.com,false"))
+
+	var results []UserCSVReturn
+	for result := range ch {
+		results = append(results, result)
+	}
+	if len(results) != 2 {
+		t.Fatalf("Expected 2 results, got %d", len(results))
+	}
+	if !results[0].Value.IsModerator {
+		t.Error("Expected first row to be a moderator")
+	}
+	if results[1].Value.IsModerator {
+		t.Error("Expected second row not to be a moderator")
+	}
+}
@@ -0,0 +1,193 @@
+// Package webexid decodes and encodes Webex API (Hydra) IDs.
+//
+// A Hydra ID is the unpadded base64 encoding of a URN such as
+// ciscospark://us/ROOM/<uuid>. Commands also accept the raw UUID, web client
+// space URLs and webexteams:// links, which all carry the UUID only.
+package webexid
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/go-ozzo/ozzo-validation/v4/is"
+)
+
+// ResourceType is the resource segment of a Hydra ID
+type ResourceType string
+
+// Resource types of Hydra IDs
+const (
+	Room             ResourceType = "ROOM"
+	People           ResourceType = "PEOPLE"
+	Message          ResourceType = "MESSAGE"
+	Membership       ResourceType = "MEMBERSHIP"
+	Team             ResourceType = "TEAM"
+	TeamMembership   ResourceType = "TEAM_MEMBERSHIP"
+	Organization     ResourceType = "ORGANIZATION"
+	Webhook          ResourceType = "WEBHOOK"
+	AttachmentAction ResourceType = "ATTACHMENT_ACTION"
+)
+
+// ResourceTypes lists the supported resource types
+var ResourceTypes = []ResourceType{Room, People, Message, Membership, Team, TeamMembership, Organization, Webhook, AttachmentAction}
+
+// DefaultCluster is used when encoding IDs known only by their UUID
+const DefaultCluster = "us"
+
+const scheme = "ciscospark://"
+
+// ID is a decoded Hydra ID
+type ID struct {
+	Cluster string       `json:"cluster"`
+	Type    ResourceType `json:"type"`
+	UUID    string       `json:"uuid"`
+}
+
+// URN returns the ciscospark:// form of the ID
+func (id ID) URN() string {
+	return fmt.Sprintf("%s%s/%s/%s", scheme, id.Cluster, id.Type, id.UUID)
+}
+
+// String returns the Hydra ID used by the API
+func (id ID) String() string {
+	return base64.RawURLEncoding.EncodeToString([]byte(id.URN()))
+}
+
+// ParseResourceType validates a resource type, case-insensitively. The
+// plural forms used by the API paths such as rooms or messages are accepted.
+func ParseResourceType(s string) (ResourceType, error) {
+	upper := strings.ToUpper(strings.TrimSpace(s))
+	for _, resourceType := range ResourceTypes {
+		if upper == string(resourceType) || upper == string(resourceType)+"S" {
+			return resourceType, nil
+		}
+	}
+	return "", fmt.Errorf("unknown resource type %s", s)
+}
+
+// ValidateUUID returns an error unless s is a UUID
+func ValidateUUID(s string) error {
+	return validation.Validate(s, validation.Required, is.UUID)
+}
+
+// Encode builds the Hydra ID of a resource known by its UUID
+func Encode(resourceType ResourceType, uuid string) (string, error) {
+	if _, err := ParseResourceType(string(resourceType)); err != nil {
+		return "", err
+	}
+	if err := ValidateUUID(uuid); err != nil {
+		return "", fmt.Errorf("invalid UUID %s: %v", uuid, err)
+	}
+	return ID{Cluster: DefaultCluster, Type: resourceType, UUID: strings.ToLower(uuid)}.String(), nil
+}
+
+// Decode decodes a Hydra ID. Standard and URL-safe base64, with or without
+// padding, are accepted.
+func Decode(s string) (ID, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return ID{}, errors.New("ID is empty")
+	}
+	trimmed := strings.TrimRight(s, "=")
+	var decoded []byte
+	var err error
+	for _, encoding := range []*base64.Encoding{base64.RawURLEncoding, base64.RawStdEncoding} {
+		if decoded, err = encoding.DecodeString(trimmed); err == nil {
+			break
+		}
+	}
+	if err != nil {
+		return ID{}, fmt.Errorf("%s is not a Webex ID", s)
+	}
+	return ParseURN(string(decoded))
+}
+
+// ParseURN parses the ciscospark:// form of an ID. The cluster may contain
+// slashes, so the type and UUID are read from the end.
+func ParseURN(urn string) (ID, error) {
+	if !strings.HasPrefix(urn, scheme) {
+		return ID{}, fmt.Errorf("%s is not a Webex ID", urn)
+	}
+	parts := strings.Split(strings.TrimPrefix(urn, scheme), "/")
+	if len(parts) < 3 {
+		return ID{}, fmt.Errorf("%s is not a Webex ID", urn)
+	}
+	id := ID{
+		Cluster: strings.Join(parts[:len(parts)-2], "/"),
+		Type:    ResourceType(parts[len(parts)-2]),
+		UUID:    parts[len(parts)-1],
+	}
+	if id.Cluster == "" || id.Type == "" || id.UUID == "" {
+		return ID{}, fmt.Errorf("%s is not a Webex ID", urn)
+	}
+	return id, nil
+}
+
+// Parse returns the Hydra ID of a resource of type want referenced by s.
+// s may be a Hydra ID, which is returned unchanged, a ciscospark:// URN, a
+// raw UUID or, for rooms, a web client space URL or webexteams:// link.
+func Parse(s string, want ResourceType) (string, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return "", fmt.Errorf("%s ID is empty", strings.ToLower(string(want)))
+	}
+	if ValidateUUID(s) == nil {
+		return Encode(want, s)
+	}
+	if strings.HasPrefix(s, scheme) {
+		id, err := ParseURN(s)
+		if err != nil {
+			return "", err
+		}
+		if err := checkType(id, want); err != nil {
+			return "", err
+		}
+		return id.String(), nil
+	}
+	if uuid, ok := spaceUUID(s); ok {
+		if want != Room {
+			return "", fmt.Errorf("%s is a space link, expected a %s ID", s, strings.ToLower(string(want)))
+		}
+		return Encode(Room, uuid)
+	}
+	id, err := Decode(s)
+	if err != nil {
+		return "", err
+	}
+	if err := checkType(id, want); err != nil {
+		return "", err
+	}
+	return s, nil
+}
+
+func checkType(id ID, want ResourceType) error {
+	if id.Type != want {
+		return fmt.Errorf("%s is a %s ID, expected a %s ID", id.UUID, strings.ToLower(string(id.Type)), strings.ToLower(string(want)))
+	}
+	return nil
+}
+
+// spaceUUID extracts the space UUID of web client URLs such as
+// https://web.webex.com/spaces/<uuid> and of webexteams://im?space=<uuid>
+func spaceUUID(s string) (string, bool) {
+	u, err := url.Parse(s)
+	if err != nil || u.Scheme == "" {
+		return "", false
+	}
+	if uuid := u.Query().Get("space"); uuid != "" && ValidateUUID(uuid) == nil {
+		return uuid, true
+	}
+	segments := strings.Split(strings.Trim(u.Path, "/"), "/")
+	for i := 0; i+1 < len(segments); i++ {
+		if segments[i] == "spaces" || segments[i] == "rooms" {
+			if ValidateUUID(segments[i+1]) == nil {
+				return segments[i+1], true
+			}
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,123 @@
+package webexid
+
+import (
+	"encoding/base64"
+	"testing"
+)
+
+const testUUID = "550e8400-e29b-41d4-a716-446655440000"
+
+func TestEncode(t *testing.T) {
+	got, err := Encode(AttachmentAction, testUUID)
+	if err != nil {
+		t.Fatalf("Encode() returned error: %v", err)
+	}
+	decoded, err := base64.RawURLEncoding.DecodeString(got)
+	if err != nil {
+		t.Fatalf("Encode() returned invalid base64: %v", err)
+	}
+	expected := "ciscospark://us/ATTACHMENT_ACTION/" + testUUID
+	if string(decoded) != expected {
+		t.Errorf("Encode() decoded = %q, want %q", string(decoded), expected)
+	}
+
+	if _, err := Encode(Room, "not-a-uuid"); err == nil {
+		t.Error("Expected Encode to reject an invalid UUID")
+	}
+	if _, err := Encode("SPACE", testUUID); err == nil {
+		t.Error("Expected Encode to reject an unknown resource type")
+	}
+}
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected ID
+		wantErr  bool
+	}{
+		{"url encoding", base64.RawURLEncoding.EncodeToString([]byte("ciscospark://us/PEOPLE/" + testUUID)), ID{"us", People, testUUID}, false},
+		{"padded std encoding", base64.StdEncoding.EncodeToString([]byte("ciscospark://us/MESSAGE/" + testUUID)), ID{"us", Message, testUUID}, false},
+		{"regional cluster", base64.RawStdEncoding.EncodeToString([]byte("ciscospark://urn:TEAM:eu-central-1_k/ROOM/" + testUUID)), ID{"urn:TEAM:eu-central-1_k", Room, testUUID}, false},
+		{"not base64", "not an id!", ID{}, true},
+		{"not a urn", base64.RawURLEncoding.EncodeToString([]byte("hello world")), ID{}, true},
+		{"empty", "", ID{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Decode(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Decode(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if got != tt.expected {
+				t.Errorf("Decode(%q) = %+v, want %+v", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	for _, resourceType := range ResourceTypes {
+		encoded, err := Encode(resourceType, testUUID)
+		if err != nil {
+			t.Fatalf("Encode(%s) returned error: %v", resourceType, err)
+		}
+		id, err := Decode(encoded)
+		if err != nil {
+			t.Fatalf("Decode(%s) returned error: %v", encoded, err)
+		}
+		if id.Type != resourceType || id.UUID != testUUID || id.Cluster != DefaultCluster {
+			t.Errorf("Round trip of %s returned %+v", resourceType, id)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	roomID, _ := Encode(Room, testUUID)
+	stdRoomID := base64.StdEncoding.EncodeToString([]byte("ciscospark://us/ROOM/" + testUUID))
+	teamID, _ := Encode(Team, testUUID)
+
+	tests := []struct {
+		name     string
+		input    string
+		want     ResourceType
+		expected string
+		wantErr  bool
+	}{
+		{"api id", roomID, Room, roomID, false},
+		{"api id kept as is", stdRoomID, Room, stdRoomID, false},
+		{"uuid", testUUID, Room, roomID, false},
+		{"uuid as team", testUUID, Team, teamID, false},
+		{"urn", "ciscospark://us/ROOM/" + testUUID, Room, roomID, false},
+		{"web client url", "https://web.webex.com/spaces/" + testUUID + "/chat", Room, roomID, false},
+		{"teams url", "https://teams.webex.com/spaces/" + testUUID, Room, roomID, false},
+		{"webexteams link", "webexteams://im?space=" + testUUID, Room, roomID, false},
+		{"space link as team", "webexteams://im?space=" + testUUID, Team, "", true},
+		{"wrong type", teamID, Room, "", true},
+		{"garbage", "room123", Room, "", true},
+		{"empty", " ", Room, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.input, tt.want)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if got != tt.expected {
+				t.Errorf("Parse(%q) = %q, want %q", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseResourceType(t *testing.T) {
+	for input, expected := range map[string]ResourceType{"room": Room, "ROOMS": Room, "people": People, "team_membership": TeamMembership} {
+		got, err := ParseResourceType(input)
+		if err != nil || got != expected {
+			t.Errorf("ParseResourceType(%q) = %q, %v, want %q", input, got, err, expected)
+		}
+	}
+	if _, err := ParseResourceType("space"); err == nil {
+		t.Error("Expected ParseResourceType to reject space")
+	}
+}
@@ -14,6 +14,7 @@ func (app *Application) WebexUtils() *cli.Command {
 		Subcommands: []*cli.Command{
 			app.FindRoomCMD(),
 			app.ListRoomsCMD(),
+			app.IDCMD(),
 		},
 		Action: func(c *cli.Context) error {
 			return nil
@@ -0,0 +1,632 @@
+package cmd
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/WebexCommunity/webex-go-sdk/v2/webhooks"
+	log "github.com/sirupsen/logrus"
+	"github.com/urfave/cli/v2"
+)
+
+const webhookSecretsFile = "webhooks.json"
+
+// webhookSignatureHeader carries the HMAC-SHA1 of the body signed with the
+// webhook's secret
+const webhookSignatureHeader = "X-Spark-Signature"
+
+// webhookSecret is the locally stored secret of a webhook. The API never
+// returns secrets, so they are kept to validate deliveries later.
+type webhookSecret struct {
+	Name      string `json:"name"`
+	TargetURL string `json:"targetUrl"`
+	Secret    string `json:"secret"`
+}
+
+// webhookResult is a row of webhooks purge
+type webhookResult struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Detail string `json:"detail,omitempty"`
+}
+
+var webhookResultHeader = []string{"id", "name", "status", "detail"}
+
+var webhookHeader = []string{"id", "name", "resource", "event", "filter", "targetUrl", "status", "created", "secretStored"}
+
+// WebhooksCMD function
+func (app *Application) WebhooksCMD() *cli.Command {
+	return &cli.Command{
+		Name:    "webhooks",
+		Aliases: []string{"wh"},
+		Usage:   "Manage webhooks",
+		Subcommands: []*cli.Command{
+			app.listWebhooksCMD(),
+			app.createWebhookCMD(),
+			app.updateWebhookCMD(),
+			app.deleteWebhookCMD(),
+			app.purgeWebhooksCMD(),
+			app.testWebhookCMD(),
+		},
+		Action: func(c *cli.Context) error {
+			return nil
+		},
+	}
+}
+
+func webhookOutputFlag() cli.Flag {
+	return &cli.StringFlag{
+		Name:     "output",
+		Aliases:  []string{"o"},
+		Value:    outputTable,
+		Usage:    "Output format, one of table, csv or json. Default is table",
+		Required: false,
+	}
+}
+
+func (app *Application) listWebhooksCMD() *cli.Command {
+	return &cli.Command{
+		Name:        "list",
+		Aliases:     []string{"ls"},
+		Description: "List the webhooks of the access token",
+		Flags:       []cli.Flag{webhookOutputFlag(), limitFlag()},
+		Action: func(c *cli.Context) error {
+			format := c.String("output")
+			if err := validateOutputFormat(format); err != nil {
+				return err
+			}
+			hooks, err := app.ListWebhooks(c.Int("limit"))
+			if err != nil {
+				return err
+			}
+			return app.writeWebhooks(format, hooks)
+		},
+	}
+}
+
+func (app *Application) createWebhookCMD() *cli.Command {
+	return &cli.Command{
+		Name:        "create",
+		Aliases:     []string{"cr"},
+		Description: "Create a webhook. A secret is generated unless one is given and is stored in the state directory",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:     "name",
+				Aliases:  []string{"n"},
+				Value:    "",
+				Usage:    "Name of the webhook",
+				Required: true,
+			},
+			&cli.StringFlag{
+				Name:     "target",
+				Aliases:  []string{"t"},
+				Value:    "",
+				Usage:    "URL that receives the webhook deliveries",
+				Required: true,
+			},
+			&cli.StringFlag{
+				Name:     "resource",
+				Aliases:  []string{"r"},
+				Value:    "all",
+				Usage:    "Resource to watch eg. messages, memberships, rooms or attachmentActions. Default is all",
+				Required: false,
+			},
+			&cli.StringFlag{
+				Name:     "event",
+				Aliases:  []string{"e"},
+				Value:    "all",
+				Usage:    "Event to watch eg. created, updated or deleted. Default is all",
+				Required: false,
+			},
+			&cli.StringFlag{
+				Name:     "filter",
+				Aliases:  []string{"f"},
+				Value:    "",
+				Usage:    "Filter of the deliveries eg. roomId=<roomID>",
+				Required: false,
+			},
+			&cli.StringFlag{
+				Name:     "secret",
+				Aliases:  []string{"s"},
+				Value:    "",
+				Usage:    "Secret used to sign deliveries. Generated when not set",
+				Required: false,
+			},
+			webhookOutputFlag(),
+		},
+		Action: func(c *cli.Context) error {
+			format := c.String("output")
+			if err := validateOutputFormat(format); err != nil {
+				return err
+			}
+			if err := validateTargetURL(c.String("target")); err != nil {
+				return err
+			}
+			secret := c.String("secret")
+			if secret == "" {
+				var err error
+				if secret, err = newWebhookSecret(); err != nil {
+					return err
+				}
+			}
+			hook, err := app.Client.Webhooks().Create(&webhooks.Webhook{
+				Name:      c.String("name"),
+				TargetURL: c.String("target"),
+				Resource:  c.String("resource"),
+				Event:     c.String("event"),
+				Filter:    c.String("filter"),
+				Secret:    secret,
+			})
+			if err != nil {
+				return err
+			}
+			if err := app.storeWebhookSecret(hook, secret); err != nil {
+				return err
+			}
+			log.Infof("Created webhook %s", hook.Name)
+			return app.writeWebhooks(format, []webhooks.Webhook{*hook})
+		},
+	}
+}
+
+func (app *Application) updateWebhookCMD() *cli.Command {
+	return &cli.Command{
+		Name:        "update",
+		Aliases:     []string{"up"},
+		Usage:       "update <webhookID>",
+		Description: "Change the name, target URL, secret or status of a webhook",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:     "name",
+				Aliases:  []string{"n"},
+				Value:    "",
+				Usage:    "New name of the webhook",
+				Required: false,
+			},
+			&cli.StringFlag{
+				Name:     "target",
+				Aliases:  []string{"t"},
+				Value:    "",
+				Usage:    "New target URL of the webhook",
+				Required: false,
+			},
+			&cli.StringFlag{
+				Name:     "secret",
+				Aliases:  []string{"s"},
+				Value:    "",
+				Usage:    "New secret of the webhook",
+				Required: false,
+			},
+			&cli.BoolFlag{
+				Name:     "rotate-secret",
+				Aliases:  []string{"rs"},
+				Value:    false,
+				Usage:    "Generate a new secret",
+				Required: false,
+			},
+			&cli.StringFlag{
+				Name:     "status",
+				Value:    "",
+				Usage:    "Set to active to re-enable a webhook the API disabled, or inactive",
+				Required: false,
+			},
+			webhookOutputFlag(),
+		},
+		Action: func(c *cli.Context) error {
+			format := c.String("output")
+			if err := validateOutputFormat(format); err != nil {
+				return err
+			}
+			if c.Args().Len() != 1 {
+				return errors.New("a webhook ID is required")
+			}
+			current, err := app.Client.Webhooks().Get(c.Args().First())
+			if err != nil {
+				return err
+			}
+			update := webhooks.NewUpdateWebhook(current.Name, current.TargetURL, "", current.Status)
+			if name := c.String("name"); name != "" {
+				update.Name = name
+			}
+			if target := c.String("target"); target != "" {
+				if err := validateTargetURL(target); err != nil {
+					return err
+				}
+				update.TargetURL = target
+			}
+			switch status := c.String("status"); status {
+			case "":
+			case "active", "inactive":
+				update.Status = status
+			default:
+				return errors.New("Allowed values for status flag are active and inactive")
+			}
+			if c.Bool("rotate-secret") {
+				if update.Secret, err = newWebhookSecret(); err != nil {
+					return err
+				}
+			} else if secret := c.String("secret"); secret != "" {
+				update.Secret = secret
+			}
+
+			hook, err := app.Client.Webhooks().Update(current.ID, update)
+			if err != nil {
+				return err
+			}
+			secret := update.Secret
+			if secret == "" {
+				secret = app.webhookSecrets()[hook.ID].Secret
+			}
+			if err := app.storeWebhookSecret(hook, secret); err != nil {
+				return err
+			}
+			log.Infof("Updated webhook %s", hook.Name)
+			return app.writeWebhooks(format, []webhooks.Webhook{*hook})
+		},
+	}
+}
+
+func (app *Application) deleteWebhookCMD() *cli.Command {
+	return &cli.Command{
+		Name:        "delete",
+		Aliases:     []string{"del"},
+		Usage:       "delete <webhookID>",
+		Description: "Delete a webhook and its stored secret",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:     "confirm",
+				Aliases:  []string{"c"},
+				Value:    "",
+				Usage:    "Continue without confirmation? Allowed values are 'y' or 'n' ",
+				Required: false,
+			},
+		},
+		Action: func(c *cli.Context) error {
+			if c.Args().Len() != 1 {
+				return errors.New("a webhook ID is required")
+			}
+			hook, err := app.Client.Webhooks().Get(c.Args().First())
+			if err != nil {
+				return err
+			}
+			if !confirmPrompt(c.String("confirm"), fmt.Sprintf("Delete webhook %s (%s)?", hook.Name, hook.TargetURL)) {
+				return nil
+			}
+			if err := app.deleteWebhook(hook.ID); err != nil {
+				return err
+			}
+			log.Infof("Deleted webhook %s", hook.Name)
+			return nil
+		},
+	}
+}
+
+func (app *Application) purgeWebhooksCMD() *cli.Command {
+	return &cli.Command{
+		Name:        "purge",
+		Description: "Delete every webhook, or those matching a target URL prefix or that the API disabled",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:     "target",
+				Aliases:  []string{"t"},
+				Value:    "",
+				Usage:    "Only webhooks whose target URL starts with this prefix",
+				Required: false,
+			},
+			&cli.BoolFlag{
+				Name:     "inactive",
+				Value:    false,
+				Usage:    "Only webhooks that are not active",
+				Required: false,
+			},
+			&cli.StringFlag{
+				Name:     "confirm",
+				Aliases:  []string{"c"},
+				Value:    "",
+				Usage:    "Continue without confirmation? Allowed values are 'y' or 'n' ",
+				Required: false,
+			},
+			webhookOutputFlag(),
+		},
+		Action: func(c *cli.Context) error {
+			format := c.String("output")
+			if err := validateOutputFormat(format); err != nil {
+				return err
+			}
+			hooks, err := app.ListAllWebhooks()
+			if err != nil {
+				return err
+			}
+			selected := filterWebhooks(hooks, c.String("target"), c.Bool("inactive"))
+			if len(selected) == 0 {
+				log.Info("No webhooks to delete")
+				return nil
+			}
+			fmt.Fprintln(os.Stderr, "Webhooks to delete:")
+			for _, hook := range selected {
+				fmt.Fprintf(os.Stderr, "  %s (%s)\n", hook.Name, hook.TargetURL)
+			}
+			if !confirmPrompt(c.String("confirm"), fmt.Sprintf("Delete %d webhook(s)?", len(selected))) {
+				return nil
+			}
+
+			results := make([]webhookResult, 0, len(selected))
+			for _, hook := range selected {
+				result := webhookResult{ID: hook.ID, Name: hook.Name, Status: "deleted"}
+				if err := app.deleteWebhook(hook.ID); err != nil {
+					result.Status = "failed"
+					result.Detail = err.Error()
+				}
+				results = append(results, result)
+			}
+			rows := make([][]string, 0, len(results))
+			for _, result := range results {
+				rows = append(rows, []string{result.ID, result.Name, result.Status, result.Detail})
+			}
+			return writeOutput(os.Stdout, format, webhookResultHeader, rows, results)
+		},
+	}
+}
+
+func (app *Application) testWebhookCMD() *cli.Command {
+	return &cli.Command{
+		Name:        "test",
+		Usage:       "test [webhookID]",
+		Description: "Send a synthetic signed delivery to a webhook receiver. Pass a webhook ID to use its target URL and stored secret",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:     "target",
+				Aliases:  []string{"t"},
+				Value:    "",
+				Usage:    "URL to send the delivery to",
+				Required: false,
+			},
+			&cli.StringFlag{
+				Name:     "secret",
+				Aliases:  []string{"s"},
+				Value:    "",
+				Usage:    "Secret to sign the delivery with",
+				Required: false,
+			},
+			&cli.StringFlag{
+				Name:     "resource",
+				Aliases:  []string{"r"},
+				Value:    "messages",
+				Usage:    "Resource of the delivery. Default is messages",
+				Required: false,
+			},
+			&cli.StringFlag{
+				Name:     "event",
+				Aliases:  []string{"e"},
+				Value:    "created",
+				Usage:    "Event of the delivery. Default is created",
+				Required: false,
+			},
+			&cli.StringFlag{
+				Name:     "data",
+				Aliases:  []string{"d"},
+				Value:    "",
+				Usage:    "JSON object sent as the data of the delivery",
+				Required: false,
+			},
+		},
+		Action: func(c *cli.Context) error {
+			delivery := webhookDelivery{
+				ID:        "test",
+				Name:      "webex-teams-cli test",
+				TargetURL: c.String("target"),
+				Resource:  c.String("resource"),
+				Event:     c.String("event"),
+				Status:    "active",
+				Created:   time.Now().UTC(),
+				Data:      json.RawMessage(`{"id":"test"}`),
+			}
+			secret := c.String("secret")
+			if c.Args().Len() > 0 {
+				stored, ok := app.webhookSecrets()[c.Args().First()]
+				if !ok {
+					return fmt.Errorf("No stored secret for webhook %s", c.Args().First())
+				}
+				delivery.ID = c.Args().First()
+				delivery.Name = stored.Name
+				if delivery.TargetURL == "" {
+					delivery.TargetURL = stored.TargetURL
+				}
+				if secret == "" {
+					secret = stored.Secret
+				}
+			}
+			if err := validateTargetURL(delivery.TargetURL); err != nil {
+				return err
+			}
+			if data := c.String("data"); data != "" {
+				if !json.Valid([]byte(data)) {
+					return errors.New("data is not valid JSON")
+				}
+				delivery.Data = json.RawMessage(data)
+			}
+			if app.Me != nil {
+				delivery.ActorID = app.Me.ID
+				delivery.CreatedBy = app.Me.ID
+				delivery.OrgID = app.Me.OrgID
+			}
+
+			status, body, err := sendWebhookDelivery(http.DefaultClient, delivery, secret)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("%d %s\n", status, body)
+			if status >= 300 {
+				return fmt.Errorf("Target responded with status %d", status)
+			}
+			return nil
+		},
+	}
+}
+
+// webhookDelivery is the envelope Webex posts to a webhook's target URL
+type webhookDelivery struct {
+	ID        string          `json:"id"`
+	Name      string          `json:"name"`
+	TargetURL string          `json:"targetUrl"`
+	Resource  string          `json:"resource"`
+	Event     string          `json:"event"`
+	Filter    string          `json:"filter,omitempty"`
+	OrgID     string          `json:"orgId,omitempty"`
+	CreatedBy string          `json:"createdBy,omitempty"`
+	AppID     string          `json:"appId,omitempty"`
+	OwnedBy   string          `json:"ownedBy,omitempty"`
+	Status    string          `json:"status"`
+	Created   time.Time       `json:"created"`
+	ActorID   string          `json:"actorId,omitempty"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// signWebhookPayload returns the hex HMAC-SHA1 that Webex sends in the
+// X-Spark-Signature header
+func signWebhookPayload(secret string, body []byte) string {
+	mac := hmac.New(sha1.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// sendWebhookDelivery posts a delivery signed with secret, when set, and
+// returns the status code and body of the response
+func sendWebhookDelivery(client *http.Client, delivery webhookDelivery, secret string) (int, string, error) {
+	body, err := json.Marshal(delivery)
+	if err != nil {
+		return 0, "", err
+	}
+	req, err := http.NewRequest(http.MethodPost, delivery.TargetURL, bytes.NewReader(body))
+	if err != nil {
+		return 0, "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if secret != "" {
+		req.Header.Set(webhookSignatureHeader, signWebhookPayload(secret, body))
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, "", err
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if err != nil {
+		return 0, "", err
+	}
+	return resp.StatusCode, string(respBody), nil
+}
+
+// ListAllWebhooks retrieves every webhook across all pages
+func (app *Application) ListAllWebhooks() ([]webhooks.Webhook, error) {
+	return app.ListWebhooks(0)
+}
+
+// ListWebhooks retrieves up to limit webhooks. A limit of 0 reads every page.
+func (app *Application) ListWebhooks(limit int) ([]webhooks.Webhook, error) {
+	params := url.Values{"max": {"100"}}
+	return listItems[webhooks.Webhook](app, "webhooks", params, limit)
+}
+
+func (app *Application) deleteWebhook(webhookID string) error {
+	if err := app.Client.Webhooks().Delete(webhookID); err != nil {
+		return err
+	}
+	return app.updateWebhookSecrets(func(secrets map[string]webhookSecret) {
+		delete(secrets, webhookID)
+	})
+}
+
+// filterWebhooks selects webhooks whose target URL starts with prefix and,
+// when inactive is set, that are not active
+func filterWebhooks(hooks []webhooks.Webhook, prefix string, inactive bool) []webhooks.Webhook {
+	selected := make([]webhooks.Webhook, 0)
+	for _, hook := range hooks {
+		if !strings.HasPrefix(hook.TargetURL, prefix) {
+			continue
+		}
+		if inactive && hook.Status == "active" {
+			continue
+		}
+		selected = append(selected, hook)
+	}
+	return selected
+}
+
+func (app *Application) writeWebhooks(format string, hooks []webhooks.Webhook) error {
+	secrets := app.webhookSecrets()
+	sort.SliceStable(hooks, func(i, j int) bool { return hooks[i].Name < hooks[j].Name })
+	rows := make([][]string, 0, len(hooks))
+	for _, hook := range hooks {
+		_, stored := secrets[hook.ID]
+		rows = append(rows, []string{hook.ID, hook.Name, hook.Resource, hook.Event, hook.Filter, hook.TargetURL, hook.Status, formatReportTime(hook.Created), strconv.FormatBool(stored)})
+	}
+	return writeOutput(os.Stdout, format, webhookHeader, rows, hooks)
+}
+
+// webhookSecrets returns the stored secrets by webhook ID. Errors reading
+// the file are logged and yield no secrets.
+func (app *Application) webhookSecrets() map[string]webhookSecret {
+	secrets := make(map[string]webhookSecret)
+	statePath, err := app.statePath(webhookSecretsFile)
+	if err == nil {
+		err = loadStateFile(statePath, &secrets)
+	}
+	if err != nil {
+		log.Debugf("Error reading webhook secrets: %s", err.Error())
+	}
+	return secrets
+}
+
+func (app *Application) storeWebhookSecret(hook *webhooks.Webhook, secret string) error {
+	if secret == "" {
+		return nil
+	}
+	return app.updateWebhookSecrets(func(secrets map[string]webhookSecret) {
+		secrets[hook.ID] = webhookSecret{Name: hook.Name, TargetURL: hook.TargetURL, Secret: secret}
+	})
+}
+
+func (app *Application) updateWebhookSecrets(modify func(secrets map[string]webhookSecret)) error {
+	statePath, err := app.statePath(webhookSecretsFile)
+	if err != nil {
+		return err
+	}
+	secrets := make(map[string]webhookSecret)
+	if err := loadStateFile(statePath, &secrets); err != nil {
+		return err
+	}
+	modify(secrets)
+	return saveStateFile(statePath, secrets)
+}
+
+func newWebhookSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func validateTargetURL(target string) error {
+	u, err := url.Parse(target)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%s is not a valid target URL", target)
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/WebexCommunity/webex-go-sdk/v2/webhooks"
+)
+
+func TestSignWebhookPayload(t *testing.T) {
+	// HMAC-SHA1 test vector from RFC 2202
+	got := signWebhookPayload("Jefe", []byte("what do ya want for nothing?"))
+	if got != "effcdf6ae5eb2fa2d27416d5f184df9c259a7c79" {
+		t.Errorf("signWebhookPayload() = %s", got)
+	}
+}
+
+func TestSendWebhookDelivery(t *testing.T) {
+	var received webhookDelivery
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if r.Header.Get(webhookSignatureHeader) != signWebhookPayload("s3cret", body) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		json.Unmarshal(body, &received)
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	delivery := webhookDelivery{ID: "wh1", TargetURL: server.URL, Resource: "messages", Event: "created", Data: json.RawMessage(`{"id":"m1"}`)}
+	status, body, err := sendWebhookDelivery(server.Client(), delivery, "s3cret")
+	if err != nil {
+		t.Fatalf("sendWebhookDelivery() error = %v", err)
+	}
+	if status != http.StatusOK || body != "ok" {
+		t.Errorf("Expected 200 ok, got %d %q", status, body)
+	}
+	if received.ID != "wh1" || string(received.Data) != `{"id":"m1"}` {
+		t.Errorf("Unexpected delivery %+v", received)
+	}
+
+	status, _, err = sendWebhookDelivery(server.Client(), delivery, "wrong")
+	if err != nil || status != http.StatusUnauthorized {
+		t.Errorf("Expected a wrong secret to be rejected, got %d %v", status, err)
+	}
+}
+
+func TestFilterWebhooks(t *testing.T) {
+	hooks := []webhooks.Webhook{
+		{ID: "1", TargetURL: "https://ci.example.com/hook", Status: "active"},
+		{ID: "2", TargetURL: "https://ci.example.com/old", Status: "inactive"},
+		{ID: "3", TargetURL: "https://other.example.com/hook", Status: "active"},
+	}
+	if n := len(filterWebhooks(hooks, "", false)); n != 3 {
+		t.Errorf("Expected all 3 webhooks, got %d", n)
+	}
+	if n := len(filterWebhooks(hooks, "https://ci.example.com", false)); n != 2 {
+		t.Errorf("Expected 2 webhooks for the prefix, got %d", n)
+	}
+	selected := filterWebhooks(hooks, "https://ci.example.com", true)
+	if len(selected) != 1 || selected[0].ID != "2" {
+		t.Errorf("Expected only the inactive webhook, got %+v", selected)
+	}
+}
+
+func TestWebhookSecrets(t *testing.T) {
+	app := &Application{StateDir: t.TempDir()}
+	hook := &webhooks.Webhook{ID: "wh1", Name: "ci", TargetURL: "https://ci.example.com/hook"}
+	if err := app.storeWebhookSecret(hook, "s3cret"); err != nil {
+		t.Fatalf("storeWebhookSecret() error = %v", err)
+	}
+	if stored := app.webhookSecrets()["wh1"]; stored.Secret != "s3cret" || stored.TargetURL != hook.TargetURL {
+		t.Errorf("Unexpected stored secret %+v", stored)
+	}
+	if err := app.updateWebhookSecrets(func(secrets map[string]webhookSecret) { delete(secrets, "wh1") }); err != nil {
+		t.Fatalf("updateWebhookSecrets() error = %v", err)
+	}
+	if _, ok := app.webhookSecrets()["wh1"]; ok {
+		t.Error("Expected the secret to be removed")
+	}
+}
+
+func TestValidateTargetURL(t *testing.T) {
+	for _, target := range []string{"https://example.com/hook", "http://localhost:8080/"} {
+		if err := validateTargetURL(target); err != nil {
+			t.Errorf("validateTargetURL(%q) error = %v", target, err)
+		}
+	}
+	for _, target := range []string{"", "example.com/hook", "ftp://example.com", "https://"} {
+		if err := validateTargetURL(target); err == nil {
+			t.Errorf("Expected validateTargetURL(%q) to fail", target)
+		}
+	}
+}
+
+func TestNewWebhookSecret(t *testing.T) {
+	a, err := newWebhookSecret()
+	if err != nil {
+		t.Fatalf("newWebhookSecret() error = %v", err)
+	}
+	b, _ := newWebhookSecret()
+	if len(a) != 64 || a == b {
+		t.Errorf("Expected distinct 64 character secrets, got %q and %q", a, b)
+	}
+}
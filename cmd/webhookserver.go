@@ -0,0 +1,369 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/WebexCommunity/webex-go-sdk/v2/attachmentactions"
+	"github.com/WebexCommunity/webex-go-sdk/v2/messages"
+	"github.com/go-chi/chi"
+	"github.com/go-chi/chi/middleware"
+	"github.com/urfave/cli/v2"
+)
+
+// webhookEvent is a verified delivery together with the resource it refers
+// to, as passed to handlers
+type webhookEvent struct {
+	webhookDelivery
+	Message          *messages.Message                   `json:"message,omitempty"`
+	AttachmentAction *attachmentactions.AttachmentAction `json:"attachmentAction,omitempty"`
+}
+
+// webhookHandler receives the JSON of each event
+type webhookHandler interface {
+	Name() string
+	Handle(event *webhookEvent, payload []byte) error
+}
+
+// WebhookServerApplication struct
+type WebhookServerApplication struct {
+	*Application
+	Secret   string
+	Handlers []webhookHandler
+	seen     *eventDeduplicator
+
+	// The SDK creates its clients lazily and without a lock, so expand uses
+	// clients taken before the server starts
+	messages          *messages.Client
+	attachmentActions *attachmentactions.Client
+}
+
+// WebhookServer function
+func (app *Application) WebhookServer() *cli.Command {
+	return &cli.Command{
+		Name:        "webhookserver",
+		Aliases:     []string{"whserver"},
+		Description: "Start a server which receives Webex webhooks, verifies their signature and passes each event to a shell command, an HTTP endpoint or a JSONL file.",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:     "port",
+				Aliases:  []string{"p"},
+				Value:    "8000",
+				Usage:    "Port on which to run the server. Default is 8000",
+				Required: false,
+			},
+			&cli.StringFlag{
+				Name:     "secret",
+				Aliases:  []string{"s"},
+				Value:    "",
+				Usage:    "Secret of the webhook, used to verify the X-Spark-Signature header",
+				Required: false,
+				EnvVars:  []string{"WEBEX_WEBHOOK_SECRET"},
+			},
+			&cli.StringFlag{
+				Name:     "webhookID",
+				Aliases:  []string{"wid"},
+				Value:    "",
+				Usage:    "ID of a webhook created with webhooks create, whose stored secret is used",
+				Required: false,
+			},
+			&cli.StringFlag{
+				Name:     "exec",
+				Aliases:  []string{"x"},
+				Value:    "",
+				Usage:    "Shell command run for each event with the event JSON on stdin",
+				Required: false,
+			},
+			&cli.StringFlag{
+				Name:     "forward",
+				Aliases:  []string{"fw"},
+				Value:    "",
+				Usage:    "URL each event JSON is posted to",
+				Required: false,
+			},
+			&cli.StringFlag{
+				Name:     "sink",
+				Aliases:  []string{"jsonl"},
+				Value:    "",
+				Usage:    "Path of a JSONL file each event is appended to",
+				Required: false,
+			},
+			&cli.DurationFlag{
+				Name:     "dedupWindow",
+				Aliases:  []string{"dw"},
+				Value:    time.Hour,
+				Usage:    "Ignore redeliveries of the same event within this window. Default is 1h",
+				Required: false,
+			},
+		},
+		Action: func(c *cli.Context) error {
+
+			port := c.Int64("port")
+
+			secret := c.String("secret")
+			if webhookID := c.String("webhookID"); webhookID != "" && secret == "" {
+				stored, ok := app.webhookSecrets()[webhookID]
+				if !ok {
+					return fmt.Errorf("No stored secret for webhook %s", webhookID)
+				}
+				secret = stored.Secret
+			}
+			if secret == "" {
+				return errors.New("secret or webhookID is required")
+			}
+
+			var handlers []webhookHandler
+			if command := c.String("exec"); command != "" {
+				handlers = append(handlers, &execHandler{Command: command, Timeout: time.Minute})
+			}
+			if target := c.String("forward"); target != "" {
+				if err := validateTargetURL(target); err != nil {
+					return err
+				}
+				handlers = append(handlers, &forwardHandler{URL: target, Client: &http.Client{Timeout: 30 * time.Second}})
+			}
+			if sinkPath := c.String("sink"); sinkPath != "" {
+				handlers = append(handlers, &sinkHandler{Path: sinkPath})
+			}
+			if len(handlers) == 0 {
+				return errors.New("at least one of exec, forward or sink is required")
+			}
+
+			serverApp := &WebhookServerApplication{
+				Application: app,
+				Secret:      secret,
+				Handlers:    handlers,
+				seen:        newEventDeduplicator(c.Duration("dedupWindow")),
+
+				messages:          app.Client.Messages(),
+				attachmentActions: app.Client.AttachmentActions(),
+			}
+			r := chi.NewRouter()
+			r.Use(middleware.RequestID)
+			r.Use(middleware.Logger)
+			r.Use(middleware.Recoverer)
+			r.Get("/", serverApp.index)
+			r.Post("/", serverApp.receive)
+
+			log.Println(fmt.Sprintf("Started server on :%d", port))
+			http.ListenAndServe(fmt.Sprintf(":%d", port), r)
+			return nil
+		},
+	}
+}
+
+func (app *WebhookServerApplication) index(w http.ResponseWriter, r *http.Request) {
+	w.Write([]byte(fmt.Sprintf("Hi, I receive webhooks for %s", app.Me.DisplayName)))
+}
+
+// receive verifies a delivery and hands it to the handlers in the
+// background, so Webex gets its response quickly
+func (app *WebhookServerApplication) receive(w http.ResponseWriter, r *http.Request) {
+	delivery, err := app.verifyDelivery(r)
+	if err != nil {
+		log.Debug(err.Error())
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte("Not Authorized"))
+		return
+	}
+	if !app.seen.firstSeen(delivery.eventKey(), time.Now()) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("Duplicate event"))
+		return
+	}
+	go app.process(delivery)
+	w.WriteHeader(http.StatusAccepted)
+	w.Write([]byte("Accepted"))
+}
+
+// verifyDelivery reads the body of r and checks its signature
+func (app *WebhookServerApplication) verifyDelivery(r *http.Request) (*webhookDelivery, error) {
+	defer r.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(r.Body, 1024*1024))
+	if err != nil {
+		return nil, err
+	}
+	signature := r.Header.Get(webhookSignatureHeader)
+	if signature == "" {
+		return nil, errors.New("missing signature")
+	}
+	if !hmac.Equal([]byte(signature), []byte(signWebhookPayload(app.Secret, body))) {
+		return nil, errors.New("invalid signature")
+	}
+	delivery := &webhookDelivery{}
+	if err := json.Unmarshal(body, delivery); err != nil {
+		return nil, err
+	}
+	return delivery, nil
+}
+
+// process expands the delivery and passes it to every handler
+func (app *WebhookServerApplication) process(delivery *webhookDelivery) {
+	event := app.expand(delivery)
+	payload, err := json.Marshal(event)
+	if err != nil {
+		log.Errorf("Error encoding event: %s", err.Error())
+		return
+	}
+	for _, handler := range app.Handlers {
+		if err := handler.Handle(event, payload); err != nil {
+			log.Errorf("Error in %s handler for %s %s: %s", handler.Name(), delivery.Resource, delivery.Event, err.Error())
+		}
+	}
+}
+
+// expand fetches the message or card action a delivery refers to, since
+// webhooks only carry IDs. Failures are logged and the event is passed on
+// without it.
+func (app *WebhookServerApplication) expand(delivery *webhookDelivery) *webhookEvent {
+	event := &webhookEvent{webhookDelivery: *delivery}
+	if delivery.Event != "created" {
+		return event
+	}
+	var data struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(delivery.Data, &data); err != nil || data.ID == "" {
+		return event
+	}
+	var err error
+	switch delivery.Resource {
+	case "messages":
+		event.Message, err = app.messages.Get(data.ID)
+	case "attachmentActions":
+		event.AttachmentAction, err = app.attachmentActions.Get(data.ID)
+	}
+	if err != nil {
+		log.Errorf("Error fetching %s %s: %s", delivery.Resource, data.ID, err.Error())
+	}
+	return event
+}
+
+// eventKey identifies an event across redeliveries. The delivery ID is the
+// webhook's, so the data is hashed in as well: two updates of the same
+// object, eg. a moderator flag set and then cleared, differ in their data.
+func (delivery *webhookDelivery) eventKey() string {
+	var data map[string]interface{}
+	json.Unmarshal(delivery.Data, &data)
+	id, _ := data["id"].(string)
+	// Marshalling the decoded data sorts its keys
+	canonical, err := json.Marshal(data)
+	if err != nil || data == nil {
+		canonical = delivery.Data
+	}
+	sum := sha256.Sum256(canonical)
+	return delivery.ID + "/" + delivery.Resource + "/" + delivery.Event + "/" + id + "/" + hex.EncodeToString(sum[:8])
+}
+
+// eventDeduplicator remembers event keys for a window
+type eventDeduplicator struct {
+	mu     sync.Mutex
+	window time.Duration
+	seen   map[string]time.Time
+}
+
+func newEventDeduplicator(window time.Duration) *eventDeduplicator {
+	return &eventDeduplicator{window: window, seen: make(map[string]time.Time)}
+}
+
+// firstSeen records key and reports whether it was not seen within the
+// window. Expired keys are dropped as it goes.
+func (dedup *eventDeduplicator) firstSeen(key string, now time.Time) bool {
+	dedup.mu.Lock()
+	defer dedup.mu.Unlock()
+	for seenKey, at := range dedup.seen {
+		if now.Sub(at) >= dedup.window {
+			delete(dedup.seen, seenKey)
+		}
+	}
+	if _, ok := dedup.seen[key]; ok {
+		return false
+	}
+	dedup.seen[key] = now
+	return true
+}
+
+// execHandler runs a shell command with the event on stdin. The resource
+// and event are also passed as WEBEX_RESOURCE and WEBEX_EVENT.
+type execHandler struct {
+	Command string
+	Timeout time.Duration
+}
+
+func (handler *execHandler) Name() string {
+	return "exec"
+}
+
+func (handler *execHandler) Handle(event *webhookEvent, payload []byte) error {
+	ctx, cancel := context.WithTimeout(context.Background(), handler.Timeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, "sh", "-c", handler.Command)
+	cmd.Stdin = bytes.NewReader(payload)
+	cmd.Env = append(os.Environ(), "WEBEX_RESOURCE="+event.Resource, "WEBEX_EVENT="+event.Event)
+	output, err := cmd.CombinedOutput()
+	if len(output) > 0 {
+		log.Debugf("exec handler output: %s", string(output))
+	}
+	return err
+}
+
+// forwardHandler posts the event to a URL
+type forwardHandler struct {
+	URL    string
+	Client *http.Client
+}
+
+func (handler *forwardHandler) Name() string {
+	return "forward"
+}
+
+func (handler *forwardHandler) Handle(event *webhookEvent, payload []byte) error {
+	resp, err := handler.Client.Post(handler.URL, "application/json", bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("%s responded with status %d", handler.URL, resp.StatusCode)
+	}
+	return nil
+}
+
+// sinkHandler appends each event as a line to a JSONL file
+type sinkHandler struct {
+	mu   sync.Mutex
+	Path string
+}
+
+func (handler *sinkHandler) Name() string {
+	return "sink"
+}
+
+func (handler *sinkHandler) Handle(event *webhookEvent, payload []byte) error {
+	handler.mu.Lock()
+	defer handler.mu.Unlock()
+	file, err := os.OpenFile(handler.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(append(payload, '\n')); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
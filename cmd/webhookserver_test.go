@@ -0,0 +1,169 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// recordingHandler keeps the events it receives
+type recordingHandler struct {
+	events []*webhookEvent
+}
+
+func (handler *recordingHandler) Name() string {
+	return "recording"
+}
+
+func (handler *recordingHandler) Handle(event *webhookEvent, payload []byte) error {
+	handler.events = append(handler.events, event)
+	return nil
+}
+
+func signedDeliveryRequest(t *testing.T, secret string, delivery webhookDelivery) *http.Request {
+	body, err := json.Marshal(delivery)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	req := httptest.NewRequest("POST", "/", bytes.NewReader(body))
+	req.Header.Set(webhookSignatureHeader, signWebhookPayload(secret, body))
+	return req
+}
+
+func TestWebhookServerVerifyDelivery(t *testing.T) {
+	app := &WebhookServerApplication{Application: &Application{}, Secret: "s3cret", seen: newEventDeduplicator(time.Hour)}
+	delivery := webhookDelivery{ID: "wh1", Resource: "memberships", Event: "deleted", Data: json.RawMessage(`{"id":"m1"}`)}
+
+	got, err := app.verifyDelivery(signedDeliveryRequest(t, "s3cret", delivery))
+	if err != nil {
+		t.Fatalf("verifyDelivery() error = %v", err)
+	}
+	if got.ID != "wh1" || got.Resource != "memberships" {
+		t.Errorf("Unexpected delivery %+v", got)
+	}
+
+	if _, err := app.verifyDelivery(signedDeliveryRequest(t, "wrong", delivery)); err == nil {
+		t.Error("Expected a delivery signed with another secret to be rejected")
+	}
+	unsigned := httptest.NewRequest("POST", "/", strings.NewReader(`{"id":"wh1"}`))
+	if _, err := app.verifyDelivery(unsigned); err == nil {
+		t.Error("Expected an unsigned delivery to be rejected")
+	}
+}
+
+func TestWebhookServerReceive(t *testing.T) {
+	app := &WebhookServerApplication{Application: &Application{}, Secret: "s3cret", seen: newEventDeduplicator(time.Hour)}
+	delivery := webhookDelivery{ID: "wh1", Resource: "memberships", Event: "deleted", Data: json.RawMessage(`{"id":"m1"}`)}
+
+	w := httptest.NewRecorder()
+	app.receive(w, signedDeliveryRequest(t, "wrong", delivery))
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected status %d, got %d", http.StatusUnauthorized, w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	app.receive(w, signedDeliveryRequest(t, "s3cret", delivery))
+	if w.Code != http.StatusAccepted {
+		t.Errorf("Expected status %d, got %d", http.StatusAccepted, w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	app.receive(w, signedDeliveryRequest(t, "s3cret", delivery))
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "Duplicate") {
+		t.Errorf("Expected the redelivery to be reported as duplicate, got %d %q", w.Code, w.Body.String())
+	}
+}
+
+func TestWebhookServerProcess(t *testing.T) {
+	recorder := &recordingHandler{}
+	sinkPath := filepath.Join(t.TempDir(), "events.jsonl")
+	app := &WebhookServerApplication{
+		Application: &Application{},
+		Handlers:    []webhookHandler{recorder, &sinkHandler{Path: sinkPath}},
+	}
+	// Events other than created are passed on without fetching anything
+	app.process(&webhookDelivery{ID: "wh1", Resource: "messages", Event: "deleted", Data: json.RawMessage(`{"id":"m1"}`)})
+	app.process(&webhookDelivery{ID: "wh1", Resource: "rooms", Event: "updated", Data: json.RawMessage(`{"id":"r1"}`)})
+
+	if len(recorder.events) != 2 || recorder.events[0].Message != nil {
+		t.Fatalf("Unexpected events %+v", recorder.events)
+	}
+	data, err := os.ReadFile(sinkPath)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[1], `"resource":"rooms"`) {
+		t.Errorf("Unexpected sink contents %q", string(data))
+	}
+}
+
+func TestEventDeduplicator(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	dedup := newEventDeduplicator(time.Minute)
+	if !dedup.firstSeen("a", now) {
+		t.Error("Expected the first delivery to be new")
+	}
+	if dedup.firstSeen("a", now.Add(30*time.Second)) {
+		t.Error("Expected a redelivery within the window to be a duplicate")
+	}
+	if !dedup.firstSeen("b", now.Add(30*time.Second)) {
+		t.Error("Expected another event to be new")
+	}
+	if !dedup.firstSeen("a", now.Add(2*time.Minute)) {
+		t.Error("Expected the event to be new again after the window")
+	}
+}
+
+func TestWebhookEventKey(t *testing.T) {
+	delivery := func(data string) *webhookDelivery {
+		return &webhookDelivery{ID: "wh1", Resource: "memberships", Event: "updated", Data: json.RawMessage(data)}
+	}
+	on := delivery(`{"id":"m1","roomId":"r1","isModerator":true}`)
+	off := delivery(`{"id":"m1","roomId":"r1","isModerator":false}`)
+	redelivered := delivery(`{"isModerator":true, "roomId":"r1", "id":"m1"}`)
+
+	if on.eventKey() == off.eventKey() {
+		t.Error("Expected two updates of the same membership to have different keys")
+	}
+	if on.eventKey() != redelivered.eventKey() {
+		t.Error("Expected a redelivery of the same event to have the same key")
+	}
+	if !strings.HasPrefix(on.eventKey(), "wh1/memberships/updated/m1/") {
+		t.Errorf("Unexpected key %s", on.eventKey())
+	}
+}
+
+func TestExecAndForwardHandlers(t *testing.T) {
+	event := &webhookEvent{webhookDelivery: webhookDelivery{Resource: "messages", Event: "created"}}
+	payload := []byte(`{"resource":"messages"}`)
+
+	outPath := filepath.Join(t.TempDir(), "out.json")
+	handler := &execHandler{Command: `cat > "` + outPath + `" && test "$WEBEX_EVENT" = created`, Timeout: 10 * time.Second}
+	if err := handler.Handle(event, payload); err != nil {
+		t.Fatalf("exec handler error = %v", err)
+	}
+	if data, _ := os.ReadFile(outPath); string(data) != string(payload) {
+		t.Errorf("Expected the command to get the event on stdin, got %q", string(data))
+	}
+
+	var forwarded []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		buf := new(bytes.Buffer)
+		buf.ReadFrom(r.Body)
+		forwarded = buf.Bytes()
+	}))
+	defer server.Close()
+	if err := (&forwardHandler{URL: server.URL, Client: server.Client()}).Handle(event, payload); err != nil {
+		t.Fatalf("forward handler error = %v", err)
+	}
+	if string(forwarded) != string(payload) {
+		t.Errorf("Expected the event to be forwarded, got %q", string(forwarded))
+	}
+}
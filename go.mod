@@ -12,8 +12,10 @@ require (
 	github.com/go-chi/chi v1.5.5
 	github.com/go-ozzo/ozzo-validation/v4 v4.3.0
 	github.com/muesli/reflow v0.3.0
+	github.com/robfig/cron/v3 v3.0.1
 	github.com/sirupsen/logrus v1.9.4
 	github.com/urfave/cli/v2 v2.27.7
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -66,6 +66,14 @@ func main() {
 				Usage:    "Directory to store any downloads to",
 				Required: false,
 			},
+			&cli.StringFlag{
+				Name:     "stateDir",
+				Aliases:  []string{"sd"},
+				Value:    "~/.webex-teams-cli",
+				Usage:    "Directory to persist CLI state (message dedup, schedules, outbox etc.) across invocations",
+				Required: false,
+				EnvVars:  []string{"WEBEX_STATE_DIR"},
+			},
 		},
 		Commands: []*cli.Command{
 			appWebex.ChatCMD(),
@@ -73,6 +81,19 @@ func main() {
 			appWebex.WebexUtils(),
 			appWebex.AddUserToRoomServer(),
 			appWebex.MessageRelayServer(),
+			appWebex.WebhookServer(),
+			appWebex.ApproveCMD(),
+			appWebex.ScheduleCMD(),
+			appWebex.OutboxCMD(),
+			appWebex.TeamCMD(),
+			appWebex.SpacesCMD(),
+			appWebex.ReportCMD(),
+			appWebex.RoomsCMD(),
+			appWebex.PeopleCMD(),
+			appWebex.WebhooksCMD(),
+			appWebex.CardsCMD(),
+			appWebex.PollCMD(),
+			appWebex.JobsCMD(),
 		},
 		Before: func(c *cli.Context) error {
 			accessToken := c.String("accessToken")
@@ -117,6 +138,20 @@ func main() {
 			}
 			appWebex.DownloadsDir = downloadsDir
 
+			stateDir := c.String("stateDir")
+			if strings.HasPrefix(stateDir, "~") {
+				home, err := os.UserHomeDir()
+				if err != nil {
+					return err
+				}
+				stateDir = path.Join(home, stateDir[len("~"):])
+			}
+			absStateDir, err := filepath.Abs(stateDir)
+			if err != nil {
+				return err
+			}
+			appWebex.StateDir = absStateDir
+
 			return nil
 		},
 		Action: func(c *cli.Context) error {